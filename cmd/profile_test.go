@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStartProfiling(t *testing.T) {
+	tests := []struct {
+		name string
+		cpu  bool
+		mem  bool
+	}{
+		{name: "disabled"},
+		{name: "cpu only", cpu: true},
+		{name: "memory only", mem: true},
+		{name: "cpu and memory", cpu: true, mem: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			var cpuPath, memPath string
+			if tt.cpu {
+				cpuPath = filepath.Join(dir, "cpu.pprof")
+			}
+			if tt.mem {
+				memPath = filepath.Join(dir, "mem.pprof")
+			}
+
+			stop, err := startProfiling(cpuPath, memPath)
+			if err != nil {
+				t.Fatalf("startProfiling() unexpected error: %v", err)
+			}
+			if err := stop(); err != nil {
+				t.Fatalf("stop() unexpected error: %v", err)
+			}
+
+			for _, path := range []string{cpuPath, memPath} {
+				if path == "" {
+					continue
+				}
+				info, err := os.Stat(path)
+				if err != nil {
+					t.Errorf("profile %s not written: %v", path, err)
+				} else if info.Size() == 0 {
+					t.Errorf("profile %s is empty", path)
+				}
+			}
+
+			if entries, _ := os.ReadDir(dir); !tt.cpu && !tt.mem && len(entries) != 0 {
+				t.Errorf("expected no files when profiling is disabled, got %d", len(entries))
+			}
+		})
+	}
+}
+
+func TestStartProfiling_InvalidPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "cpu.pprof")
+	if _, err := startProfiling(path, ""); err == nil {
+		t.Error("expected error for unwritable CPU profile path")
+	}
+}
@@ -2,25 +2,37 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
 	"time"
 	"unicode"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/cli/go-gh/v2/pkg/term"
+	"github.com/itchyny/gojq"
 	"github.com/jparise/gh-find/internal/finder"
 	"github.com/jparise/gh-find/internal/github"
+	"github.com/jparise/gh-find/internal/gitignore"
 	"github.com/jparise/gh-find/internal/timeparse"
 	"github.com/spf13/cobra"
 )
 
 const (
 	maxJobs = 100
+
+	// repoTypesEnv overrides the default value of --repo-types.
+	repoTypesEnv = "GH_FIND_REPO_TYPES"
 )
 
 // outputMode represents when to enable output features (color, hyperlinks, etc).
@@ -50,15 +62,21 @@ func (m *outputMode) Type() string {
 	return "mode"
 }
 
-type fileTypesFlag []github.FileType
+type fileTypesFlag struct {
+	types    []github.FileType
+	contents []finder.ContentType
+}
 
 func (f *fileTypesFlag) String() string {
-	if f == nil || len(*f) == 0 {
+	if f == nil {
 		return ""
 	}
-	strs := make([]string, len(*f))
-	for i, ft := range *f {
-		strs[i] = string(ft)
+	strs := make([]string, 0, len(f.types)+len(f.contents))
+	for _, ft := range f.types {
+		strs = append(strs, string(ft))
+	}
+	for _, ct := range f.contents {
+		strs = append(strs, string(ct))
 	}
 	return strings.Join(strs, ",")
 }
@@ -66,17 +84,21 @@ func (f *fileTypesFlag) String() string {
 func (f *fileTypesFlag) Set(v string) error {
 	switch v {
 	case "f", "file":
-		*f = append(*f, github.FileTypeFile)
+		f.types = append(f.types, github.FileTypeFile)
 	case "d", "dir", "directory":
-		*f = append(*f, github.FileTypeDirectory)
+		f.types = append(f.types, github.FileTypeDirectory)
 	case "l", "symlink":
-		*f = append(*f, github.FileTypeSymlink)
+		f.types = append(f.types, github.FileTypeSymlink)
 	case "x", "executable":
-		*f = append(*f, github.FileTypeExecutable)
+		f.types = append(f.types, github.FileTypeExecutable)
 	case "s", "submodule":
-		*f = append(*f, github.FileTypeSubmodule)
+		f.types = append(f.types, github.FileTypeSubmodule)
+	case "text":
+		f.contents = append(f.contents, finder.ContentText)
+	case "binary":
+		f.contents = append(f.contents, finder.ContentBinary)
 	default:
-		return fmt.Errorf("must be one of f, file, d, dir, directory, l, symlink, x, executable, s, submodule")
+		return fmt.Errorf("must be one of f, file, d, dir, directory, l, symlink, x, executable, s, submodule, text, binary")
 	}
 	return nil
 }
@@ -107,6 +129,105 @@ func (e *extensionsFlag) Type() string {
 	return "ext"
 }
 
+type commitsFlag []string
+
+func (c *commitsFlag) String() string {
+	if c == nil || len(*c) == 0 {
+		return ""
+	}
+	return strings.Join(*c, ",")
+}
+
+func (c *commitsFlag) Set(v string) error {
+	for sha := range strings.SplitSeq(v, ",") {
+		sha = strings.TrimSpace(sha)
+		if sha == "" {
+			continue
+		}
+		if len(sha) < 4 || len(sha) > 40 || strings.Trim(strings.ToLower(sha), "0123456789abcdef") != "" {
+			return fmt.Errorf("invalid commit SHA %q: must be 4 to 40 hexadecimal characters", sha)
+		}
+		*c = append(*c, sha)
+	}
+	return nil
+}
+
+func (c *commitsFlag) Type() string {
+	return "shas"
+}
+
+type modesFlag []string
+
+func (m *modesFlag) String() string {
+	if m == nil || len(*m) == 0 {
+		return ""
+	}
+	return strings.Join(*m, ",")
+}
+
+func (m *modesFlag) Set(v string) error {
+	// Normalize to the six digits the tree API reports, so "40000" matches
+	// directories' "040000".
+	if v == "" || len(v) > 6 || strings.Trim(v, "01234567") != "" {
+		return fmt.Errorf("invalid mode %q: must be up to 6 octal digits (e.g., 100755)", v)
+	}
+	*m = append(*m, fmt.Sprintf("%06s", v))
+	return nil
+}
+
+func (m *modesFlag) Type() string {
+	return "mode"
+}
+
+type blobSHAsFlag []string
+
+func (b *blobSHAsFlag) String() string {
+	if b == nil || len(*b) == 0 {
+		return ""
+	}
+	return strings.Join(*b, ",")
+}
+
+func (b *blobSHAsFlag) Set(v string) error {
+	// The tree API reports lowercase SHAs.
+	sha := strings.ToLower(strings.TrimSpace(v))
+	if len(sha) < 4 || len(sha) > 40 || strings.Trim(sha, "0123456789abcdef") != "" {
+		return fmt.Errorf("invalid blob SHA %q: must be 4 to 40 hexadecimal characters", v)
+	}
+	*b = append(*b, sha)
+	return nil
+}
+
+func (b *blobSHAsFlag) Type() string {
+	return "sha"
+}
+
+type pathReplaceFlag []finder.PathReplacement
+
+func (p *pathReplaceFlag) String() string {
+	if p == nil || len(*p) == 0 {
+		return ""
+	}
+	parts := make([]string, len(*p))
+	for i, r := range *p {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+func (p *pathReplaceFlag) Set(v string) error {
+	r, err := finder.ParsePathReplacement(v)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, r)
+	return nil
+}
+
+func (p *pathReplaceFlag) Type() string {
+	return "old=new"
+}
+
 type repoTypesFlag github.RepoTypes
 
 func (f *repoTypesFlag) String() string {
@@ -152,6 +273,20 @@ func (f *repoTypesFlag) Type() string {
 	return "types"
 }
 
+// resolveRepoTypes returns the repo types to search: the flag's value if it
+// was set explicitly, otherwise env if it is set, otherwise the flag default.
+func resolveRepoTypes(flag repoTypesFlag, changed bool, env string) (github.RepoTypes, error) {
+	if changed || strings.TrimSpace(env) == "" {
+		return github.RepoTypes(flag), nil
+	}
+
+	var types repoTypesFlag
+	if err := types.Set(env); err != nil {
+		return github.RepoTypes{}, fmt.Errorf("%s: %w", repoTypesEnv, err)
+	}
+	return github.RepoTypes(types), nil
+}
+
 type jobsCount int
 
 func (j *jobsCount) Set(s string) error {
@@ -174,6 +309,136 @@ func (j *jobsCount) Type() string {
 	return "count"
 }
 
+type resultLimit int
+
+func (l *resultLimit) Set(s string) error {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	if v < 1 {
+		return fmt.Errorf("must be greater than 0")
+	}
+	*l = resultLimit(v)
+	return nil
+}
+
+func (l *resultLimit) String() string {
+	return strconv.Itoa(int(*l))
+}
+
+func (l *resultLimit) Type() string {
+	return "count"
+}
+
+type sortKeyFlag finder.SortKey
+
+func (k *sortKeyFlag) String() string {
+	return string(*k)
+}
+
+func (k *sortKeyFlag) Set(v string) error {
+	if !slices.Contains(finder.ValidSortKeys, v) {
+		return fmt.Errorf("must be one of %s", strings.Join(finder.ValidSortKeys, ", "))
+	}
+	*k = sortKeyFlag(v)
+	return nil
+}
+
+func (k *sortKeyFlag) Type() string {
+	return "key"
+}
+
+type visibilityFlag github.Visibility
+
+func (v *visibilityFlag) String() string {
+	return string(*v)
+}
+
+func (v *visibilityFlag) Set(s string) error {
+	if !slices.Contains(github.ValidVisibilities, s) {
+		return fmt.Errorf("must be one of %s", strings.Join(github.ValidVisibilities, ", "))
+	}
+	*v = visibilityFlag(s)
+	return nil
+}
+
+func (v *visibilityFlag) Type() string {
+	return "visibility"
+}
+
+type repoSortFlag finder.RepoSort
+
+func (s *repoSortFlag) String() string {
+	return string(*s)
+}
+
+func (s *repoSortFlag) Set(v string) error {
+	if !slices.Contains(finder.ValidRepoSorts, v) {
+		return fmt.Errorf("must be one of %s", strings.Join(finder.ValidRepoSorts, ", "))
+	}
+	*s = repoSortFlag(v)
+	return nil
+}
+
+func (s *repoSortFlag) Type() string {
+	return "key"
+}
+
+type formatFlag finder.Format
+
+func (f *formatFlag) String() string {
+	return string(*f)
+}
+
+func (f *formatFlag) Set(v string) error {
+	switch finder.Format(v) {
+	case finder.FormatCSV, finder.FormatTSV, finder.FormatHTML, finder.FormatURLs:
+		*f = formatFlag(v)
+		return nil
+	default:
+		return fmt.Errorf("must be one of %s, %s, %s, %s", finder.FormatCSV, finder.FormatTSV, finder.FormatHTML, finder.FormatURLs)
+	}
+}
+
+func (f *formatFlag) Type() string {
+	return "format"
+}
+
+type printfFlag struct {
+	printf *finder.Printf
+}
+
+func (f *printfFlag) String() string {
+	if f.printf == nil {
+		return ""
+	}
+	return f.printf.String()
+}
+
+func (f *printfFlag) Set(v string) error {
+	p, err := finder.ParsePrintf(v)
+	if err != nil {
+		return err
+	}
+	f.printf = p
+	return nil
+}
+
+func (f *printfFlag) Type() string {
+	return "format"
+}
+
+// checkPostTo reports whether raw is an http or https URL that --post-to can
+// send matches to, so a typo fails before the search rather than after it.
+func checkPostTo(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid --post-to URL %q: must be an http or https URL", raw)
+	}
+	return nil
+}
+
 type byteSize int64
 
 func (b *byteSize) Set(s string) error {
@@ -233,20 +498,109 @@ var (
 
 	color         = outputAuto
 	hyperlink     = outputAuto
+	icons         = outputNever
+	noRepoPrefix  = outputNever
 	repoTypes     = repoTypesFlag{Sources: true}
 	fileTypes     fileTypesFlag
 	ignoreCase    bool
 	fullPath      bool
+	useRegex      bool
+	globs         []string
+	patternFile   string
+	fixedStrings  bool
 	extensions    extensionsFlag
+	modes         modesFlag
+	blobSHAs      blobSHAsFlag
+	linkTargets   []string
+	sniffContent  bool
 	excludes      []string
+	ignoreFile    string
+	prune         []string
+	paths         []string
 	minSize       byteSize
 	maxSize       byteSize
+	emptyOnly     bool
+	caseCollide   bool
+	winUnsafe     bool
+	minDepth      int
+	maxDepth      int
 	changedWithin timeDuration
 	changedBefore timeDuration
+	createdAfter  timeDuration
+	createdBefore timeDuration
+	commits       commitsFlag
+	noGenerated   bool
+	lfsOnly       bool
+	noLFS         bool
+	inheritHealth bool
 	noCache       bool
 	cacheDir      string
 	cacheTTL      time.Duration
+	hostname      string
+	caCert        string
+	insecure      bool
+	noDedup       bool
+	ordered       bool
+	ignoreMissing bool
+	includeEmpty  bool
+	reposFile     string
+	repoQuery     string
+	teams         []string
+	starred       []string
+	affiliation   []string
+	excludeRepos  []string
+	repoTopics    []string
+	repoLanguages []string
+	repoMinStars  int
+	repoPushed    timeDuration
+	repoVis       visibilityFlag
+	repoMaxSize   byteSize
+	noTemplates   bool
+	refOverride   string
+	branchGlob    string
+	allBranches   bool
+	tagGlob       string
+	asOf          timeDuration
+	pathReplace   pathReplaceFlag
+	showCommit    bool
+	showMtime     bool
+	showAuthor    bool
+	authors       []string
+	minCommits    int
+	maxCommits    int
+	permalink     bool
+	previewBytes  byteSize
+	sortKey       sortKeyFlag
+	reverseSort   bool
+	maxResults    resultLimit
+	maxPerRepo    resultLimit
+	maxRepos      resultLimit
+	listRepos     bool
+	repoSort      repoSortFlag
+	groupOutput   bool
+	bufferGroups  bool
+	treeOutput    bool
+	duplicates    bool
+	sameName      bool
+	showStats     bool
 	jobs          = jobsCount(10)
+	postTo        string
+	jsonOutput    bool
+	jsonPretty    bool
+	jsonCompact   bool
+	jqExpr        string
+	ndjsonOutput  bool
+	format        formatFlag
+	printf        printfFlag
+	print0        bool
+	longOutput    bool
+	humanSizes    bool
+	withID        bool
+	totalOnly     bool
+	countOnly     bool
+	quiet         bool
+	cpuProfile    string
+	memProfile    string
 )
 
 var rootCmd = &cobra.Command{
@@ -258,24 +612,45 @@ var rootCmd = &cobra.Command{
   *              Match any characters (e.g., "*.go")
   **             Match across directories (e.g., "**/*.js")
   ?              Match single character (e.g., "file?.txt")
-  [...]          Match character class (e.g., "file[0-9].txt")
+  [...]          Match character class (e.g., "file[0-9].txt", "[[:digit:]]")
   {...}          Match alternatives (e.g., "*.{go,md}")
+  .../           Match directories only (e.g., "testdata/")
 
 When searching a single repository, pattern defaults to "*". When searching
 multiple repositories, the first argument is the pattern and the rest are
-repositories.
+repositories. With -g/--glob, which can be repeated to match any of several
+patterns, or --pattern-file, every argument is a repository.
 
 <repository> can be:
   <owner>             Search all repositories for a user or organization
   <owner>/<repo>      Search a specific repository
   <owner>/<repo>@<ref> Search a specific repository at a branch, tag, or commit
-
-You can specify multiple repositories to search across them all.
+  <owner>/<repo>@<ref>,<ref>...
+                      Search a specific repository at each of several refs
+  <owner>/<repo>/<path>
+                      Search only a directory of a repository
+                      (e.g., "golang/go/src/crypto/...")
+  <owner>/<glob>      Search each of the owner's repositories whose name
+                      matches a glob (e.g., "my-org/terraform-*")
+  <org>/@<team>       Search the repositories an organization's team has
+                      access to (e.g., "my-org/@platform-team")
+  @me                 Search your repositories, including private ones and
+                      those you collaborate on
+  @starred            Search the repositories you've starred
+
+You can specify multiple repositories to search across them all, list them
+in a file with --repos-file, or find them with --team, --starred, or a search
+query with --repo-query. When any of these is given, the first argument is always the
+pattern.
 
 Examples:
   gh find "*.go" cli
   gh find "*.go" cli/cli cli/go-gh
   gh find "*.go" cli/cli@trunk
+  gh find --ref release-1.0 "*.go" cli
+  gh find --branch "feature/*" "*.go" cli/cli
+  gh find --tag "v2.*" go.mod cli/cli
+  gh find --as-of 2023-01-01 SECURITY.md cli
   gh find -p "**/*_test.go" golang/go
   gh find "*" cli/cli cli/go-gh
   gh find -e go -e md cli
@@ -283,10 +658,16 @@ Examples:
   gh find --changed-within 2weeks "*.go" cli/cli
   gh find --newer 1d --min-size 10k golang/go
   gh find "*.js" -E "*.test.js" -E "*.spec.js" facebook/react
+  gh find "*.tf" my-org --exclude-repo "*-archive" --exclude-repo "sandbox-*"
   gh find --min-size 10k --max-size 100k "*.go" cli/cli`,
 	Version: version,
-	Args:    cobra.MinimumNArgs(1),
-	RunE:    run,
+	Args:    cobra.ArbitraryArgs,
+	PreRun: func(cmd *cobra.Command, _ []string) {
+		// Flag groups are validated after this, so their errors are
+		// silenced too.
+		silenceQuiet(cmd, quiet)
+	},
+	RunE: run,
 }
 
 func init() {
@@ -297,18 +678,61 @@ func init() {
 		"case-insensitive pattern matching")
 	rootCmd.Flags().BoolVarP(&fullPath, "full-path", "p", false,
 		"match pattern against full path")
+	rootCmd.Flags().BoolVarP(&useRegex, "regex", "x", false,
+		"interpret the pattern and exclude patterns as regular expressions")
+	rootCmd.Flags().BoolVarP(&fixedStrings, "fixed-strings", "F", false,
+		"interpret the pattern and exclude patterns as literal substrings")
+	rootCmd.MarkFlagsMutuallyExclusive("regex", "fixed-strings")
+	rootCmd.Flags().StringArrayVarP(&globs, "glob", "g", nil,
+		"match files against this pattern instead of the first argument (can be specified multiple times for OR matching)")
+	rootCmd.Flags().StringVar(&patternFile, "pattern-file", "",
+		"match files against any of the patterns in a file, one per line, like repeated --glob")
 
 	// File filtering
 	rootCmd.Flags().VarP(&fileTypes, "type", "t",
-		"filter by file type: f/file, d/dir/directory, l/symlink, x/executable, s/submodule")
+		"filter by file type: f/file, d/dir/directory, l/symlink, x/executable, s/submodule, or by content: text, binary")
+	rootCmd.Flags().BoolVar(&sniffContent, "sniff", false,
+		"with -t text or -t binary, classify files without a well-known extension by fetching their first 8KB")
+	rootCmd.Flags().Var(&modes, "mode",
+		"filter by exact git mode, such as 100644 or 120000 (can be specified multiple times)")
+	rootCmd.Flags().Var(&blobSHAs, "blob-sha",
+		"only match files whose blob SHA starts with this SHA (can be specified multiple times)")
+	rootCmd.Flags().StringArrayVar(&linkTargets, "symlink-target", nil,
+		"only match symlinks whose target matches a glob (can be specified multiple times)")
 	rootCmd.Flags().VarP(&extensions, "extension", "e",
 		"filter by file extension (can be specified multiple times)")
 	rootCmd.Flags().StringSliceVarP(&excludes, "exclude", "E", []string{},
 		"exclude patterns (can be specified multiple times)")
+	rootCmd.Flags().StringArrayVar(&paths, "path", nil,
+		"only match entries beneath this directory (can be specified multiple times)")
+	rootCmd.Flags().StringArrayVar(&prune, "prune", nil,
+		"skip directories matching a glob and everything beneath them (can be specified multiple times)")
+	rootCmd.Flags().StringVar(&ignoreFile, "ignore-file", "",
+		"exclude paths ignored by a file in .gitignore syntax")
 	rootCmd.Flags().Var(&minSize, "min-size",
 		"minimum file size (e.g., 1M, 500k, 1GB)")
 	rootCmd.Flags().Var(&maxSize, "max-size",
 		"maximum file size (e.g., 5M, 1GB)")
+	rootCmd.Flags().BoolVar(&emptyOnly, "empty", false,
+		"only match zero-byte files")
+	rootCmd.MarkFlagsMutuallyExclusive("empty", "min-size")
+	rootCmd.Flags().BoolVar(&caseCollide, "case-collisions", false,
+		"only match paths that differ only by case from another path in the repository")
+	rootCmd.Flags().BoolVar(&winUnsafe, "windows-unsafe", false,
+		"only match paths that are too long or have names that are invalid on Windows")
+	rootCmd.Flags().IntVar(&minDepth, "min-depth", 0,
+		"only match entries at least this many directories deep, where top-level entries are 1")
+	rootCmd.Flags().IntVar(&maxDepth, "max-depth", 0,
+		"only match entries at most this many directories deep, where top-level entries are 1")
+	rootCmd.Flags().BoolVar(&noGenerated, "no-generated", false,
+		"exclude files marked linguist-generated or linguist-vendored in .gitattributes")
+	rootCmd.Flags().BoolVar(&lfsOnly, "lfs", false,
+		"only match files tracked by Git LFS in .gitattributes")
+	rootCmd.Flags().BoolVar(&noLFS, "no-lfs", false,
+		"exclude files tracked by Git LFS in .gitattributes")
+	rootCmd.MarkFlagsMutuallyExclusive("lfs", "no-lfs")
+	rootCmd.Flags().BoolVar(&inheritHealth, "community-health", false,
+		"include community health files inherited from the owner's .github repository")
 
 	// Time filtering
 	rootCmd.Flags().Var(&changedWithin, "changed-within",
@@ -316,36 +740,325 @@ func init() {
 	rootCmd.Flags().Var(&changedBefore, "changed-before",
 		"filter by files changed before duration ago or date (e.g., 2weeks, 1d, 2024-01-01) [alias: --older]")
 
+	rootCmd.Flags().Var(&createdAfter, "created-after",
+		"filter by files first committed within duration or since date (e.g., 1month, 2024-01-01)")
+	rootCmd.Flags().Var(&createdBefore, "created-before",
+		"filter by files first committed before duration ago or date (e.g., 1year, 2024-01-01)")
+
 	// Aliases (hidden from --help)
 	rootCmd.Flags().Var(&changedWithin, "newer", "alias for --changed-within")
 	rootCmd.Flags().Var(&changedBefore, "older", "alias for --changed-before")
 	_ = rootCmd.Flags().MarkHidden("newer")
 	_ = rootCmd.Flags().MarkHidden("older")
 
+	// Commit filtering
+	rootCmd.Flags().Var(&commits, "commits",
+		"only match files changed by these commits (comma-separated SHAs; single repository only)")
+	rootCmd.Flags().StringArrayVar(&authors, "author", nil,
+		"only match files whose last commit's author login or name matches a glob, ignoring case (can be specified multiple times)")
+	rootCmd.Flags().IntVar(&minCommits, "min-commits", 0,
+		"only match files changed by at least this many commits")
+	rootCmd.Flags().IntVar(&maxCommits, "max-commits", 0,
+		"only match files changed by at most this many commits")
+
 	// Repository selection
+	rootCmd.Flags().StringVar(&reposFile, "repos-file", "",
+		"read repositories to search from a file, one per line (# starts a comment)")
+	rootCmd.Flags().StringArrayVar(&teams, "team", nil,
+		"also search the repositories a team has access to, as org/team (can be specified multiple times)")
+	rootCmd.Flags().StringArrayVar(&starred, "starred", nil,
+		"also search the repositories a user has starred (can be specified multiple times)")
+	rootCmd.Flags().StringSliceVar(&affiliation, "affiliation", []string{"owner", "collaborator"},
+		"repositories @me expands to: owner, collaborator, organization_member")
+	rootCmd.Flags().StringVar(&repoQuery, "repo-query", "",
+		"also search the repositories matching a GitHub search query (e.g., \"org:cli topic:terraform\")")
 	rootCmd.Flags().Var(&repoTypes, "repo-types",
-		"repo types when expanding owners (sources,forks,archives,mirrors,all)")
+		"repo types when expanding owners (sources,forks,archives,mirrors,all) [env: "+repoTypesEnv+"]")
+	rootCmd.Flags().BoolVar(&includeEmpty, "include-empty", false,
+		"search empty repositories (with no commits) instead of skipping them")
+	rootCmd.Flags().BoolVar(&ignoreMissing, "ignore-missing", false,
+		"silently skip repositories and owners that don't exist")
+	rootCmd.Flags().Var(&maxRepos, "max-repos",
+		"search at most N repositories, stopping expansion once they're found")
+	rootCmd.Flags().Var(&repoSort, "repo-sort",
+		"search each owner's repositories in this order: pushed (most recent first), stars (most first), name")
+	rootCmd.Flags().StringArrayVar(&excludeRepos, "exclude-repo", nil,
+		"skip expanded repositories whose name matches a glob (can be specified multiple times)")
+	rootCmd.Flags().StringSliceVar(&repoTopics, "repo-topic", nil,
+		"only expand to repositories with this topic (can be specified multiple times; all must match)")
+	rootCmd.Flags().StringSliceVar(&repoLanguages, "repo-language", nil,
+		"only expand to repositories whose primary language is one of these (e.g., go,python)")
+	rootCmd.Flags().IntVar(&repoMinStars, "repo-min-stars", 0,
+		"only expand to repositories with at least N stars")
+	rootCmd.Flags().Var(&repoPushed, "repo-pushed-since",
+		"only expand to repositories pushed to within duration or since date (e.g., 6weeks, 2024-01-01)")
+	rootCmd.Flags().Var(&repoVis, "repo-visibility",
+		"only expand to repositories with this visibility: public, private, internal")
+	rootCmd.Flags().Var(&repoMaxSize, "repo-max-size",
+		"skip expanded repositories larger than this (e.g., 500M, 2GB)")
+	rootCmd.Flags().BoolVar(&noTemplates, "exclude-templates", false,
+		"skip expanded template repositories")
+	rootCmd.Flags().StringVar(&refOverride, "ref", "",
+		"search this branch, tag, or commit instead of each repository's default branch")
+	rootCmd.Flags().StringVar(&branchGlob, "branch", "",
+		"search each branch matching a glob instead of each repository's default branch")
+	rootCmd.Flags().BoolVar(&allBranches, "all-branches", false,
+		"search every branch instead of each repository's default branch")
+	rootCmd.Flags().StringVar(&tagGlob, "tag", "",
+		"search each tag matching a glob instead of each repository's default branch")
+	rootCmd.MarkFlagsMutuallyExclusive("ref", "branch", "all-branches")
+	rootCmd.MarkFlagsMutuallyExclusive("ref", "tag")
+	rootCmd.Flags().Var(&asOf, "as-of",
+		"search each repository as of the last commit before a date or duration ago (e.g., 2023-01-01, 52weeks)")
+	rootCmd.MarkFlagsMutuallyExclusive("as-of", "ref", "branch", "all-branches", "tag")
 
 	// Output control
 	rootCmd.Flags().VarP(&color, "color", "c",
 		"colorize output: auto, always, never")
 	rootCmd.Flags().Var(&hyperlink, "hyperlink",
 		"hyperlink output: auto, always, never")
+	rootCmd.Flags().Var(&icons, "icons",
+		"show a Nerd Font icon before each match: auto, always, never")
+	rootCmd.Flags().Lookup("icons").NoOptDefVal = string(outputAuto)
+	rootCmd.Flags().Var(&noRepoPrefix, "no-repo-prefix",
+		"print bare paths without the owner/repo: prefix: auto (when searching a single repository), always, never")
+	rootCmd.Flags().Lookup("no-repo-prefix").NoOptDefVal = string(outputAlways)
+	rootCmd.Flags().BoolVar(&jsonOutput, "json", false,
+		"output matches as a JSON array")
+	rootCmd.Flags().BoolVar(&jsonPretty, "json-pretty", false,
+		"always pretty-print JSON output")
+	rootCmd.Flags().BoolVar(&jsonCompact, "json-compact", false,
+		"always write compact JSON output")
+	rootCmd.MarkFlagsMutuallyExclusive("json-pretty", "json-compact")
+	rootCmd.Flags().StringVar(&jqExpr, "jq", "",
+		"filter JSON output using a jq expression")
+	rootCmd.Flags().BoolVar(&ndjsonOutput, "ndjson", false,
+		"output each match as a JSON object on its own line as it is found")
+	rootCmd.MarkFlagsMutuallyExclusive("json", "ndjson")
+	rootCmd.Flags().Var(&format, "format",
+		"output matches as csv, tsv, a standalone html report, or raw content urls")
+	rootCmd.MarkFlagsMutuallyExclusive("format", "json", "ndjson")
+	rootCmd.Flags().BoolVar(&withID, "with-id", false,
+		"include a stable ID for each match in JSON output")
+	rootCmd.Flags().BoolVar(&showCommit, "show-commit", false,
+		"include a link to the last commit that changed each file")
+	rootCmd.Flags().BoolVar(&showMtime, "show-mtime", false,
+		"include the date of the last commit that changed each file")
+	rootCmd.Flags().BoolVar(&showAuthor, "show-author", false,
+		"include the author of the last commit that changed each file")
+	rootCmd.Flags().BoolVar(&permalink, "permalink", false,
+		"link to files at the commit each ref resolves to, so links never go stale")
+	rootCmd.Flags().Var(&previewBytes, "preview",
+		"print the first N bytes of each matched file below it (e.g., 200, 1k)")
+	rootCmd.Flags().Var(&pathReplace, "path-replace",
+		"rewrite displayed paths with a regular expression (can be specified multiple times)")
+	rootCmd.Flags().Var(&printf, "printf",
+		"print each match using find-style directives: %p, %f, %h, %s, %m, %y, %H, %T@")
+	rootCmd.Flags().BoolVarP(&longOutput, "long", "l", false,
+		"show each match's mode, type, size, and short blob SHA, like ls -l")
+	rootCmd.Flags().BoolVar(&humanSizes, "human-readable", false,
+		"show sizes with unit suffixes like 12K and 1.4M")
+	rootCmd.Flags().BoolVarP(&print0, "print0", "0", false,
+		"terminate each match with a NUL byte instead of a newline (for xargs -0)")
+	rootCmd.Flags().BoolVar(&totalOnly, "total", false,
+		"only print the total number of matches")
+	rootCmd.MarkFlagsMutuallyExclusive("json", "total")
+	rootCmd.MarkFlagsMutuallyExclusive("ndjson", "total")
+	rootCmd.MarkFlagsMutuallyExclusive("format", "total")
+	rootCmd.Flags().BoolVarP(&quiet, "quiet", "q", false,
+		"print nothing; exit 0 if anything matched, 1 if nothing did, 2 on errors")
+	rootCmd.Flags().BoolVar(&countOnly, "count", false,
+		"only print the number of matches in each repository, and the total")
+	rootCmd.Flags().BoolVar(&groupOutput, "group", false,
+		"group text output under a heading for each repository")
+	rootCmd.Flags().BoolVar(&bufferGroups, "buffer-groups", false,
+		"format each group before writing it instead of streaming it")
+	rootCmd.Flags().BoolVar(&treeOutput, "tree", false,
+		"show each repository's matches as a tree, like tree(1)")
+	rootCmd.MarkFlagsMutuallyExclusive("tree", "group", "json", "ndjson", "format", "total", "printf", "long", "print0", "preview")
+	rootCmd.Flags().BoolVar(&duplicates, "duplicates", false,
+		"only show matches whose content is identical to another match's, grouped by blob SHA")
+	rootCmd.Flags().BoolVar(&sameName, "same-name", false,
+		"only show matches whose file name is shared with another match, grouped by name")
+	rootCmd.MarkFlagsMutuallyExclusive("duplicates", "same-name", "group", "tree")
+	rootCmd.Flags().Var(&sortKey, "sort",
+		"sort matches once the search completes: path, size (largest first), mtime (newest first), repo")
+	rootCmd.Flags().BoolVar(&reverseSort, "reverse", false,
+		"reverse the --sort order")
+	rootCmd.MarkFlagsMutuallyExclusive("group", "json")
+	rootCmd.MarkFlagsMutuallyExclusive("group", "ndjson")
+	rootCmd.MarkFlagsMutuallyExclusive("group", "format")
+	rootCmd.MarkFlagsMutuallyExclusive("printf", "json", "ndjson", "format", "total", "group")
+	rootCmd.MarkFlagsMutuallyExclusive("print0", "printf", "json", "ndjson", "format", "total", "group")
+	rootCmd.MarkFlagsMutuallyExclusive("long", "printf", "json", "ndjson", "format", "total")
+	rootCmd.MarkFlagsMutuallyExclusive("group", "total")
+	rootCmd.Flags().Var(&maxResults, "max-results",
+		"write at most this many matches, stopping the search once reached")
+	rootCmd.Flags().Var(&maxPerRepo, "max-results-per-repo",
+		"keep at most this many matches from each repository")
+	rootCmd.Flags().BoolVar(&ordered, "ordered", false,
+		"write repositories' matches in the order they're specified, so repeated runs match")
+	rootCmd.MarkFlagsMutuallyExclusive("count", "total", "json", "ndjson", "format", "printf", "long", "print0",
+		"group", "tree", "preview", "sort", "max-results", "duplicates", "same-name")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "count", "total", "json", "ndjson", "format", "printf", "long", "print0",
+		"group", "tree", "preview", "sort", "max-results", "duplicates", "same-name")
+	rootCmd.Flags().BoolVar(&showStats, "stats", false,
+		"print a summary of the search to stderr when it completes")
+	rootCmd.Flags().StringVar(&postTo, "post-to", "",
+		"also POST matches as batched JSON to this URL")
+	rootCmd.Flags().BoolVar(&listRepos, "list-repos", false,
+		"list the repositories that would be searched, with their branch and size, without searching them")
+	rootCmd.MarkFlagsMutuallyExclusive("list-repos", "count", "total", "json", "ndjson", "format", "printf", "long",
+		"print0", "group", "tree", "preview", "sort", "max-results", "post-to", "duplicates", "same-name")
 
 	// Performance & caching
 	rootCmd.Flags().VarP(&jobs, "jobs", "j",
 		"maximum concurrent API requests")
+	rootCmd.Flags().BoolVar(&noDedup, "no-dedup", false,
+		"search repositories as often as they're specified instead of once")
 	rootCmd.Flags().BoolVar(&noCache, "no-cache", false,
 		"bypass cache, always fetch fresh data")
 	rootCmd.Flags().StringVar(&cacheDir, "cache-dir", "",
 		"override cache directory location")
 	rootCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 24*time.Hour,
 		"cache time-to-live (e.g., 1h, 30m, 24h)")
+
+	// GitHub Enterprise Server
+	rootCmd.Flags().StringVar(&hostname, "hostname", "",
+		"GitHub host to search (default: GH_HOST or gh's default host)")
+	rootCmd.Flags().StringVar(&caCert, "ca-cert", "",
+		"PEM file of additional CA certificates to trust for the GitHub host")
+	rootCmd.Flags().BoolVar(&insecure, "insecure", false,
+		"INSECURE: skip TLS certificate verification for the GitHub host")
+
+	// Profiling (hidden from --help)
+	rootCmd.Flags().StringVar(&cpuProfile, "profile", "", "write a CPU profile to file")
+	rootCmd.Flags().StringVar(&memProfile, "memprofile", "", "write a memory profile to file")
+	_ = rootCmd.Flags().MarkHidden("profile")
+	_ = rootCmd.Flags().MarkHidden("memprofile")
 }
 
 // Execute runs the root command.
 func Execute() error {
-	return rootCmd.Execute()
+	// Look for --quiet before the flags are parsed, so that errors parsing
+	// them are silenced as well.
+	requested := quietRequested(rootCmd, os.Args[1:])
+	silenceQuiet(rootCmd, requested)
+
+	err := rootCmd.Execute()
+	if quiet || requested {
+		return quietUsageError(err)
+	}
+	return err
+}
+
+// quietRequested reports whether args set --quiet, ahead of parsing them.
+// It follows pflag's syntax closely enough to find -q in combined shorthands
+// such as -iq, to skip the values of flags that take one, and to accept any
+// boolean value pflag does, such as --quiet=1. The last setting wins.
+func quietRequested(cmd *cobra.Command, args []string) bool {
+	flags := cmd.Flags()
+	requested := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return requested
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			flag := flags.Lookup(name)
+			switch {
+			case flag == nil:
+			case flag.Name == "quiet":
+				requested = !hasValue || quietValue(value)
+			case !hasValue && flag.NoOptDefVal == "":
+				i++ // The next argument is the flag's value.
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			shorthands := arg[1:]
+			for j := 0; j < len(shorthands); j++ {
+				flag := flags.ShorthandLookup(shorthands[j : j+1])
+				if flag == nil {
+					break
+				}
+				value, hasValue := strings.CutPrefix(shorthands[j+1:], "=")
+				if flag.Name == "quiet" {
+					requested = !hasValue || quietValue(value)
+				}
+				if hasValue {
+					break
+				}
+				if flag.NoOptDefVal == "" {
+					// The rest of the argument, or the next one, is the
+					// flag's value.
+					if j == len(shorthands)-1 {
+						i++
+					}
+					break
+				}
+			}
+		}
+	}
+	return requested
+}
+
+// quietValue reports whether value turns --quiet on. Values pflag would
+// reject leave it off.
+func quietValue(value string) bool {
+	v, err := strconv.ParseBool(value)
+	return err == nil && v
+}
+
+// silenceQuiet stops cobra from reporting errors and usage if quiet is set,
+// since main reports them along with the exit code.
+func silenceQuiet(cmd *cobra.Command, quiet bool) {
+	if quiet {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+	}
+}
+
+// quietUsageError gives errors that stopped a --quiet search before it began,
+// such as invalid flags and arguments, exit code 2 like grep -q's usage
+// errors. Errors that already carry an exit code are returned as is.
+func quietUsageError(err error) error {
+	var exitErr *ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		return err
+	}
+	return &ExitError{Code: 2, Err: err}
+}
+
+// ExitError requests a specific process exit code. Err, if set, is reported
+// before exiting.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// quietExit returns the grep-style result of a --quiet search: nil if
+// anything matched, exit code 2 if nothing matched and there were errors, and
+// exit code 1 otherwise.
+func quietExit(matched, failed bool, err error) error {
+	switch {
+	case matched:
+		return nil
+	case err != nil || failed:
+		return &ExitError{Code: 2, Err: err}
+	default:
+		return &ExitError{Code: 1}
+	}
 }
 
 // parseByteSize parses a human-readable size string into bytes.
@@ -400,64 +1113,412 @@ func parseByteSize(s string) (int64, error) {
 	return num * multiplier, nil
 }
 
-// parseRepoSpec parses "owner", "owner/repo", or "owner/repo@ref" format.
+// useJSONIndent reports whether JSON output should be pretty-printed. The
+// explicit flags take precedence; otherwise JSON is pretty-printed only when
+// writing to a terminal.
+func useJSONIndent(pretty, compact, isTerminal bool) bool {
+	switch {
+	case pretty:
+		return true
+	case compact:
+		return false
+	default:
+		return isTerminal
+	}
+}
+
+// parseRepoSpec parses "owner", "owner/repo", "owner/repo/path",
+// "owner/repo@ref", "owner/@team", "@me", or "@starred" format.
 func parseRepoSpec(spec string) (finder.RepoSpec, error) {
+	switch spec {
+	case "@me":
+		return finder.RepoSpec{Me: true}, nil
+	case "@starred":
+		return finder.RepoSpec{Starred: true}, nil
+	}
+	if owner, team, ok := strings.Cut(spec, "/@"); ok {
+		if owner == "" || team == "" || strings.ContainsAny(owner, "/@") || strings.ContainsAny(team, "/@") {
+			return finder.RepoSpec{}, fmt.Errorf("invalid team spec: %s (expected org/@team)", spec)
+		}
+		return finder.RepoSpec{Owner: owner, Team: team}, nil
+	}
+
 	path, ref, _ := strings.Cut(spec, "@")
 	owner, repo, hasRepo := strings.Cut(path, "/")
+	repo, subtree, hasSubtree := strings.Cut(repo, "/")
 
-	if owner == "" || (hasRepo && (repo == "" || strings.Contains(repo, "/"))) {
+	if owner == "" || (hasRepo && repo == "") {
 		return finder.RepoSpec{}, fmt.Errorf("invalid repo spec: %s (expected username or username/repo)", spec)
 	}
 	if ref != "" && !hasRepo {
 		return finder.RepoSpec{}, fmt.Errorf("cannot specify ref for owner expansion: %s (use owner/repo@ref)", spec)
 	}
+	if hasSubtree {
+		var err error
+		if subtree, err = parseSubtree(subtree); err != nil {
+			return finder.RepoSpec{}, fmt.Errorf("invalid repo spec: %s (%w)", spec, err)
+		}
+	}
+
+	s := finder.RepoSpec{Owner: owner, Repo: repo, Ref: ref, Path: subtree}
+	if s.IsGlob() && !doublestar.ValidatePattern(repo) {
+		return finder.RepoSpec{}, fmt.Errorf("invalid repo spec: %s (bad glob pattern)", spec)
+	}
+	return s, nil
+}
+
+// parseSubtree parses the directory path of an owner/repo/path spec. A
+// trailing "/..." (as in Go package patterns) is allowed and means the same
+// thing, since subtrees are always searched recursively; "..." alone means
+// the whole repository.
+func parseSubtree(subtree string) (string, error) {
+	if subtree == "..." {
+		return "", nil
+	}
+	subtree = strings.TrimSuffix(subtree, "/...")
+	subtree = strings.TrimSuffix(subtree, "/")
+	if subtree == "" {
+		return "", nil
+	}
+	for segment := range strings.SplitSeq(subtree, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("bad path %q", subtree)
+		}
+	}
+	return subtree, nil
+}
+
+// parseRepoSpecs parses a repository spec that may name several refs, either
+// as a comma-separated list (owner/repo@main,develop) or in braces
+// (owner/repo@{main,develop}), into one RepoSpec per ref.
+func parseRepoSpecs(spec string) ([]finder.RepoSpec, error) {
+	path, refList, hasRef := strings.Cut(spec, "@")
+	if !hasRef || path == "" || strings.HasSuffix(path, "/") {
+		s, err := parseRepoSpec(spec)
+		return []finder.RepoSpec{s}, err
+	}
+
+	if strings.HasPrefix(refList, "{") && strings.HasSuffix(refList, "}") {
+		refList = refList[1 : len(refList)-1]
+	}
+
+	var specs []finder.RepoSpec
+	for ref := range strings.SplitSeq(refList, ",") {
+		ref = strings.TrimSpace(ref)
+		if ref == "" && refList != "" {
+			return nil, fmt.Errorf("invalid repo spec: %s (empty ref in list)", spec)
+		}
+		s, err := parseRepoSpec(path + "@" + ref)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, s)
+	}
 
-	return finder.RepoSpec{Owner: owner, Repo: repo, Ref: ref}, nil
+	return specs, nil
 }
 
-// parseArgs parses command-line arguments into a pattern and repository specs.
-func parseArgs(args []string) (pattern string, repoSpecs []finder.RepoSpec, err error) {
-	if len(args) == 0 {
-		return "", nil, fmt.Errorf("at least one repository is required")
+// readReposFile reads repository specs from a file with one spec per line.
+// Blank lines are skipped, and "#" starts a comment that runs to the end of
+// the line. It's an error for the file to list no repositories.
+func readReposFile(path string) ([]finder.RepoSpec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
 
+	repoSpecs, err := parseReposFile(f, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(repoSpecs) == 0 {
+		return nil, fmt.Errorf("%s: no repositories listed", path)
+	}
+	return repoSpecs, nil
+}
+
+// parseReposFile parses the contents of a repos file, using name in errors.
+func parseReposFile(r io.Reader, name string) ([]finder.RepoSpec, error) {
+	var repoSpecs []finder.RepoSpec
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) > 1 && !strings.HasPrefix(fields[1], "#") {
+			return nil, fmt.Errorf("%s:%d: expected one repo spec per line", name, line)
+		}
+
+		specs, err := parseRepoSpecs(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		repoSpecs = append(repoSpecs, specs...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return repoSpecs, nil
+}
+
+// readPatternFile reads patterns from a file with one pattern per line.
+// Leading and trailing whitespace is trimmed, and blank lines and lines
+// starting with "#" are skipped. Since patterns may contain "#", there are
+// no trailing comments. It's an error for the file to list no patterns.
+func readPatternFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns, err := parsePatternFile(f, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("%s: no patterns listed", path)
+	}
+	return patterns, nil
+}
+
+// parsePatternFile parses the contents of a pattern file, using name in
+// errors.
+func parsePatternFile(r io.Reader, name string) ([]string, error) {
+	var patterns []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		pattern := strings.TrimSpace(scanner.Text())
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	return patterns, nil
+}
+
+// singleRepo reports whether specs name exactly one repository at one ref.
+func singleRepo(specs []finder.RepoSpec) bool {
+	return len(specs) == 1 && specs[0].Repo != "" && !specs[0].IsGlob()
+}
+
+// parseArgs parses command-line arguments into patterns and repository
+// specs. globs are patterns from --glob, in which case every argument is a
+// repository. extraSpecs are repository specs from flags, such as those read
+// from a repos file, which are searched after those given as arguments.
+func parseArgs(args, globs []string, extraSpecs []finder.RepoSpec) (patterns []string, repoSpecs []finder.RepoSpec, err error) {
 	var specArgs []string
 
 	// Single arg: it's a repo (pattern defaults to "*")
 	// Multiple args: first is pattern, rest are repos
-	if len(args) == 1 {
-		pattern = "*"
+	// With extra specs, the first arg (if any) is always the pattern.
+	// With globs, all args are repos.
+	if len(globs) > 0 {
+		for _, glob := range globs {
+			if glob == "" {
+				return nil, nil, fmt.Errorf("--glob pattern cannot be empty")
+			}
+		}
+		patterns = globs
+		specArgs = args
+	} else if len(args) == 0 {
+		patterns = []string{"*"}
+	} else if len(args) == 1 && len(extraSpecs) == 0 {
+		patterns = []string{"*"}
 		specArgs = args
 	} else {
-		pattern = args[0]
+		patterns = []string{args[0]}
 		specArgs = args[1:]
 
-		if pattern == "" {
-			pattern = "*"
+		if args[0] == "" {
+			patterns = []string{"*"}
 		}
 	}
 
-	// Parse each repo spec string into a RepoSpec
-	repoSpecs = make([]finder.RepoSpec, len(specArgs))
-	for i, s := range specArgs {
-		spec, err := parseRepoSpec(s)
+	// Parse each repo spec string into one RepoSpec per ref
+	repoSpecs = make([]finder.RepoSpec, 0, len(specArgs)+len(extraSpecs))
+	for _, s := range specArgs {
+		specs, err := parseRepoSpecs(s)
 		if err != nil {
-			return "", nil, err
+			return nil, nil, err
 		}
-		repoSpecs[i] = spec
+		repoSpecs = append(repoSpecs, specs...)
+	}
+	repoSpecs = append(repoSpecs, extraSpecs...)
+
+	if len(repoSpecs) == 0 {
+		return nil, nil, fmt.Errorf("at least one repository is required")
 	}
 
-	return pattern, repoSpecs, nil
+	return patterns, repoSpecs, nil
 }
 
-func run(cmd *cobra.Command, args []string) error {
+// directoryPattern reports whether pattern ends in "/", which (as in shell
+// globs and .gitignore) means it should only match directories. It returns
+// the pattern without the trailing slash, since tree paths never have one.
+func directoryPattern(pattern string) (string, bool) {
+	trimmed := strings.TrimRight(pattern, "/")
+	if trimmed == "" || trimmed == pattern {
+		return pattern, false
+	}
+	return trimmed, true
+}
+
+// directoryPatterns applies directoryPattern to each of patterns. Patterns
+// ending in "/" restrict the search to directories, so either all of them
+// must or none of them can, and types must allow directories.
+func directoryPatterns(patterns []string, types []github.FileType) ([]string, []github.FileType, error) {
+	trimmed := make([]string, len(patterns))
+	dirs := 0
+	for i, pattern := range patterns {
+		var ok bool
+		if trimmed[i], ok = directoryPattern(pattern); ok {
+			if len(types) > 0 && !slices.Contains(types, github.FileTypeDirectory) {
+				return nil, nil, fmt.Errorf("pattern %q only matches directories, but --type excludes them", pattern)
+			}
+			dirs++
+		}
+	}
+
+	switch dirs {
+	case 0:
+		return patterns, types, nil
+	case len(patterns):
+		return trimmed, []github.FileType{github.FileTypeDirectory}, nil
+	default:
+		return nil, nil, fmt.Errorf("patterns ending in / only match directories and can't be combined with other patterns")
+	}
+}
+
+// alternateRegexps combines exprs into a single regular expression that
+// matches any of them.
+func alternateRegexps(exprs []string) string {
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	groups := make([]string, len(exprs))
+	for i, expr := range exprs {
+		groups[i] = "(?:" + expr + ")"
+	}
+	return strings.Join(groups, "|")
+}
+
+func run(cmd *cobra.Command, args []string) (err error) {
 	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	pattern, repoSpecs, err := parseArgs(args)
+	stopProfiling, err := startProfiling(cpuProfile, memProfile)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		err = errors.Join(err, stopProfiling())
+	}()
+
+	var extraSpecs []finder.RepoSpec
+	if reposFile != "" {
+		extraSpecs, err = readReposFile(reposFile)
+		if err != nil {
+			return err
+		}
+	}
+	for _, team := range teams {
+		owner, slug, _ := strings.Cut(team, "/")
+		spec, err := parseRepoSpec(owner + "/@" + strings.TrimPrefix(slug, "@"))
+		if err != nil {
+			return fmt.Errorf("invalid --team %q: expected org/team", team)
+		}
+		extraSpecs = append(extraSpecs, spec)
+	}
+	for _, user := range starred {
+		if user == "" || strings.ContainsAny(user, "/@") {
+			return fmt.Errorf("invalid --starred %q: expected a user name", user)
+		}
+		extraSpecs = append(extraSpecs, finder.RepoSpec{Owner: user, Starred: true})
+	}
+	if repoQuery != "" {
+		extraSpecs = append(extraSpecs, finder.RepoSpec{Query: repoQuery})
+	}
+
+	globPatterns := globs
+	if patternFile != "" {
+		filePatterns, err := readPatternFile(patternFile)
+		if err != nil {
+			return err
+		}
+		globPatterns = append(slices.Clip(globPatterns), filePatterns...)
+	}
+
+	patterns, repoSpecs, err := parseArgs(args, globPatterns, extraSpecs)
+	if err != nil {
+		return err
+	}
+
+	resolvedRepoTypes, err := resolveRepoTypes(repoTypes, cmd.Flags().Changed("repo-types"), os.Getenv(repoTypesEnv))
+	if err != nil {
+		return err
+	}
+	resolvedRepoTypes.Empty = includeEmpty
+
+	// With --regex, the pattern and excludes are compiled up front so that
+	// errors are reported before searching.
+	var patternRegexp *regexp.Regexp
+	var excludeRegexps []*regexp.Regexp
+	if useRegex {
+		// parseArgs defaults the pattern to the glob "*", and the empty
+		// regular expression is its equivalent.
+		if slices.Equal(patterns, []string{"*"}) {
+			patterns = []string{""}
+		}
+		for _, pattern := range patterns {
+			if _, err := finder.CompileRegexp(pattern, false); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+		patternRegexp, err = finder.CompileRegexp(alternateRegexps(patterns), ignoreCase)
+		if err != nil {
+			return err
+		}
+		for _, exclude := range excludes {
+			re, err := finder.CompileRegexp(exclude, ignoreCase)
+			if err != nil {
+				return fmt.Errorf("invalid --exclude pattern %q: %w", exclude, err)
+			}
+			excludeRegexps = append(excludeRegexps, re)
+		}
+	}
+
+	// With --fixed-strings, the empty string is the equivalent of the
+	// default "*".
+	if fixedStrings && slices.Equal(patterns, []string{"*"}) {
+		patterns = []string{""}
+	}
+
+	var ignore *gitignore.Matcher
+	if ignoreFile != "" {
+		data, err := os.ReadFile(ignoreFile)
+		if err != nil {
+			return err
+		}
+		ignore = gitignore.Parse(data)
+	}
+
+	types := fileTypes.types
+	if !useRegex && !fixedStrings {
+		patterns, types, err = directoryPatterns(patterns, types)
+		if err != nil {
+			return err
+		}
+	}
 
 	terminal := term.FromEnv()
 
@@ -481,10 +1542,175 @@ func run(cmd *cobra.Command, args []string) error {
 		hyperlinks = terminal.IsColorEnabled() && color != outputNever
 	}
 
+	// Icons need a Nerd Font, which can't be detected, so "auto" only checks
+	// that the output is a terminal.
+	var showIcons bool
+	switch icons {
+	case outputAlways:
+		showIcons = true
+	case outputNever:
+		showIcons = false
+	case outputAuto:
+		showIcons = terminal.IsTerminalOutput()
+	}
+
+	if (jsonPretty || jsonCompact) && !jsonOutput {
+		return fmt.Errorf("--json-pretty and --json-compact require --json")
+	}
+	if jqExpr != "" {
+		if !jsonOutput {
+			return fmt.Errorf("--jq requires --json")
+		}
+		// Check the expression before searching rather than after.
+		if _, err := gojq.Parse(jqExpr); err != nil {
+			return fmt.Errorf("invalid --jq expression: %w", err)
+		}
+	}
+	if reverseSort && sortKey == "" {
+		return fmt.Errorf("--reverse requires --sort")
+	}
+	if bufferGroups && !groupOutput {
+		return fmt.Errorf("--buffer-groups requires --group")
+	}
+	if sniffContent && len(fileTypes.contents) == 0 {
+		return fmt.Errorf("--sniff requires -t text or -t binary")
+	}
+	if postTo != "" {
+		if err := checkPostTo(postTo); err != nil {
+			return err
+		}
+	}
+
+	highlight := finder.NewHighlighter(patterns, fullPath, ignoreCase)
+	switch {
+	case patternRegexp != nil:
+		highlight = finder.NewRegexpHighlighter(patternRegexp, fullPath)
+	case fixedStrings:
+		quoted := make([]string, len(patterns))
+		for i, pattern := range patterns {
+			quoted[i] = regexp.QuoteMeta(pattern)
+		}
+		re, _ := finder.CompileRegexp(strings.Join(quoted, "|"), ignoreCase)
+		highlight = finder.NewRegexpHighlighter(re, fullPath)
+	}
+
+	outputOpts := finder.OutputOptions{
+		Format:           finder.FormatText,
+		Colorize:         colorize,
+		Hyperlinks:       hyperlinks,
+		WithID:           withID,
+		ShowCommit:       showCommit,
+		ShowMtime:        showMtime,
+		ShowAuthor:       showAuthor,
+		Sort:             finder.SortKey(sortKey),
+		Reverse:          reverseSort,
+		MaxResults:       int(maxResults),
+		Group:            groupOutput,
+		BufferGroups:     bufferGroups,
+		Tree:             treeOutput,
+		Duplicates:       duplicates,
+		SameName:         sameName,
+		PathReplacements: []finder.PathReplacement(pathReplace),
+		LSColors:         os.Getenv("LS_COLORS"),
+		Highlight:        highlight,
+		Printf:           printf.printf,
+		Print0:           print0,
+		Long:             longOutput,
+		Icons:            showIcons,
+		NoRepoPrefix:     noRepoPrefix == outputAlways || (noRepoPrefix == outputAuto && singleRepo(repoSpecs)),
+		HumanReadable:    humanSizes,
+	}
+	switch {
+	case jsonOutput:
+		outputOpts.Format = finder.FormatJSON
+		outputOpts.JSONIndent = useJSONIndent(jsonPretty, jsonCompact, terminal.IsTerminalOutput())
+		outputOpts.JQ = jqExpr
+	case ndjsonOutput:
+		outputOpts.Format = finder.FormatNDJSON
+	case format != "":
+		outputOpts.Format = finder.Format(format)
+	case quiet:
+		outputOpts.Format = finder.FormatQuiet
+	case countOnly:
+		outputOpts.Format = finder.FormatCount
+	case totalOnly:
+		outputOpts.Format = finder.FormatTotal
+	}
+
+	if len(commits) > 0 && !singleRepo(repoSpecs) {
+		return fmt.Errorf("--commits requires exactly one repository (owner/repo)")
+	}
+
+	for _, a := range affiliation {
+		if !slices.Contains(github.ValidAffiliations, a) {
+			return fmt.Errorf("invalid --affiliation %q: must be one of %s", a, strings.Join(github.ValidAffiliations, ", "))
+		}
+	}
+	if repoMinStars < 0 {
+		return fmt.Errorf("--repo-min-stars cannot be negative")
+	}
+	for _, pattern := range excludeRepos {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("invalid --exclude-repo pattern %q", pattern)
+		}
+	}
+	for i, dir := range paths {
+		// Paths are always relative to the repository root.
+		subtree, err := parseSubtree(strings.TrimLeft(dir, "/"))
+		if err != nil || subtree == "" {
+			return fmt.Errorf("invalid --path %q", dir)
+		}
+		paths[i] = subtree
+	}
+	for i, pattern := range prune {
+		// A trailing slash, as in "node_modules/", is redundant since only
+		// directories are pruned.
+		prune[i] = strings.TrimRight(pattern, "/")
+		if prune[i] == "" || !doublestar.ValidatePattern(prune[i]) {
+			return fmt.Errorf("invalid --prune pattern %q", pattern)
+		}
+	}
+	for _, target := range linkTargets {
+		if target == "" || !doublestar.ValidatePattern(target) {
+			return fmt.Errorf("invalid --symlink-target pattern %q", target)
+		}
+	}
+	for _, author := range authors {
+		if author == "" || !doublestar.ValidatePattern(author) {
+			return fmt.Errorf("invalid --author pattern %q", author)
+		}
+	}
+	if branchGlob != "" && !doublestar.ValidatePattern(branchGlob) {
+		return fmt.Errorf("invalid --branch pattern %q", branchGlob)
+	}
+	if tagGlob != "" && !doublestar.ValidatePattern(tagGlob) {
+		return fmt.Errorf("invalid --tag pattern %q", tagGlob)
+	}
+	if allBranches {
+		branchGlob = "**"
+	}
+
+	// Sorting by mtime, showing the mtime or author, and printing %T@ or %u
+	// use each match's last commit.
+	needCommitDates := finder.SortKey(sortKey) == finder.SortMtime || showMtime || showAuthor ||
+		(printf.printf != nil && printf.printf.NeedsCommitDates())
+
 	// Validate that min <= max if both specified
 	if minSize > 0 && maxSize > 0 && minSize > maxSize {
 		return fmt.Errorf("--min-size cannot be greater than --max-size")
 	}
+	if minCommits < 0 || maxCommits < 0 {
+		return fmt.Errorf("--min-commits and --max-commits cannot be negative")
+	}
+	if minCommits > 0 && maxCommits > 0 && minCommits > maxCommits {
+		return fmt.Errorf("--min-commits cannot be greater than --max-commits")
+	}
+	if minDepth < 0 || maxDepth < 0 {
+		return fmt.Errorf("--min-depth and --max-depth cannot be negative")
+	}
+	if minDepth > 0 && maxDepth > 0 && minDepth > maxDepth {
+		return fmt.Errorf("--min-depth cannot be greater than --max-depth")
+	}
 
 	// Convert timeDuration to *time.Time
 	now := time.Now()
@@ -497,30 +1723,110 @@ func run(cmd *cobra.Command, args []string) error {
 		t := now.Add(-time.Duration(changedBefore))
 		changedBeforeTime = &t
 	}
+	var createdAfterTime, createdBeforeTime *time.Time
+	if createdAfter != 0 {
+		t := now.Add(-time.Duration(createdAfter))
+		createdAfterTime = &t
+	}
+	if createdBefore != 0 {
+		t := now.Add(-time.Duration(createdBefore))
+		createdBeforeTime = &t
+	}
+	var repoPushedTime *time.Time
+	if repoPushed != 0 {
+		t := now.Add(-time.Duration(repoPushed))
+		repoPushedTime = &t
+	}
+	var asOfTime *time.Time
+	if asOf != 0 {
+		t := now.Add(-time.Duration(asOf))
+		asOfTime = &t
+	}
 
 	// Build search options
 	opts := &finder.Options{
-		Pattern:       pattern,
-		RepoSpecs:     repoSpecs,
-		RepoTypes:     github.RepoTypes(repoTypes),
-		FileTypes:     []github.FileType(fileTypes),
-		IgnoreCase:    ignoreCase,
-		FullPath:      fullPath,
-		Extensions:    []string(extensions),
-		Excludes:      excludes,
-		MinSize:       int64(minSize),
-		MaxSize:       int64(maxSize),
-		ChangedAfter:  changedAfterTime,
-		ChangedBefore: changedBeforeTime,
+		Patterns:        patterns,
+		RepoSpecs:       repoSpecs,
+		Affiliation:     affiliation,
+		ExcludeRepos:    excludeRepos,
+		RepoTopics:      repoTopics,
+		RepoLanguages:   repoLanguages,
+		RepoMinStars:    repoMinStars,
+		RepoPushedSince: repoPushedTime,
+		RepoVisibility:  github.Visibility(repoVis),
+		RepoMaxSize:     int64(repoMaxSize),
+		RepoSort:        finder.RepoSort(repoSort),
+		SkipTemplates:   noTemplates,
+		Ref:             refOverride,
+		Branches:        branchGlob,
+		Tags:            tagGlob,
+		AsOf:            asOfTime,
+		RepoTypes:       resolvedRepoTypes,
+		Regexp:          patternRegexp,
+		ExcludeRegexps:  excludeRegexps,
+		FixedStrings:    fixedStrings,
+		Ignore:          ignore,
+		FileTypes:       types,
+		ContentTypes:    fileTypes.contents,
+		SniffContent:    sniffContent,
+		Modes:           []string(modes),
+		BlobSHAs:        []string(blobSHAs),
+		SymlinkTargets:  linkTargets,
+		IgnoreCase:      ignoreCase,
+		FullPath:        fullPath,
+		Extensions:      []string(extensions),
+		Paths:           paths,
+		Excludes:        excludes,
+		Prune:           prune,
+		MinSize:         int64(minSize),
+		MaxSize:         int64(maxSize),
+		Empty:           emptyOnly,
+		CaseCollisions:  caseCollide,
+		WindowsUnsafe:   winUnsafe,
+		MinDepth:        minDepth,
+		MaxDepth:        maxDepth,
+		ChangedAfter:    changedAfterTime,
+		ChangedBefore:   changedBeforeTime,
+		CreatedAfter:    createdAfterTime,
+		CreatedBefore:   createdBeforeTime,
+		Authors:         authors,
+		MinCommits:      minCommits,
+		MaxCommits:      maxCommits,
+		Commits:         []string(commits),
+		NoGenerated:     noGenerated,
+		LFS:             lfsOnly,
+		NoLFS:           noLFS,
+		CommunityHealth: inheritHealth,
+		ShowCommit:      showCommit,
+		CommitDates:     needCommitDates,
+		Preview:         int(previewBytes),
+		MaxPerRepo:      int(maxPerRepo),
+		Permalink:       permalink,
 		ClientOpts: github.ClientOptions{
+			Host:         hostname,
 			DisableCache: noCache,
 			CacheDir:     cacheDir,
 			CacheTTL:     cacheTTL,
+			CACertFile:   caCert,
+			Insecure:     insecure,
 		},
-		Jobs: int(jobs),
+		Jobs:          int(jobs),
+		PostTo:        postTo,
+		NoDedup:       noDedup,
+		MaxRepos:      int(maxRepos),
+		ListRepos:     listRepos,
+		Ordered:       ordered,
+		IgnoreMissing: ignoreMissing,
+		Stats:         showStats,
+		Quiet:         quiet,
 	}
 
 	// Create finder and run search
-	f := finder.New(cmd.OutOrStdout(), cmd.ErrOrStderr(), colorize, hyperlinks)
-	return f.Find(ctx, opts)
+	f := finder.New(cmd.OutOrStdout(), cmd.ErrOrStderr(), outputOpts)
+	err = f.Find(ctx, opts)
+	if quiet {
+		// The exit code is the result, so don't report it as an error.
+		return quietExit(f.Matches() > 0, f.Failures() > 0, err)
+	}
+	return err
 }
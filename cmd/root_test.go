@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"reflect"
 	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"testing/synctest"
 	"time"
@@ -73,6 +75,30 @@ func TestOutputMode(t *testing.T) {
 	}
 }
 
+func TestUseJSONIndent(t *testing.T) {
+	tests := []struct {
+		name       string
+		pretty     bool
+		compact    bool
+		isTerminal bool
+		want       bool
+	}{
+		{name: "auto terminal", isTerminal: true, want: true},
+		{name: "auto pipe", isTerminal: false, want: false},
+		{name: "pretty overrides pipe", pretty: true, isTerminal: false, want: true},
+		{name: "compact overrides terminal", compact: true, isTerminal: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := useJSONIndent(tt.pretty, tt.compact, tt.isTerminal)
+			if got != tt.want {
+				t.Errorf("useJSONIndent(%v, %v, %v) = %v, want %v", tt.pretty, tt.compact, tt.isTerminal, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestJobsCount(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -139,6 +165,275 @@ func TestJobsCount(t *testing.T) {
 	}
 }
 
+func TestResultLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+		want    resultLimit
+	}{
+		{name: "valid", value: "20", want: resultLimit(20)},
+		{name: "one", value: "1", want: resultLimit(1)},
+		{name: "zero", value: "0", wantErr: true},
+		{name: "negative", value: "-5", wantErr: true},
+		{name: "non-numeric", value: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var l resultLimit
+			err := l.Set(tt.value)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resultLimit.Set(%q) expected error, got nil", tt.value)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("resultLimit.Set(%q) unexpected error: %v", tt.value, err)
+				return
+			}
+
+			if l != tt.want {
+				t.Errorf("resultLimit.Set(%q) = %v, want %v", tt.value, l, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortKeyFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    finder.SortKey
+		wantErr bool
+	}{
+		{value: "path", want: finder.SortPath},
+		{value: "size", want: finder.SortSize},
+		{value: "mtime", want: finder.SortMtime},
+		{value: "repo", want: finder.SortRepo},
+		{value: "name", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var k sortKeyFlag
+			err := k.Set(tt.value)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sortKeyFlag.Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && finder.SortKey(k) != tt.want {
+				t.Errorf("sortKeyFlag.Set(%q) = %q, want %q", tt.value, k, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    finder.Format
+		wantErr bool
+	}{
+		{value: "csv", want: finder.FormatCSV},
+		{value: "tsv", want: finder.FormatTSV},
+		{value: "html", want: finder.FormatHTML},
+		{value: "urls", want: finder.FormatURLs},
+		{value: "json", wantErr: true},
+		{value: "CSV", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var f formatFlag
+			err := f.Set(tt.value)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatFlag.Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && finder.Format(f) != tt.want {
+				t.Errorf("formatFlag.Set(%q) = %q, want %q", tt.value, f, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibilityFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "public"},
+		{value: "private"},
+		{value: "internal"},
+		{value: "secret", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var v visibilityFlag
+			err := v.Set(tt.value)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("visibilityFlag.Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && string(v) != tt.value {
+				t.Errorf("visibilityFlag.Set(%q) = %q", tt.value, v)
+			}
+		})
+	}
+}
+
+func TestRepoSortFlag(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "pushed"},
+		{value: "stars"},
+		{value: "name"},
+		{value: "size", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var s repoSortFlag
+			err := s.Set(tt.value)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("repoSortFlag.Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && string(s) != tt.value {
+				t.Errorf("repoSortFlag.Set(%q) = %q", tt.value, s)
+			}
+		})
+	}
+}
+
+func TestQuietRequested(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: []string{"-q", "*.go", "cli"}, want: true},
+		{args: []string{"--bogus", "--quiet", "cli"}, want: true},
+		{args: []string{"--quiet=true", "cli"}, want: true},
+		{args: []string{"--quiet=1", "cli"}, want: true},
+		{args: []string{"--quiet=T", "cli"}, want: true},
+		{args: []string{"--quiet=false", "cli"}, want: false},
+		{args: []string{"--quiet=0", "cli"}, want: false},
+		{args: []string{"-q", "--quiet=false", "cli"}, want: false},
+		{args: []string{"-qi", "*.go", "cli"}, want: true},
+		{args: []string{"-iq", "*.go", "cli"}, want: true},
+		{args: []string{"-ipq", "*.go", "cli"}, want: true},
+		{args: []string{"-q=1", "cli"}, want: true},
+		{args: []string{"-iq=false", "cli"}, want: false},
+		{args: []string{"-gq", "cli"}, want: false},
+		{args: []string{"-g", "-q", "cli"}, want: false},
+		{args: []string{"--glob", "-q", "cli"}, want: false},
+		{args: []string{"--glob=x", "-q", "cli"}, want: true},
+		{args: []string{"-j4q", "cli"}, want: false},
+		{args: []string{"*.go", "cli"}, want: false},
+		{args: []string{"--", "-q"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := quietRequested(rootCmd, tt.args); got != tt.want {
+				t.Errorf("quietRequested(%q) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietUsageError(t *testing.T) {
+	if err := quietUsageError(nil); err != nil {
+		t.Errorf("quietUsageError(nil) = %v, want nil", err)
+	}
+
+	usageErr := errors.New("unknown flag: --bogus")
+	var exitErr *ExitError
+	if err := quietUsageError(usageErr); !errors.As(err, &exitErr) || exitErr.Code != 2 || !errors.Is(err, usageErr) {
+		t.Errorf("quietUsageError() = %#v, want exit code 2 wrapping %v", err, usageErr)
+	}
+
+	// A search's own result keeps its exit code.
+	noMatch := &ExitError{Code: 1}
+	if err := quietUsageError(noMatch); !errors.Is(err, noMatch) {
+		t.Errorf("quietUsageError() = %v, want %v", err, noMatch)
+	}
+}
+
+func TestQuietExit(t *testing.T) {
+	searchErr := errors.New("failed to search all 2 repositories")
+
+	tests := []struct {
+		name     string
+		matched  bool
+		failed   bool
+		err      error
+		wantCode int // 0 = no error
+		wantErr  error
+	}{
+		{name: "matched", matched: true, wantCode: 0},
+		{name: "matched with failures", matched: true, failed: true, wantCode: 0},
+		{name: "no match", wantCode: 1},
+		{name: "no match with failures", failed: true, wantCode: 2},
+		{name: "error", err: searchErr, wantCode: 2, wantErr: searchErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := quietExit(tt.matched, tt.failed, tt.err)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Errorf("quietExit() = %v, want nil", err)
+				}
+				return
+			}
+
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("quietExit() = %v, want *ExitError", err)
+			}
+			if exitErr.Code != tt.wantCode {
+				t.Errorf("quietExit() code = %d, want %d", exitErr.Code, tt.wantCode)
+			}
+			if !errors.Is(exitErr.Err, tt.wantErr) {
+				t.Errorf("quietExit() err = %v, want %v", exitErr.Err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPostTo(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://example.com/matches"},
+		{url: "http://localhost:8080"},
+		{url: "example.com/matches", wantErr: true},
+		{url: "ftp://example.com/matches", wantErr: true},
+		{url: "https://", wantErr: true},
+		{url: "https://exa mple.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if err := checkPostTo(tt.url); (err != nil) != tt.wantErr {
+				t.Errorf("checkPostTo(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestByteSize(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -266,6 +561,148 @@ func TestExtensionsFlag(t *testing.T) {
 	}
 }
 
+func TestModesFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "mode", values: []string{"100755"}, want: []string{"100755"}},
+		{name: "several modes", values: []string{"100644", "100664"}, want: []string{"100644", "100664"}},
+		{name: "padded", values: []string{"40000"}, want: []string{"040000"}},
+		{name: "not octal", values: []string{"100855"}, wantErr: true},
+		{name: "too long", values: []string{"1000644"}, wantErr: true},
+		{name: "empty", values: []string{""}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f modesFlag
+			var err error
+			for _, v := range tt.values {
+				if err = f.Set(v); err != nil {
+					break
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("modesFlag.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal([]string(f), tt.want) {
+				t.Errorf("modesFlag = %v, want %v", f, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlobSHAsFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "full sha", values: []string{"3b18e512dba79e4c8300dd08aeb37f8e728b8dad"}, want: []string{"3b18e512dba79e4c8300dd08aeb37f8e728b8dad"}},
+		{name: "lowercased", values: []string{"3B18E51"}, want: []string{"3b18e51"}},
+		{name: "repeated flag", values: []string{"3b18e51", "8ab686e"}, want: []string{"3b18e51", "8ab686e"}},
+		{name: "too short", values: []string{"3b1"}, wantErr: true},
+		{name: "not hexadecimal", values: []string{"install.sh"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f blobSHAsFlag
+			var err error
+			for _, v := range tt.values {
+				if err = f.Set(v); err != nil {
+					break
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("blobSHAsFlag.Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !slices.Equal([]string(f), tt.want) {
+				t.Errorf("blobSHAsFlag = %v, want %v", f, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitsFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "single full sha",
+			values: []string{"abc1234def5678abc1234def5678abc1234def56"},
+			want:   []string{"abc1234def5678abc1234def5678abc1234def56"},
+		},
+		{
+			name:   "comma-separated short shas",
+			values: []string{"abc1234,DEF5678"},
+			want:   []string{"abc1234", "DEF5678"},
+		},
+		{
+			name:   "repeated flag",
+			values: []string{"abc1234", "def5678"},
+			want:   []string{"abc1234", "def5678"},
+		},
+		{
+			name:   "empty entries skipped",
+			values: []string{"abc1234,,"},
+			want:   []string{"abc1234"},
+		},
+		{
+			name:    "too short",
+			values:  []string{"abc"},
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			values:  []string{"abc1234def5678abc1234def5678abc1234def5678"},
+			wantErr: true,
+		},
+		{
+			name:    "not hexadecimal",
+			values:  []string{"main"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c commitsFlag
+			var err error
+			for _, v := range tt.values {
+				if err = c.Set(v); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("commitsFlag.Set(%v) expected error, got nil", tt.values)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("commitsFlag.Set(%v) unexpected error: %v", tt.values, err)
+				return
+			}
+
+			if !slices.Equal([]string(c), tt.want) {
+				t.Errorf("commitsFlag = %v, want %v", c, tt.want)
+			}
+		})
+	}
+}
+
 func TestRepoTypes(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -328,6 +765,74 @@ func TestRepoTypes(t *testing.T) {
 	}
 }
 
+func TestResolveRepoTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		want    github.RepoTypes
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: github.RepoTypes{Sources: true},
+		},
+		{
+			name: "env overrides default",
+			env:  "all",
+			want: github.RepoTypes{}.All(),
+		},
+		{
+			name: "env with multiple types",
+			env:  "forks,archives",
+			want: github.RepoTypes{Forks: true, Archives: true},
+		},
+		{
+			name: "flag overrides env",
+			flag: "forks",
+			env:  "all",
+			want: github.RepoTypes{Forks: true},
+		},
+		{
+			name: "blank env is ignored",
+			env:  "  ",
+			want: github.RepoTypes{Sources: true},
+		},
+		{
+			name:    "invalid env",
+			env:     "bogus",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := repoTypesFlag{Sources: true}
+			changed := tt.flag != ""
+			if changed {
+				flag = repoTypesFlag{}
+				if err := flag.Set(tt.flag); err != nil {
+					t.Fatalf("repoTypesFlag.Set(%q) unexpected error: %v", tt.flag, err)
+				}
+			}
+
+			got, err := resolveRepoTypes(flag, changed, tt.env)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("resolveRepoTypes() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveRepoTypes() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resolveRepoTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseRepoSpec(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -355,14 +860,79 @@ func TestParseRepoSpec(t *testing.T) {
 			spec: "cli/cli@",
 			want: finder.RepoSpec{Owner: "cli", Repo: "cli", Ref: ""},
 		},
+		{
+			name: "repo glob",
+			spec: "my-org/terraform-*@main",
+			want: finder.RepoSpec{Owner: "my-org", Repo: "terraform-*", Ref: "main"},
+		},
+		{
+			name: "repo path",
+			spec: "golang/go/src/crypto",
+			want: finder.RepoSpec{Owner: "golang", Repo: "go", Path: "src/crypto"},
+		},
+		{
+			name: "repo path with dots and ref",
+			spec: "golang/go/src/crypto/...@release-branch.go1.22",
+			want: finder.RepoSpec{Owner: "golang", Repo: "go", Ref: "release-branch.go1.22", Path: "src/crypto"},
+		},
+		{
+			name: "repo path with trailing slash",
+			spec: "golang/go/src/",
+			want: finder.RepoSpec{Owner: "golang", Repo: "go", Path: "src"},
+		},
+		{
+			name: "repo with only dots",
+			spec: "golang/go/...",
+			want: finder.RepoSpec{Owner: "golang", Repo: "go"},
+		},
+		{
+			name: "team",
+			spec: "my-org/@platform-team",
+			want: finder.RepoSpec{Owner: "my-org", Team: "platform-team"},
+		},
+		{
+			name: "me",
+			spec: "@me",
+			want: finder.RepoSpec{Me: true},
+		},
+		{
+			name: "starred",
+			spec: "@starred",
+			want: finder.RepoSpec{Starred: true},
+		},
+		{
+			name:    "unknown at spec",
+			spec:    "@stars",
+			wantErr: true,
+		},
+		{
+			name:    "team with ref not allowed",
+			spec:    "my-org/@platform-team@main",
+			wantErr: true,
+		},
+		{
+			name:    "team without org",
+			spec:    "/@platform-team",
+			wantErr: true,
+		},
+		{
+			name:    "invalid repo glob",
+			spec:    "my-org/terraform-[",
+			wantErr: true,
+		},
 		{
 			name:    "owner with ref not allowed",
 			spec:    "octocat@main",
 			wantErr: true,
 		},
 		{
-			name:    "too many slashes",
-			spec:    "owner/repo/extra",
+			name:    "empty path segment",
+			spec:    "owner/repo//extra",
+			wantErr: true,
+		},
+		{
+			name:    "parent path segment",
+			spec:    "owner/repo/src/../extra",
 			wantErr: true,
 		},
 		{
@@ -401,13 +971,142 @@ func TestParseRepoSpec(t *testing.T) {
 	}
 }
 
+func TestParseRepoSpecs(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []finder.RepoSpec
+		wantErr bool
+	}{
+		{
+			name: "owner only",
+			spec: "cli",
+			want: []finder.RepoSpec{{Owner: "cli"}},
+		},
+		{
+			name: "single ref",
+			spec: "cli/cli@trunk",
+			want: []finder.RepoSpec{{Owner: "cli", Repo: "cli", Ref: "trunk"}},
+		},
+		{
+			name: "team",
+			spec: "cli/@maintainers",
+			want: []finder.RepoSpec{{Owner: "cli", Team: "maintainers"}},
+		},
+		{
+			name: "comma-separated refs",
+			spec: "cli/cli@main,develop",
+			want: []finder.RepoSpec{
+				{Owner: "cli", Repo: "cli", Ref: "main"},
+				{Owner: "cli", Repo: "cli", Ref: "develop"},
+			},
+		},
+		{
+			name: "braced refs",
+			spec: "cli/cli@{main,v2.40.0}",
+			want: []finder.RepoSpec{
+				{Owner: "cli", Repo: "cli", Ref: "main"},
+				{Owner: "cli", Repo: "cli", Ref: "v2.40.0"},
+			},
+		},
+		{
+			name: "braced single ref",
+			spec: "cli/cli@{main}",
+			want: []finder.RepoSpec{{Owner: "cli", Repo: "cli", Ref: "main"}},
+		},
+		{
+			name: "empty ref",
+			spec: "cli/cli@",
+			want: []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+		},
+		{
+			name:    "empty ref in list",
+			spec:    "cli/cli@main,,develop",
+			wantErr: true,
+		},
+		{
+			name:    "owner with refs",
+			spec:    "cli@main,develop",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRepoSpecs(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseRepoSpecs(%q) expected error, got nil", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRepoSpecs(%q) unexpected error: %v", tt.spec, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRepoSpecs(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePatternFile(t *testing.T) {
+	input := "# Files that may hold secrets\n" +
+		"*.pem\n" +
+		"\n" +
+		"  id_rsa  \n" +
+		"\t# indented comment\n" +
+		"[#]*.env\n"
+
+	got, err := parsePatternFile(strings.NewReader(input), "patterns.txt")
+	if err != nil {
+		t.Fatalf("parsePatternFile() unexpected error: %v", err)
+	}
+	want := []string{"*.pem", "id_rsa", "[#]*.env"}
+	if !slices.Equal(got, want) {
+		t.Errorf("parsePatternFile() = %q, want %q", got, want)
+	}
+}
+
+func TestSingleRepo(t *testing.T) {
+	tests := []struct {
+		specs []string
+		want  bool
+	}{
+		{specs: []string{"cli/cli"}, want: true},
+		{specs: []string{"cli/cli@trunk"}, want: true},
+		{specs: []string{"cli/cli@trunk,v2"}, want: false},
+		{specs: []string{"cli"}, want: false},
+		{specs: []string{"cli/cli", "cli/go-gh"}, want: false},
+		{specs: []string{"cli/go-*"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.specs, " "), func(t *testing.T) {
+			var specs []finder.RepoSpec
+			for _, s := range tt.specs {
+				parsed, err := parseRepoSpecs(s)
+				if err != nil {
+					t.Fatalf("parseRepoSpecs(%q) unexpected error: %v", s, err)
+				}
+				specs = append(specs, parsed...)
+			}
+			if got := singleRepo(specs); got != tt.want {
+				t.Errorf("singleRepo(%v) = %v, want %v", tt.specs, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestParseArgs(t *testing.T) {
 	tests := []struct {
-		name        string
-		args        []string
-		wantPattern string
-		wantRepos   []finder.RepoSpec
-		wantErr     bool
+		name         string
+		args         []string
+		globs        []string
+		extraSpecs   []finder.RepoSpec
+		wantPatterns []string
+		wantRepos    []finder.RepoSpec
+		wantErr      bool
 	}{
 		{
 			name:    "no args",
@@ -415,45 +1114,101 @@ func TestParseArgs(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name:        "single repo defaults to star pattern",
-			args:        []string{"cli/cli"},
-			wantPattern: "*",
-			wantRepos:   []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+			name:         "single repo defaults to star pattern",
+			args:         []string{"cli/cli"},
+			wantPatterns: []string{"*"},
+			wantRepos:    []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
 		},
 		{
-			name:        "pattern with multiple repos",
-			args:        []string{"*.go", "cli/cli", "cli/go-gh"},
-			wantPattern: "*.go",
+			name:         "pattern with multiple repos",
+			args:         []string{"*.go", "cli/cli", "cli/go-gh"},
+			wantPatterns: []string{"*.go"},
 			wantRepos: []finder.RepoSpec{
 				{Owner: "cli", Repo: "cli"},
 				{Owner: "cli", Repo: "go-gh"},
 			},
 		},
 		{
-			name:        "repos with refs",
-			args:        []string{"*.go", "cli/cli@main", "golang/go@release-branch.go1.21"},
-			wantPattern: "*.go",
+			name:         "repos with refs",
+			args:         []string{"*.go", "cli/cli@main", "golang/go@release-branch.go1.21"},
+			wantPatterns: []string{"*.go"},
 			wantRepos: []finder.RepoSpec{
 				{Owner: "cli", Repo: "cli", Ref: "main"},
 				{Owner: "golang", Repo: "go", Ref: "release-branch.go1.21"},
 			},
 		},
 		{
-			name:        "empty pattern defaults to star",
-			args:        []string{"", "cli/cli"},
-			wantPattern: "*",
-			wantRepos:   []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+			name:         "repo with multiple refs",
+			args:         []string{"*.go", "cli/cli@{main,develop}", "cli/go-gh"},
+			wantPatterns: []string{"*.go"},
+			wantRepos: []finder.RepoSpec{
+				{Owner: "cli", Repo: "cli", Ref: "main"},
+				{Owner: "cli", Repo: "cli", Ref: "develop"},
+				{Owner: "cli", Repo: "go-gh"},
+			},
+		},
+		{
+			name:         "empty pattern defaults to star",
+			args:         []string{"", "cli/cli"},
+			wantPatterns: []string{"*"},
+			wantRepos:    []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
 		},
 		{
 			name:    "invalid repo spec",
-			args:    []string{"*.go", "owner/repo/extra"},
+			args:    []string{"*.go", "owner/repo//extra"},
+			wantErr: true,
+		},
+		{
+			name:         "repos file only",
+			extraSpecs:   []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+			wantPatterns: []string{"*"},
+			wantRepos:    []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+		},
+		{
+			name:         "repos file with pattern",
+			args:         []string{"*.go"},
+			extraSpecs:   []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+			wantPatterns: []string{"*.go"},
+			wantRepos:    []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+		},
+		{
+			name:         "repos file after args",
+			args:         []string{"*.go", "golang/go"},
+			extraSpecs:   []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+			wantPatterns: []string{"*.go"},
+			wantRepos:    []finder.RepoSpec{{Owner: "golang", Repo: "go"}, {Owner: "cli", Repo: "cli"}},
+		},
+		{
+			name:         "globs make every arg a repo",
+			args:         []string{"cli/cli", "cli/go-gh"},
+			globs:        []string{"*.yml", "*.yaml"},
+			wantPatterns: []string{"*.yml", "*.yaml"},
+			wantRepos:    []finder.RepoSpec{{Owner: "cli", Repo: "cli"}, {Owner: "cli", Repo: "go-gh"}},
+		},
+		{
+			name:         "globs with repos file",
+			globs:        []string{"*.go"},
+			extraSpecs:   []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+			wantPatterns: []string{"*.go"},
+			wantRepos:    []finder.RepoSpec{{Owner: "cli", Repo: "cli"}},
+		},
+		{
+			name:    "globs without repos",
+			args:    []string{},
+			globs:   []string{"*.go"},
+			wantErr: true,
+		},
+		{
+			name:    "empty glob",
+			args:    []string{"cli/cli"},
+			globs:   []string{""},
 			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pattern, repos, err := parseArgs(tt.args)
+			patterns, repos, err := parseArgs(tt.args, tt.globs, tt.extraSpecs)
 
 			if tt.wantErr {
 				if err == nil {
@@ -467,8 +1222,8 @@ func TestParseArgs(t *testing.T) {
 				return
 			}
 
-			if pattern != tt.wantPattern {
-				t.Errorf("parseArgs(%v) pattern = %q, want %q", tt.args, pattern, tt.wantPattern)
+			if !slices.Equal(patterns, tt.wantPatterns) {
+				t.Errorf("parseArgs(%v) patterns = %q, want %q", tt.args, patterns, tt.wantPatterns)
 			}
 
 			if !reflect.DeepEqual(repos, tt.wantRepos) {
@@ -478,6 +1233,152 @@ func TestParseArgs(t *testing.T) {
 	}
 }
 
+func TestParseReposFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []finder.RepoSpec
+		wantErr string
+	}{
+		{
+			name: "specs and comments",
+			input: "# Repositories to audit\n" +
+				"cli/cli\n" +
+				"\n" +
+				"  golang/go@{master,release-branch.go1.21}  # trailing comment\n" +
+				"octo\n",
+			want: []finder.RepoSpec{
+				{Owner: "cli", Repo: "cli"},
+				{Owner: "golang", Repo: "go", Ref: "master"},
+				{Owner: "golang", Repo: "go", Ref: "release-branch.go1.21"},
+				{Owner: "octo"},
+			},
+		},
+		{
+			name:  "empty",
+			input: "# nothing here\n",
+		},
+		{
+			name:    "invalid spec",
+			input:   "cli/cli\nowner/repo//extra\n",
+			wantErr: "repos.txt:2: invalid repo spec",
+		},
+		{
+			name:    "several specs on a line",
+			input:   "cli/cli golang/go\n",
+			wantErr: "repos.txt:1: expected one repo spec per line",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReposFile(strings.NewReader(tt.input), "repos.txt")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseReposFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReposFile() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseReposFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirectoryPattern(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		wantPattern string
+		wantDir     bool
+	}{
+		{pattern: "docs/", wantPattern: "docs", wantDir: true},
+		{pattern: "**/testdata/", wantPattern: "**/testdata", wantDir: true},
+		{pattern: "docs//", wantPattern: "docs", wantDir: true},
+		{pattern: "docs", wantPattern: "docs", wantDir: false},
+		{pattern: "*.go", wantPattern: "*.go", wantDir: false},
+		{pattern: "/", wantPattern: "/", wantDir: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			pattern, dir := directoryPattern(tt.pattern)
+			if pattern != tt.wantPattern || dir != tt.wantDir {
+				t.Errorf("directoryPattern(%q) = (%q, %v), want (%q, %v)",
+					tt.pattern, pattern, dir, tt.wantPattern, tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestDirectoryPatterns(t *testing.T) {
+	tests := []struct {
+		name         string
+		patterns     []string
+		types        []github.FileType
+		wantPatterns []string
+		wantTypes    []github.FileType
+		wantErr      bool
+	}{
+		{
+			name:         "no directory patterns",
+			patterns:     []string{"*.yml", "*.yaml"},
+			types:        []github.FileType{github.FileTypeFile},
+			wantPatterns: []string{"*.yml", "*.yaml"},
+			wantTypes:    []github.FileType{github.FileTypeFile},
+		},
+		{
+			name:         "all directory patterns",
+			patterns:     []string{"docs/", "testdata/"},
+			wantPatterns: []string{"docs", "testdata"},
+			wantTypes:    []github.FileType{github.FileTypeDirectory},
+		},
+		{
+			name:     "mixed",
+			patterns: []string{"docs/", "*.md"},
+			wantErr:  true,
+		},
+		{
+			name:     "type excludes directories",
+			patterns: []string{"docs/"},
+			types:    []github.FileType{github.FileTypeFile},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patterns, types, err := directoryPatterns(tt.patterns, tt.types)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("directoryPatterns(%q) error = %v, wantErr %v", tt.patterns, err, tt.wantErr)
+			}
+			if !slices.Equal(patterns, tt.wantPatterns) || !slices.Equal(types, tt.wantTypes) {
+				t.Errorf("directoryPatterns(%q) = (%q, %v), want (%q, %v)",
+					tt.patterns, patterns, types, tt.wantPatterns, tt.wantTypes)
+			}
+		})
+	}
+}
+
+func TestAlternateRegexps(t *testing.T) {
+	tests := []struct {
+		exprs []string
+		want  string
+	}{
+		{exprs: []string{`\.go$`}, want: `\.go$`},
+		{exprs: []string{`^a|b$`, `c`}, want: `(?:^a|b$)|(?:c)`},
+	}
+
+	for _, tt := range tests {
+		if got := alternateRegexps(tt.exprs); got != tt.want {
+			t.Errorf("alternateRegexps(%q) = %q, want %q", tt.exprs, got, tt.want)
+		}
+	}
+}
+
 func TestTimeDuration(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -5,6 +5,7 @@ go 1.25.3
 require (
 	github.com/bmatcuk/doublestar/v4 v4.9.1
 	github.com/cli/go-gh/v2 v2.13.0
+	github.com/itchyny/gojq v0.12.15
 	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d
 	github.com/spf13/cobra v1.10.1
 	golang.org/x/sync v0.18.0
@@ -18,6 +19,7 @@ require (
 	github.com/h2non/parth v0.0.0-20190131123155-b4df798d6542 // indirect
 	github.com/henvic/httpretty v0.0.6 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/itchyny/timefmt-go v0.1.5 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
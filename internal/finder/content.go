@@ -0,0 +1,156 @@
+package finder
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// ContentType classifies a file by its content rather than by its git mode.
+type ContentType string
+
+const (
+	// ContentText matches files whose content is text.
+	ContentText ContentType = "text"
+	// ContentBinary matches files whose content is binary.
+	ContentBinary ContentType = "binary"
+)
+
+// binaryExtensions classifies lowercase file extensions as binary (true) or
+// text (false). Files with other extensions are sniffed.
+var binaryExtensions = map[string]bool{
+	// Binary
+	".7z":    true,
+	".a":     true,
+	".bin":   true,
+	".bmp":   true,
+	".class": true,
+	".dll":   true,
+	".dylib": true,
+	".eot":   true,
+	".exe":   true,
+	".gif":   true,
+	".gz":    true,
+	".ico":   true,
+	".jar":   true,
+	".jpeg":  true,
+	".jpg":   true,
+	".mov":   true,
+	".mp3":   true,
+	".mp4":   true,
+	".o":     true,
+	".otf":   true,
+	".pdf":   true,
+	".png":   true,
+	".pyc":   true,
+	".so":    true,
+	".tar":   true,
+	".ttf":   true,
+	".wasm":  true,
+	".webp":  true,
+	".woff":  true,
+	".woff2": true,
+	".xz":    true,
+	".zip":   true,
+
+	// Text
+	".c":     false,
+	".cc":    false,
+	".cpp":   false,
+	".cs":    false,
+	".css":   false,
+	".csv":   false,
+	".go":    false,
+	".h":     false,
+	".html":  false,
+	".java":  false,
+	".js":    false,
+	".json":  false,
+	".jsx":   false,
+	".kt":    false,
+	".md":    false,
+	".mod":   false,
+	".php":   false,
+	".py":    false,
+	".rb":    false,
+	".rs":    false,
+	".rst":   false,
+	".sh":    false,
+	".sql":   false,
+	".sum":   false,
+	".svg":   false,
+	".swift": false,
+	".toml":  false,
+	".ts":    false,
+	".tsx":   false,
+	".txt":   false,
+	".xml":   false,
+	".yaml":  false,
+	".yml":   false,
+}
+
+// hasContent reports whether entry is a regular file or executable, whose
+// content can be classified.
+func hasContent(entry github.TreeEntry) bool {
+	switch github.ParseFileType(entry.Mode) {
+	case github.FileTypeFile, github.FileTypeExecutable:
+		return true
+	case github.FileTypeDirectory, github.FileTypeSymlink, github.FileTypeSubmodule:
+	}
+	return false
+}
+
+// filterByContent applies contents to the entries that filterByType kept.
+// Entries of one of types are kept as is; the rest are regular files and
+// executables, which are kept if their content is of one of contents. Files
+// are classified by extension, and empty files are text. Other files are
+// only classified if sniff is set, by fetching their first binarySniffLen
+// bytes and checking them for NUL bytes, as git does; otherwise they match
+// neither content type. Files whose content can't be fetched are reported
+// and skipped.
+func (f *Finder) filterByContent(ctx context.Context, repo github.Repository, entries []github.TreeEntry, types []github.FileType, contents []ContentType, sniff bool) ([]github.TreeEntry, error) {
+	if len(contents) == 0 {
+		return entries, nil
+	}
+	wantText := slices.Contains(contents, ContentText)
+	wantBinary := slices.Contains(contents, ContentBinary)
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		if slices.Contains(types, github.ParseFileType(entry.Mode)) {
+			filtered = append(filtered, entry)
+			continue
+		}
+		if !hasContent(entry) {
+			continue
+		}
+
+		binary, ok := binaryExtensions[strings.ToLower(path.Ext(entry.Path))]
+		switch {
+		case ok:
+		case entry.Size == 0:
+			binary = false
+		case !sniff:
+			continue
+		default:
+			data, err := f.client.GetBlobPrefix(ctx, repo, entry.SHA, binarySniffLen)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, err
+				}
+				f.output.Warningf("%s: %s: %v", repo.FullName, entry.Path, err)
+				continue
+			}
+			binary = isBinary(data)
+		}
+
+		if (binary && wantBinary) || (!binary && wantText) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered, nil
+}
@@ -0,0 +1,98 @@
+package finder
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jparise/gh-find/internal/github"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestFilterByContent(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "main.go", Mode: "100644", Size: 100, SHA: "aaa"},
+		{Path: "logo.PNG", Mode: "100644", Size: 100, SHA: "bbb"},
+		{Path: "Makefile", Mode: "100644", Size: 100, SHA: "ccc"},
+		{Path: "bin/tool", Mode: "100755", Size: 100, SHA: "ddd"},
+		{Path: "empty", Mode: "100644", Size: 0, SHA: "eee"},
+		{Path: "src", Mode: "040000", SHA: "fff"},
+	}
+
+	tests := []struct {
+		name     string
+		types    []github.FileType
+		contents []ContentType
+		sniff    bool
+		want     []string
+	}{
+		{
+			name:     "text",
+			contents: []ContentType{ContentText},
+			sniff:    true,
+			want:     []string{"main.go", "Makefile", "empty"},
+		},
+		{
+			name:     "binary",
+			contents: []ContentType{ContentBinary},
+			sniff:    true,
+			want:     []string{"logo.PNG", "bin/tool"},
+		},
+		{
+			name:     "binary or directory",
+			types:    []github.FileType{github.FileTypeDirectory},
+			contents: []ContentType{ContentBinary},
+			sniff:    true,
+			want:     []string{"logo.PNG", "bin/tool", "src"},
+		},
+		{
+			// Without sniffing, files with an unknown extension match
+			// neither content type.
+			name:     "text by extension",
+			contents: []ContentType{ContentText},
+			want:     []string{"main.go", "empty"},
+		},
+		{
+			name:     "binary by extension",
+			contents: []ContentType{ContentBinary},
+			want:     []string{"logo.PNG"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, _, stderr := testFinder(t)
+
+			// Only files with an unknown extension and content are sniffed,
+			// and only their leading bytes are fetched.
+			if tt.sniff {
+				gock.New("https://api.github.com").
+					Get("/repos/octo/a/git/blobs/ccc").
+					MatchHeader("Range", "^bytes=0-7999$").
+					Reply(206).
+					BodyString("all:\n")
+				gock.New("https://api.github.com").
+					Get("/repos/octo/a/git/blobs/ddd").
+					MatchHeader("Range", "^bytes=0-7999$").
+					Reply(206).
+					BodyString("\x7fELF\x00")
+			}
+
+			repo := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a", Ref: "main"}
+			got, err := f.filterByContent(context.Background(), repo, entries, tt.types, tt.contents, tt.sniff)
+			if err != nil {
+				t.Fatalf("filterByContent() error = %v", err)
+			}
+
+			if !slices.Equal(treePaths(got), tt.want) {
+				t.Errorf("filterByContent() = %v, want %v", treePaths(got), tt.want)
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("filterByContent() wrote to stderr: %q", stderr.String())
+			}
+			if !gock.IsDone() {
+				t.Errorf("not all mocks were called: %v", gock.Pending())
+			}
+		})
+	}
+}
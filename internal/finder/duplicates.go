@@ -0,0 +1,138 @@
+package finder
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// shortSHALen is the length of the abbreviated SHAs in duplicate headings.
+const shortSHALen = 7
+
+// duplicateKey selects what makes two matches duplicates of each other.
+type duplicateKey int
+
+const (
+	// duplicateNone writes every match.
+	duplicateNone duplicateKey = iota
+	// duplicateContent groups matches by blob SHA.
+	duplicateContent
+	// duplicateName groups matches by basename.
+	duplicateName
+)
+
+// of returns the value r is grouped by, or "" if r can't be grouped:
+// directories and submodules have no blob to compare.
+func (k duplicateKey) of(r result) string {
+	switch k {
+	case duplicateContent:
+		if hasSize(r.entry) {
+			return r.entry.SHA
+		}
+	case duplicateName:
+		return path.Base(r.entry.Path)
+	case duplicateNone:
+	}
+	return ""
+}
+
+// duplicateGroups groups results by key and returns the groups with more
+// than one member, largest first. Each group's members are in the order
+// given.
+func duplicateGroups(results []result, key duplicateKey) [][]result {
+	byKey := make(map[string][]result)
+	for _, r := range results {
+		if k := key.of(r); k != "" {
+			byKey[k] = append(byKey[k], r)
+		}
+	}
+
+	var groups [][]result
+	for _, group := range byKey {
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	slices.SortFunc(groups, func(a, b []result) int {
+		return cmp.Or(
+			cmp.Compare(len(b), len(a)),
+			strings.Compare(key.of(a[0]), key.of(b[0])),
+		)
+	})
+	return groups
+}
+
+// duplicateSortKey returns the order of the matches within each group of
+// duplicates: the --sort order if there is one, and otherwise by repository.
+func (o *Output) duplicateSortKey() SortKey {
+	if o.sortKey == SortNone {
+		return SortRepo
+	}
+	return o.sortKey
+}
+
+// flushDuplicates writes the buffered matches that are duplicates of another
+// match, a group at a time. Text output writes a heading for each group;
+// other formats write its matches consecutively. The caller must hold o.mu.
+func (o *Output) flushDuplicates() {
+	sortResults(o.pending, o.duplicateSortKey(), o.reverse)
+
+	groups := duplicateGroups(o.pending, o.duplicates)
+	o.pending = nil
+
+	o.matched = 0
+	for _, group := range groups {
+		o.matched += len(group)
+	}
+
+	headings := o.format == FormatText && o.printf == nil && o.terminator == "\n"
+	for _, group := range groups {
+		if headings {
+			n := o.reserve(len(group))
+			if n == 0 {
+				return
+			}
+			io.WriteString(o.stdout, o.duplicateHeading(group))
+			for _, r := range group[:n] {
+				io.WriteString(o.stdout, "  "+o.formatText(r, false)+o.terminator)
+			}
+			continue
+		}
+		for _, r := range group {
+			o.render(r)
+		}
+	}
+}
+
+// duplicateHeading returns the heading line for a group of duplicates,
+// preceded by a blank line if it isn't the first group. The caller must hold
+// o.mu.
+func (o *Output) duplicateHeading(group []result) string {
+	var heading string
+	switch o.duplicates {
+	case duplicateContent:
+		entry := group[0].entry
+		heading = fmt.Sprintf("%s (%d copies, %s)\n",
+			o.yellow(entry.SHA[:min(len(entry.SHA), shortSHALen)]), len(group), humanSize(entry.Size))
+	case duplicateName:
+		repos := make(map[string]bool)
+		for _, r := range group {
+			repos[r.repo.FullName] = true
+		}
+		noun := "repositories"
+		if len(repos) == 1 {
+			noun = "repository"
+		}
+		heading = fmt.Sprintf("%s (%d matches in %d %s)\n",
+			o.yellow(path.Base(group[0].entry.Path)), len(group), len(repos), noun)
+	case duplicateNone:
+	}
+	if o.groups > 0 {
+		heading = "\n" + heading
+	}
+	o.groups++
+	return heading
+}
@@ -0,0 +1,140 @@
+package finder
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestDuplicates(t *testing.T) {
+	a := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a"}
+	b := github.Repository{Owner: "octo", Name: "b", FullName: "octo/b"}
+
+	matches := []result{
+		{repo: b, entry: github.TreeEntry{Path: "install.sh", Mode: "100755", Size: 2048, SHA: "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"}},
+		{repo: a, entry: github.TreeEntry{Path: "README.md", Mode: "100644", Size: 100, SHA: "8ab686eafeb1f44702738c8b0f24f2567c36da6d"}},
+		{repo: a, entry: github.TreeEntry{Path: "scripts/install.sh", Mode: "100755", Size: 2048, SHA: "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"}},
+		{repo: a, entry: github.TreeEntry{Path: "LICENSE", Mode: "100644", Size: 1066, SHA: "c4a3e1f0b4e3f5c1b0d8e6b0a1c6e5f4d3c2b1a0"}},
+		{repo: b, entry: github.TreeEntry{Path: "LICENSE", Mode: "100644", Size: 1066, SHA: "c4a3e1f0b4e3f5c1b0d8e6b0a1c6e5f4d3c2b1a0"}},
+		{repo: b, entry: github.TreeEntry{Path: "vendor/LICENSE", Mode: "100644", Size: 1066, SHA: "c4a3e1f0b4e3f5c1b0d8e6b0a1c6e5f4d3c2b1a0"}},
+		{repo: b, entry: github.TreeEntry{Path: "docs", Mode: "040000", SHA: "8ab686eafeb1f44702738c8b0f24f2567c36da6d"}},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		output := NewOutput(stdout, stderr, OutputOptions{Duplicates: true})
+
+		for _, r := range matches {
+			output.write(r)
+		}
+		if output.done() {
+			t.Errorf("done() = true, want false until every match is in")
+		}
+		if err := output.Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+
+		// The largest group comes first, and a directory never matches a
+		// blob with the same SHA.
+		want := "c4a3e1f (3 copies, 1.0K)\n" +
+			"  octo/a:LICENSE\n" +
+			"  octo/b:LICENSE\n" +
+			"  octo/b:vendor/LICENSE\n" +
+			"\n" +
+			"3b18e51 (2 copies, 2.0K)\n" +
+			"  octo/a:scripts/install.sh\n" +
+			"  octo/b:install.sh\n"
+		if got := stdout.String(); got != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("stderr = %q, want empty", stderr.String())
+		}
+	})
+
+	t.Run("max results", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		output := NewOutput(stdout, stderr, OutputOptions{Duplicates: true, MaxResults: 4})
+
+		for _, r := range matches {
+			output.write(r)
+		}
+		if err := output.Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+
+		want := "c4a3e1f (3 copies, 1.0K)\n" +
+			"  octo/a:LICENSE\n" +
+			"  octo/b:LICENSE\n" +
+			"  octo/b:vendor/LICENSE\n" +
+			"\n" +
+			"3b18e51 (2 copies, 2.0K)\n" +
+			"  octo/a:scripts/install.sh\n"
+		if got := stdout.String(); got != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+		if want := "... (showing first 4 of 5 matches)\n"; stderr.String() != want {
+			t.Errorf("stderr = %q, want %q", stderr.String(), want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Format: FormatJSON, Duplicates: true})
+
+		for _, r := range matches {
+			output.write(r)
+		}
+		if err := output.Flush(); err != nil {
+			t.Fatalf("Flush() error = %v", err)
+		}
+
+		var records []Record
+		if err := json.Unmarshal(stdout.Bytes(), &records); err != nil {
+			t.Fatalf("invalid JSON output: %v", err)
+		}
+		var got []string
+		for _, record := range records {
+			got = append(got, record.Repo+":"+record.Path)
+		}
+		want := []string{"a:LICENSE", "b:LICENSE", "b:vendor/LICENSE", "a:scripts/install.sh", "b:install.sh"}
+		if !slices.Equal(got, want) {
+			t.Errorf("records = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestSameName(t *testing.T) {
+	a := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a"}
+	b := github.Repository{Owner: "octo", Name: "b", FullName: "octo/b"}
+
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{SameName: true})
+
+	output.write(result{repo: a, entry: github.TreeEntry{Path: ".eslintrc.json"}})
+	output.write(result{repo: b, entry: github.TreeEntry{Path: "web/.eslintrc.json"}})
+	output.write(result{repo: a, entry: github.TreeEntry{Path: "tsconfig.json"}})
+	output.write(result{repo: a, entry: github.TreeEntry{Path: "web/tsconfig.json"}})
+	output.write(result{repo: b, entry: github.TreeEntry{Path: "package.json"}})
+	if err := output.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// Names shared across repositories and within one are both reported,
+	// and names that appear once are left out.
+	want := ".eslintrc.json (2 matches in 2 repositories)\n" +
+		"  octo/a:.eslintrc.json\n" +
+		"  octo/b:web/.eslintrc.json\n" +
+		"\n" +
+		"tsconfig.json (2 matches in 1 repository)\n" +
+		"  octo/a:tsconfig.json\n" +
+		"  octo/a:web/tsconfig.json\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -2,19 +2,23 @@
 package finder
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"path"
 	"path/filepath"
+	"regexp"
 	"slices"
 	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
 	"github.com/bmatcuk/doublestar/v4"
+	"github.com/jparise/gh-find/internal/gitattributes"
 	"github.com/jparise/gh-find/internal/github"
+	"github.com/jparise/gh-find/internal/gitignore"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -22,12 +26,15 @@ import (
 type Finder struct {
 	output *Output
 	client *github.Client
+	sink   *Sink
+	health healthCache
+	stats  runStats
 }
 
-// New creates a new Finder.
-func New(stdout, stderr io.Writer, colorize, hyperlinks bool) *Finder {
+// New creates a new Finder that writes results according to outputOpts.
+func New(stdout, stderr io.Writer, outputOpts OutputOptions) *Finder {
 	return &Finder{
-		output: NewOutput(stdout, stderr, colorize, hyperlinks),
+		output: NewOutput(stdout, stderr, outputOpts),
 	}
 }
 
@@ -39,290 +46,1428 @@ func (f *Finder) Find(ctx context.Context, opts *Options) error {
 	}
 	f.client = client
 
-	var allRepos []github.Repository
+	if opts.ClientOpts.Insecure {
+		f.output.Warningf("TLS certificate verification is disabled (--insecure); connections to GitHub can be intercepted")
+	}
+
+	return f.find(ctx, opts)
+}
+
+// Matches returns the number of matches found by the search, including any
+// that weren't written because of an output limit.
+func (f *Finder) Matches() int64 {
+	return f.stats.matches.Load()
+}
+
+// Failures returns the number of repositories that couldn't be searched,
+// including named repositories that couldn't be fetched.
+func (f *Finder) Failures() int64 {
+	return f.stats.failed.Load() + f.stats.missing.Load()
+}
+
+// find runs the search using the Finder's client.
+func (f *Finder) find(ctx context.Context, opts *Options) error {
+	f.stats.start = time.Now()
+
+	if !opts.Quiet && opts.Regexp == nil && !opts.FixedStrings && patternsConflict(opts.Patterns, opts.Extensions, opts.IgnoreCase) {
+		f.output.Warningf("%s can't match files with extension %s; no files will match",
+			describePatterns(opts.Patterns), strings.Join(opts.Extensions, ", "))
+	}
+
+	if opts.PostTo != "" {
+		specs := make([]string, len(opts.RepoSpecs))
+		for i, spec := range opts.RepoSpecs {
+			specs[i] = spec.String()
+		}
+		f.sink = NewSink(opts.PostTo, RunInfo{
+			StartedAt: time.Now().UTC(),
+			Patterns:  opts.Patterns,
+			Repos:     specs,
+		})
+	}
+
+	// Matches buffered until the search completes are previewed as they're
+	// flushed, once sorting and the result limit have picked them.
+	if opts.Preview > 0 {
+		f.output.preview = func(results []result) {
+			_ = f.addPreviews(ctx, results, opts.Preview)
+		}
+	}
+
+	// Expand repo specs in the background, feeding repositories to the
+	// search loop as each page arrives rather than waiting for the full list.
+	//
+	// The search runs under its own context so it can stop early, canceling
+	// in-flight requests, once no more matches can be written.
+	searchCtx, stopSearch := context.WithCancel(ctx)
+	defer stopSearch()
+
+	// Expand specs and search repositories concurrently, sharing the same
+	// bounded parallelism.
+	sem := semaphore.NewWeighted(int64(opts.Jobs))
+
+	repoCh := make(chan github.Repository)
+	var expandErr error
+	go func() {
+		defer close(repoCh)
+		expandErr = f.expandRepos(searchCtx, opts, sem, repoCh)
+	}()
+
+	var wg sync.WaitGroup
+
+	// With opts.Ordered, each repository's results are written in the order
+	// the repositories were expanded rather than the order they finish.
+	var order *repoOrder
+	if opts.Ordered {
+		order = &repoOrder{}
+	}
+	var next int
+
+	for repo := range repoCh {
+		if opts.ListRepos {
+			f.stats.searched.Add(1)
+			f.output.writeRepo(repo)
+			continue
+		}
+
+		if err := sem.Acquire(searchCtx, 1); err != nil {
+			break
+		}
+		f.stats.searched.Add(1)
+
+		wg.Add(1)
+		go func(i int, repo github.Repository) {
+			defer wg.Done()
+			defer sem.Release(1)
+
+			results, err := f.searchRepo(searchCtx, repo, opts)
+			finish := func() {
+				// Only preview the matches the result limit will let through.
+				if err == nil && opts.Preview > 0 {
+					err = f.addPreviews(searchCtx, results[:f.output.writable(len(results))], opts.Preview)
+				}
+				if err == nil {
+					err = f.emit(searchCtx, repo, results)
+				}
+				if f.output.done() {
+					stopSearch()
+				}
+				if err != nil && !stoppedEarly(searchCtx, ctx) {
+					f.stats.failed.Add(1)
+					f.output.Warningf("%s: %v", repo.FullName, err)
+				}
+			}
+			if order != nil {
+				order.finish(i, finish)
+			} else {
+				finish()
+			}
+		}(next, repo)
+		next++
+	}
+
+	wg.Wait()
+
+	// Drain any repos still being sent so the expansion goroutine can exit.
+	for range repoCh {
+	}
+
+	stopped := stoppedEarly(searchCtx, ctx)
+	if stopped {
+		f.output.markStopped()
+	}
+	if err := f.output.Flush(); err != nil {
+		return err
+	}
+
+	if opts.Stats {
+		f.output.Infof("%s", f.stats.format(f.client.Requests(), time.Since(f.stats.start)))
+	}
+
+	// Send the matches that were found even if expansion failed partway.
+	var sinkErr error
+	if f.sink != nil {
+		sinkErr = f.sink.Flush(ctx)
+	}
+
+	if expandErr != nil && !stopped {
+		return errors.Join(expandErr, sinkErr)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if sinkErr != nil {
+		return sinkErr
+	}
+
+	searched := f.stats.searched.Load()
+	if searched == 0 {
+		f.output.Warningf("No repositories match the filter")
+		return nil
+	}
+
+	if f.stats.failed.Load() == searched {
+		return fmt.Errorf("failed to search all %d repositories", searched)
+	}
+
+	return nil
+}
+
+// stoppedEarly reports whether the search context was canceled because the
+// search stopped early, rather than because its parent was canceled.
+func stoppedEarly(searchCtx, ctx context.Context) bool {
+	return searchCtx.Err() != nil && ctx.Err() == nil
+}
 
-	for _, spec := range opts.RepoSpecs {
-		var repos []github.Repository
+// expandRepos resolves each repo spec and sends the resulting repositories to
+// out in input order. Owner specs are sent a page at a time as they're listed.
+//
+// Specs are expanded concurrently, each holding one of sem's slots while it
+// makes requests. Later specs wait to send their repositories until earlier
+// ones are done, releasing their slot while they wait so the search can't
+// be starved of slots. Warnings are reported in the same order.
+func (f *Finder) expandRepos(ctx context.Context, opts *Options, sem *semaphore.Weighted, out chan<- github.Repository) error {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
 
-		// Fetch either the single named repo or all of an owner's repos.
-		if spec.Repo != "" {
-			r, err := f.client.GetRepo(ctx, spec.Owner, spec.Repo)
+	expanders := make([]*specExpander, len(opts.RepoSpecs))
+	for i, spec := range opts.RepoSpecs {
+		e := &specExpander{ctx: ctx, sem: sem, ch: make(chan expansion)}
+		expanders[i] = e
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(e.ch)
+			if e.err = sem.Acquire(ctx, 1); e.err != nil {
+				return
+			}
+			e.held = true
+			e.err = f.expandSpec(ctx, spec, opts, e)
+			if e.held {
+				sem.Release(1)
+			}
+		}()
+	}
+
+	// The specs could expand to duplicates (e.g. the user provided an
+	// explicit owner/repo name that was also expanded from owner/*). We skip
+	// repos we've already seen while preserving input order, unless asked
+	// to search every one (e.g. for benchmarking).
+	seen := newRepoSet()
+	// --max-repos counts repositories rather than the refs searched in them,
+	// so the limit means the same thing with --all-branches or --tags.
+	counted := make(map[string]bool)
+
+	for _, e := range expanders {
+		for x := range e.ch {
+			if x.warning != "" {
+				if x.missing {
+					f.stats.missing.Add(1)
+				}
+				f.output.Warningf("%s", x.warning)
+			}
+			for _, repo := range x.repos {
+				if !opts.NoDedup && !seen.Add(repo) {
+					continue
+				}
+				if name := strings.ToLower(repo.FullName); opts.MaxRepos > 0 && !counted[name] {
+					if len(counted) == opts.MaxRepos {
+						f.output.Warningf("More than %d repositories matched; searching only the first %d (--max-repos)",
+							opts.MaxRepos, opts.MaxRepos)
+						return nil
+					}
+					counted[name] = true
+				}
+				select {
+				case out <- repo:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+		if e.err != nil {
+			return e.err
+		}
+	}
+
+	return nil
+}
+
+// expansion is a batch of repositories expanded from a spec, or a warning.
+type expansion struct {
+	repos   []github.Repository
+	warning string
+	missing bool // The warning is about a repository that couldn't be searched
+}
+
+// specExpander passes the repositories expanded from a spec, and any
+// warnings, to expandRepos in the order they're found.
+type specExpander struct {
+	ctx  context.Context
+	sem  *semaphore.Weighted
+	ch   chan expansion
+	held bool  // Whether the expander holds one of sem's slots
+	err  error // The error that stopped expansion, set before ch is closed
+}
+
+// put passes x to expandRepos, releasing the expander's slot while it waits
+// its turn.
+func (e *specExpander) put(x expansion) error {
+	e.sem.Release(1)
+	e.held = false
+	select {
+	case e.ch <- x:
+	case <-e.ctx.Done():
+		return e.ctx.Err()
+	}
+	if err := e.sem.Acquire(e.ctx, 1); err != nil {
+		return err
+	}
+	e.held = true
+	return nil
+}
+
+// send passes repos to expandRepos.
+func (e *specExpander) send(repos []github.Repository) error {
+	return e.put(expansion{repos: repos})
+}
+
+// warnf passes a warning to expandRepos.
+func (e *specExpander) warnf(format string, args ...any) error {
+	return e.put(expansion{warning: fmt.Sprintf(format, args...)})
+}
+
+// missingf passes a warning about a repository that couldn't be searched to
+// expandRepos.
+func (e *specExpander) missingf(format string, args ...any) error {
+	return e.put(expansion{warning: fmt.Sprintf(format, args...), missing: true})
+}
+
+// expandSpec resolves a repo spec, passing the resulting repositories to e a
+// batch at a time.
+func (f *Finder) expandSpec(ctx context.Context, spec RepoSpec, opts *Options, e *specExpander) error {
+	// sendRefs sends each repository, or its matching branches and tags.
+	sendRefs := func(repos []github.Repository) error {
+		for _, repo := range repos {
+			refs, err := f.expandRefs(ctx, repo, opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				err = e.missingf("%s: %v", repo.FullName, err)
+			} else {
+				err = e.send(refs)
+			}
 			if err != nil {
-				f.output.Warningf("%s/%s: %v", spec.Owner, spec.Repo, err)
-				continue
+				return err
+			}
+		}
+		return nil
+	}
+
+	// sendExpanded sends the repositories that aren't filtered out.
+	sendExpanded := func(repos []github.Repository) error {
+		return sendRefs(slices.DeleteFunc(repos, func(repo github.Repository) bool {
+			return skipExpanded(repo, opts)
+		}))
+	}
+
+	// Fetch either the single named repo, the repos matching a search
+	// query, that a team has access to, that a user starred, or of the
+	// authenticated user, or all of an owner's repos (or just those
+	// matching a repo name glob).
+	switch {
+	case spec.Query != "":
+		total, err := f.client.SearchRepos(ctx, spec.Query, opts.RepoTypes, sendExpanded)
+		if err != nil {
+			return err
+		}
+		if total > github.SearchResultLimit {
+			return e.warnf("%s: matched %d repositories, but only the first %d can be searched",
+				spec, total, github.SearchResultLimit)
+		}
+	case spec.Team != "":
+		err := f.client.WalkTeamRepos(ctx, spec.Owner, spec.Team, opts.RepoTypes, sendExpanded)
+		if err != nil && (!opts.IgnoreMissing || !github.IsNotFound(err)) {
+			return err
+		}
+	case spec.Me:
+		err := f.client.WalkUserRepos(ctx, opts.Affiliation, opts.RepoTypes, sendExpanded)
+		if err != nil {
+			return err
+		}
+	case spec.Starred:
+		err := f.client.WalkStarredRepos(ctx, spec.Owner, opts.RepoTypes, sendExpanded)
+		if err != nil && (!opts.IgnoreMissing || !github.IsNotFound(err)) {
+			return err
+		}
+	case spec.Repo != "" && !spec.IsGlob():
+		r, err := f.client.GetRepo(ctx, spec.Owner, spec.Repo)
+		if errors.Is(err, github.ErrEmptyRepository) && opts.RepoTypes.Empty {
+			err = nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if !opts.IgnoreMissing || !github.IsNotFound(err) {
+				return e.missingf("%s/%s: %v", spec.Owner, spec.Repo, err)
+			}
+			return nil
+		}
+		if spec.Ref != "" {
+			r.Ref = spec.Ref
+			r.ExplicitRef = true
+		}
+		r.Subtree = spec.Path
+		if err := sendRefs([]github.Repository{r}); err != nil {
+			return err
+		}
+	default:
+		// GitHub can list repositories in most orders, but sorting by
+		// stars means waiting for the whole list.
+		var sorted []github.Repository
+		var matched bool
+		err := f.client.WalkRepos(ctx, spec.Owner, opts.RepoTypes, apiRepoSort(opts.RepoSort), func(repos []github.Repository) error {
+			repos = slices.DeleteFunc(repos, func(repo github.Repository) bool {
+				return !spec.Matches(repo) || skipExpanded(repo, opts)
+			})
+			for i := range repos {
+				if spec.Ref != "" {
+					repos[i].Ref = spec.Ref
+					repos[i].ExplicitRef = true
+				}
+				repos[i].Subtree = spec.Path
+			}
+			matched = matched || len(repos) > 0
+			if opts.RepoSort == RepoSortStars {
+				sorted = append(sorted, repos...)
+				return nil
 			}
-			if spec.Ref != "" {
-				r.Ref = spec.Ref
+			return sendRefs(repos)
+		})
+		if err != nil && (!opts.IgnoreMissing || !github.IsNotFound(err)) {
+			return err
+		}
+		if len(sorted) > 0 {
+			slices.SortStableFunc(sorted, func(a, b github.Repository) int {
+				return cmp.Compare(b.Stars, a.Stars)
+			})
+			if err := sendRefs(sorted); err != nil {
+				return err
+			}
+		}
+		if err == nil && spec.IsGlob() && !matched {
+			return e.warnf("%s: no repositories match", spec)
+		}
+	}
+
+	return nil
+}
+
+// apiRepoSort returns the GitHub API's sort parameter for listing an owner's
+// repositories in sort order, or "" if the API can't sort by it.
+func apiRepoSort(sort RepoSort) string {
+	switch sort {
+	case RepoSortPushed:
+		return "pushed"
+	case RepoSortName:
+		return "full_name"
+	}
+	return ""
+}
+
+// expandRefs returns the repository at each of its branches matching
+// opts.Branches and each of its tags matching opts.Tags. Repositories whose
+// spec named a ref are returned as-is, as are all repositories if neither
+// option is set.
+func (f *Finder) expandRefs(ctx context.Context, repo github.Repository, opts *Options) ([]github.Repository, error) {
+	if (opts.Branches == "" && opts.Tags == "") || repo.ExplicitRef {
+		return []github.Repository{repo}, nil
+	}
+
+	var repos []github.Repository
+	for _, refs := range []struct{ namespace, glob string }{
+		{github.RefBranches, opts.Branches},
+		{github.RefTags, opts.Tags},
+	} {
+		if refs.glob == "" {
+			continue
+		}
+
+		names, err := f.client.ListRefs(ctx, repo, refs.namespace, globPrefix(refs.glob))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			if doublestar.MatchUnvalidated(refs.glob, name) {
+				r := repo
+				r.Ref = name
 				r.ExplicitRef = true
+				repos = append(repos, r)
 			}
-			repos = []github.Repository{r}
-		} else {
-			repos, err = f.client.ListRepos(ctx, spec.Owner, opts.RepoTypes)
+		}
+	}
+	return repos, nil
+}
+
+// globPrefix returns the literal text at the start of a glob pattern, before
+// its first special character.
+func globPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[{\`); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+// repoKey returns the key identifying a repository at a ref, and the subtree
+// searched if it isn't the whole repository. GitHub owner and repository
+// names are case-insensitive, so the name is folded to lowercase; refs and
+// paths are case-sensitive and kept as-is.
+func repoKey(repo github.Repository) string {
+	key := strings.ToLower(repo.FullName) + "@" + repo.Ref
+	if repo.Subtree != "" {
+		key += ":" + repo.Subtree
+	}
+	return key
+}
+
+// repoSet is a concurrency-safe set of repositories keyed by name and ref.
+type repoSet struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func newRepoSet() *repoSet {
+	return &repoSet{seen: make(map[string]bool)}
+}
+
+// Add adds repo to the set, returning false if it was already present.
+func (s *repoSet) Add(repo github.Repository) bool {
+	key := repoKey(repo)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen[key] {
+		return false
+	}
+	s.seen[key] = true
+	return true
+}
+
+// repoOrder runs the work that finishes each repository's search in the
+// order the repositories were numbered, holding back any that finish early.
+type repoOrder struct {
+	mu      sync.Mutex
+	next    int
+	pending map[int]func()
+}
+
+// finish runs fn, and any held-back work it unblocks, once every repository
+// numbered before i has finished. Otherwise it holds fn back and returns.
+func (o *repoOrder) finish(i int, fn func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.pending == nil {
+		o.pending = make(map[int]func())
+	}
+	o.pending[i] = fn
+	for {
+		fn, ok := o.pending[o.next]
+		if !ok {
+			return
+		}
+		delete(o.pending, o.next)
+		o.next++
+		fn()
+	}
+}
+
+// filterByType keeps the entries of one of types. Content classes keep
+// every regular file and executable here, leaving it to filterByContent to
+// check their content once the cheaper filters are done.
+func filterByType(entries []github.TreeEntry, types []github.FileType, contents []ContentType) []github.TreeEntry {
+	if len(types) == 0 && len(contents) == 0 {
+		return entries
+	}
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		fileType := github.ParseFileType(entry.Mode)
+		if slices.Contains(types, fileType) || (len(contents) > 0 && hasContent(entry)) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// filterByBlobSHA keeps the files whose blob SHA starts with one of shas,
+// which are lowercase and may be abbreviated.
+func filterByBlobSHA(entries []github.TreeEntry, shas []string) []github.TreeEntry {
+	if len(shas) == 0 {
+		return entries
+	}
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		if !hasSize(entry) {
+			continue // trees and submodules don't have blobs
+		}
+		if slices.ContainsFunc(shas, func(sha string) bool {
+			return strings.HasPrefix(entry.SHA, sha)
+		}) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func filterByMode(entries []github.TreeEntry, modes []string) []github.TreeEntry {
+	if len(modes) == 0 {
+		return entries
+	}
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		if slices.Contains(modes, entry.Mode) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+func filterByExtension(entries []github.TreeEntry, extensions []string, ignoreCase bool) []github.TreeEntry {
+	if len(extensions) == 0 {
+		return entries
+	}
+
+	if ignoreCase {
+		normalized := make([]string, len(extensions))
+		for i, ext := range extensions {
+			normalized[i] = strings.ToLower(ext)
+		}
+		extensions = normalized
+	}
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		matchPath := entry.Path
+		if ignoreCase {
+			matchPath = strings.ToLower(matchPath)
+		}
+
+		ext := filepath.Ext(matchPath)
+		if ext != "" && slices.Contains(extensions, ext) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered
+}
+
+func filterBySize(entries []github.TreeEntry, minSize, maxSize int64) []github.TreeEntry {
+	if minSize == 0 && maxSize == 0 {
+		return entries
+	}
+
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !hasSize(entry) {
+			// Trees and submodules have no size, so don't treat them as empty.
+			filtered = append(filtered, entry)
+			continue
+		}
+		if minSize > 0 && entry.Size < minSize {
+			continue
+		}
+		if maxSize > 0 && entry.Size > maxSize {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	return filtered
+}
+
+// filterByDirs keeps the entries beneath any of dirs, or all of them if dirs
+// is empty.
+func filterByDirs(entries []github.TreeEntry, dirs []string) []github.TreeEntry {
+	if len(dirs) == 0 {
+		return entries
+	}
+
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if slices.ContainsFunc(dirs, func(dir string) bool {
+			return strings.HasPrefix(entry.Path, dir+"/")
+		}) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// filterByPrune removes the directories whose name, or full path for
+// patterns containing a slash, matches any of patterns, along with
+// everything beneath them.
+func filterByPrune(entries []github.TreeEntry, patterns []string, ignoreCase bool) []github.TreeEntry {
+	if len(patterns) == 0 {
+		return entries
+	}
+	if ignoreCase {
+		patterns = lowerAll(patterns)
+	}
+
+	pruned := make(map[string]bool)
+	for _, entry := range entries {
+		if github.ParseFileType(entry.Mode) != github.FileTypeDirectory {
+			continue
+		}
+		p := entry.Path
+		if ignoreCase {
+			p = strings.ToLower(p)
+		}
+		if slices.ContainsFunc(patterns, func(pattern string) bool {
+			if !strings.Contains(pattern, "/") {
+				return doublestar.MatchUnvalidated(pattern, path.Base(p))
+			}
+			return doublestar.MatchUnvalidated(pattern, p)
+		}) {
+			pruned[entry.Path] = true
+		}
+	}
+	if len(pruned) == 0 {
+		return entries
+	}
+
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !underPruned(entry.Path, pruned) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// underPruned reports whether p or any of its parent directories is pruned.
+func underPruned(p string, pruned map[string]bool) bool {
+	if pruned[p] {
+		return true
+	}
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' && pruned[p[:i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// filterByDepth keeps the entries whose depth is within [minDepth, maxDepth],
+// where 0 means no limit. Like find(1), top-level entries have depth 1, and
+// depth counts from subtree when searching only a directory.
+func filterByDepth(entries []github.TreeEntry, subtree string, minDepth, maxDepth int) []github.TreeEntry {
+	if minDepth == 0 && maxDepth == 0 {
+		return entries
+	}
+
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		depth := strings.Count(entry.Path, "/") + 1
+		if subtree != "" {
+			depth -= strings.Count(subtree, "/") + 1
+		}
+		if minDepth > 0 && depth < minDepth {
+			continue
+		}
+		if maxDepth > 0 && depth > maxDepth {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	return filtered
+}
+
+// filterByEmpty keeps only zero-byte blobs if empty is set. Unlike find(1),
+// it never keeps directories, since git doesn't store empty ones.
+func filterByEmpty(entries []github.TreeEntry, empty bool) []github.TreeEntry {
+	if !empty {
+		return entries
+	}
+
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if hasSize(entry) && entry.Size == 0 {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// hasSize reports whether the tree API reports a size for entry, which it
+// only does for blobs.
+func hasSize(entry github.TreeEntry) bool {
+	switch github.ParseFileType(entry.Mode) {
+	case github.FileTypeDirectory, github.FileTypeSubmodule:
+		return false
+	case github.FileTypeFile, github.FileTypeExecutable, github.FileTypeSymlink:
+	}
+	return true
+}
+
+// filterByPattern keeps the entries whose name, or full path if fullPath is
+// set, matches any of patterns.
+func filterByPattern(entries []github.TreeEntry, patterns []string, fullPath, ignoreCase bool) ([]github.TreeEntry, error) {
+	patterns = slices.Clone(patterns)
+	for i, pattern := range patterns {
+		pattern, err := expandPOSIXClasses(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if ignoreCase {
+			pattern = strings.ToLower(pattern)
+		}
+		patterns[i] = pattern
+	}
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		matchPath := entry.Path
+		if !fullPath {
+			matchPath = path.Base(matchPath)
+		}
+		if ignoreCase {
+			matchPath = strings.ToLower(matchPath)
+		}
+
+		for _, pattern := range patterns {
+			matched, err := doublestar.Match(pattern, matchPath)
 			if err != nil {
-				return err
+				return nil, fmt.Errorf("pattern %q failed to match path %q: %w", pattern, entry.Path, err)
+			}
+
+			if matched {
+				filtered = append(filtered, entry)
+				break
 			}
 		}
+	}
 
-		allRepos = append(allRepos, repos...)
+	return filtered, nil
+}
+
+func filterByExcludes(entries []github.TreeEntry, excludes []string, fullPath, ignoreCase bool) ([]github.TreeEntry, error) {
+	if len(excludes) == 0 {
+		return entries, nil
+	}
+
+	normalized := make([]string, len(excludes))
+	for i, exclude := range excludes {
+		exclude, err := expandPOSIXClasses(exclude)
+		if err != nil {
+			return nil, err
+		}
+		if ignoreCase {
+			exclude = strings.ToLower(exclude)
+		}
+		normalized[i] = exclude
+	}
+	excludes = normalized
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		matchPath := entry.Path
+		if !fullPath {
+			matchPath = path.Base(matchPath)
+		}
+		if ignoreCase {
+			matchPath = strings.ToLower(matchPath)
+		}
+
+		excluded := false
+		for _, excludePattern := range excludes {
+			isExcluded, err := doublestar.Match(excludePattern, matchPath)
+			if err != nil {
+				return nil, fmt.Errorf("exclude pattern %q failed to match path %q: %w",
+					excludePattern, entry.Path, err)
+			}
+			if isExcluded {
+				excluded = true
+				break
+			}
+		}
+
+		if !excluded {
+			filtered = append(filtered, entry)
+		}
 	}
 
-	// The full list of repos could contain duplicates (e.g. the user provided
-	// an explicit owner/repo name that was also expanded from owner/*). We
-	// deduplicate them while preserving input order.
-	seen := make(map[string]bool)
-	repos := make([]github.Repository, 0, len(allRepos))
-	for _, repo := range allRepos {
-		repoKey := repo.FullName + "@" + repo.Ref
-		if !seen[repoKey] {
-			seen[repoKey] = true
-			repos = append(repos, repo)
+	return filtered, nil
+}
+
+// filterByRegexp keeps the entries whose name, or full path if fullPath is
+// set, matches re and none of excludes. Unlike globs, the expressions match
+// anywhere in the name unless they're anchored.
+func filterByRegexp(entries []github.TreeEntry, re *regexp.Regexp, excludes []*regexp.Regexp, fullPath bool) []github.TreeEntry {
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		matchPath := entry.Path
+		if !fullPath {
+			matchPath = path.Base(matchPath)
+		}
+
+		if !re.MatchString(matchPath) {
+			continue
+		}
+		if slices.ContainsFunc(excludes, func(exclude *regexp.Regexp) bool {
+			return exclude.MatchString(matchPath)
+		}) {
+			continue
 		}
+		filtered = append(filtered, entry)
 	}
 
-	if len(repos) == 0 {
-		f.output.Warningf("No repositories match the filter")
-		return nil
+	return filtered
+}
+
+// filterBySubstring keeps the entries whose name, or full path if fullPath
+// is set, contains any of substrs and none of excludes.
+func filterBySubstring(entries []github.TreeEntry, substrs, excludes []string, fullPath, ignoreCase bool) []github.TreeEntry {
+	contains := func(s string) func(string) bool {
+		return func(substr string) bool { return strings.Contains(s, substr) }
+	}
+	if ignoreCase {
+		substrs = lowerAll(substrs)
+		excludes = lowerAll(excludes)
 	}
 
-	// Process repositories concurrently with bounded parallelism
-	var wg sync.WaitGroup
-	var errorCount atomic.Int32
-	sem := semaphore.NewWeighted(int64(opts.Jobs))
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		matchPath := entry.Path
+		if !fullPath {
+			matchPath = path.Base(matchPath)
+		}
+		if ignoreCase {
+			matchPath = strings.ToLower(matchPath)
+		}
 
-	for _, repo := range repos {
-		if err := sem.Acquire(ctx, 1); err != nil {
-			wg.Wait()
-			return err
+		if slices.ContainsFunc(substrs, contains(matchPath)) && !slices.ContainsFunc(excludes, contains(matchPath)) {
+			filtered = append(filtered, entry)
 		}
+	}
 
-		wg.Add(1)
-		go func(repo github.Repository) {
-			defer wg.Done()
-			defer sem.Release(1)
+	return filtered
+}
 
-			if err := f.searchRepo(ctx, repo, opts); err != nil {
-				errorCount.Add(1)
-				f.output.Warningf("%s: %v", repo.FullName, err)
-			}
-		}(repo)
+// lowerAll returns a copy of ss with each string lowercased.
+func lowerAll(ss []string) []string {
+	lower := make([]string, len(ss))
+	for i, s := range ss {
+		lower[i] = strings.ToLower(s)
 	}
+	return lower
+}
 
-	wg.Wait()
-
-	if int(errorCount.Load()) == len(repos) {
-		return fmt.Errorf("failed to search all %d repositories", len(repos))
+func filterByPaths(entries []github.TreeEntry, paths map[string]bool) []github.TreeEntry {
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if paths[entry.Path] {
+			filtered = append(filtered, entry)
+		}
 	}
+	return filtered
+}
 
-	return nil
+func filterByAttributes(entries []github.TreeEntry, attrs *gitattributes.Attributes) []github.TreeEntry {
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if attrs.IsSet(entry.Path, "linguist-generated") || attrs.IsSet(entry.Path, "linguist-vendored") {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
 }
 
-func filterByType(entries []github.TreeEntry, types []github.FileType) []github.TreeEntry {
-	if len(types) == 0 {
+// filterByLFS keeps only the blobs tracked by Git LFS (filter=lfs in
+// .gitattributes) if lfs is set, or drops them if noLFS is set. The size of
+// a tracked file is that of its pointer, not of its content.
+func filterByLFS(entries []github.TreeEntry, attrs *gitattributes.Attributes, lfs, noLFS bool) []github.TreeEntry {
+	if !lfs && !noLFS {
 		return entries
 	}
 
-	var filtered []github.TreeEntry
+	filtered := make([]github.TreeEntry, 0, len(entries))
 	for _, entry := range entries {
-		fileType := github.ParseFileType(entry.Mode)
-		if slices.Contains(types, fileType) {
+		value, _ := attrs.Lookup(entry.Path, "filter")
+		tracked := value == "lfs" && hasSize(entry)
+		if tracked == lfs {
 			filtered = append(filtered, entry)
 		}
 	}
 	return filtered
 }
 
-func filterByExtension(entries []github.TreeEntry, extensions []string, ignoreCase bool) []github.TreeEntry {
-	if len(extensions) == 0 {
+// filterByCaseCollision keeps the entries whose path differs only by case
+// from another path in tree, which can't both be checked out on a
+// case-insensitive filesystem. Every path in tree counts, not just entries,
+// so a match collides with files the pattern didn't match.
+func filterByCaseCollision(entries, tree []github.TreeEntry, enabled bool) []github.TreeEntry {
+	if !enabled {
 		return entries
 	}
 
-	if ignoreCase {
-		normalized := make([]string, len(extensions))
-		for i, ext := range extensions {
-			normalized[i] = strings.ToLower(ext)
-		}
-		extensions = normalized
+	counts := make(map[string]int, len(tree))
+	for _, entry := range tree {
+		counts[strings.ToLower(entry.Path)]++
 	}
 
 	var filtered []github.TreeEntry
 	for _, entry := range entries {
-		matchPath := entry.Path
-		if ignoreCase {
-			matchPath = strings.ToLower(matchPath)
-		}
-
-		ext := filepath.Ext(matchPath)
-		if ext != "" && slices.Contains(extensions, ext) {
+		if counts[strings.ToLower(entry.Path)] > 1 {
 			filtered = append(filtered, entry)
 		}
 	}
+	return filtered
+}
+
+func filterByIgnore(entries []github.TreeEntry, m *gitignore.Matcher) []github.TreeEntry {
+	if m == nil {
+		return entries
+	}
 
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		if m.Match(entry.Path, github.ParseFileType(entry.Mode) == github.FileTypeDirectory) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
 	return filtered
 }
 
-func filterBySize(entries []github.TreeEntry, minSize, maxSize int64) []github.TreeEntry {
-	if minSize == 0 && maxSize == 0 {
+func filterByDate(commits []github.FileCommitInfo, entries []github.TreeEntry, changedAfter, changedBefore *time.Time) []github.TreeEntry {
+	if changedAfter == nil && changedBefore == nil {
 		return entries
 	}
 
+	pathDates := make(map[string]time.Time, len(commits))
+	for _, info := range commits {
+		pathDates[info.Path] = info.CommittedDate
+	}
+
 	filtered := make([]github.TreeEntry, 0, len(entries))
 	for _, entry := range entries {
-		if minSize > 0 && entry.Size < minSize {
+		commitDate, ok := pathDates[entry.Path]
+		if !ok {
+			continue // No matching path, skip
+		}
+
+		if changedAfter != nil && commitDate.Before(*changedAfter) {
 			continue
 		}
-		if maxSize > 0 && entry.Size > maxSize {
+		if changedBefore != nil && commitDate.After(*changedBefore) {
 			continue
 		}
+
 		filtered = append(filtered, entry)
 	}
 
 	return filtered
 }
 
-func filterByPattern(entries []github.TreeEntry, pattern string, fullPath, ignoreCase bool) ([]github.TreeEntry, error) {
-	if ignoreCase {
-		pattern = strings.ToLower(pattern)
+// filterByAuthor keeps the entries whose last commit's author matches any of
+// authors, glob patterns compared without regard to case.
+func filterByAuthor(commits []github.FileCommitInfo, entries []github.TreeEntry, authors []string) []github.TreeEntry {
+	if len(authors) == 0 {
+		return entries
 	}
+	authors = lowerAll(authors)
 
-	var filtered []github.TreeEntry
-	for _, entry := range entries {
-		matchPath := entry.Path
-		if !fullPath {
-			matchPath = path.Base(matchPath)
-		}
-		if ignoreCase {
-			matchPath = strings.ToLower(matchPath)
-		}
+	pathAuthors := make(map[string]string, len(commits))
+	for _, info := range commits {
+		pathAuthors[info.Path] = strings.ToLower(info.Author)
+	}
 
-		matched, err := doublestar.Match(pattern, matchPath)
-		if err != nil {
-			return nil, fmt.Errorf("pattern %q failed to match path %q: %w", pattern, entry.Path, err)
+	filtered := make([]github.TreeEntry, 0, len(entries))
+	for _, entry := range entries {
+		author, ok := pathAuthors[entry.Path]
+		if !ok || author == "" {
+			continue
 		}
-
-		if matched {
+		if slices.ContainsFunc(authors, func(pattern string) bool {
+			return doublestar.MatchUnvalidated(pattern, author)
+		}) {
 			filtered = append(filtered, entry)
 		}
 	}
 
-	return filtered, nil
+	return filtered
 }
 
-func filterByExcludes(entries []github.TreeEntry, excludes []string, fullPath, ignoreCase bool) ([]github.TreeEntry, error) {
-	if len(excludes) == 0 {
-		return entries, nil
+// filterByCreated keeps the entries whose first commit falls within
+// [createdAfter, createdBefore], where nil means no bound.
+func filterByCreated(commits []github.FileCommitInfo, entries []github.TreeEntry, createdAfter, createdBefore *time.Time) []github.TreeEntry {
+	if createdAfter == nil && createdBefore == nil {
+		return entries
 	}
 
-	if ignoreCase {
-		normalized := make([]string, len(excludes))
-		for i, exclude := range excludes {
-			normalized[i] = strings.ToLower(exclude)
+	pathDates := make(map[string]time.Time, len(commits))
+	for _, info := range commits {
+		if !info.CreatedDate.IsZero() {
+			pathDates[info.Path] = info.CreatedDate
 		}
-		excludes = normalized
 	}
 
-	var filtered []github.TreeEntry
+	filtered := make([]github.TreeEntry, 0, len(entries))
 	for _, entry := range entries {
-		matchPath := entry.Path
-		if !fullPath {
-			matchPath = path.Base(matchPath)
-		}
-		if ignoreCase {
-			matchPath = strings.ToLower(matchPath)
+		createdDate, ok := pathDates[entry.Path]
+		if !ok {
+			continue
 		}
-
-		excluded := false
-		for _, excludePattern := range excludes {
-			isExcluded, err := doublestar.Match(excludePattern, matchPath)
-			if err != nil {
-				return nil, fmt.Errorf("exclude pattern %q failed to match path %q: %w",
-					excludePattern, entry.Path, err)
-			}
-			if isExcluded {
-				excluded = true
-				break
-			}
+		if createdAfter != nil && createdDate.Before(*createdAfter) {
+			continue
 		}
-
-		if !excluded {
-			filtered = append(filtered, entry)
+		if createdBefore != nil && createdDate.After(*createdBefore) {
+			continue
 		}
+		filtered = append(filtered, entry)
 	}
 
-	return filtered, nil
+	return filtered
 }
 
-func filterByDate(commits []github.FileCommitInfo, entries []github.TreeEntry, changedAfter, changedBefore *time.Time) []github.TreeEntry {
-	if changedAfter == nil && changedBefore == nil {
+// filterByCommitCount keeps the entries whose history has between
+// minCommits and maxCommits commits, where 0 means no limit.
+func filterByCommitCount(commits []github.FileCommitInfo, entries []github.TreeEntry, minCommits, maxCommits int) []github.TreeEntry {
+	if minCommits == 0 && maxCommits == 0 {
 		return entries
 	}
 
-	pathDates := make(map[string]time.Time, len(commits))
+	pathCounts := make(map[string]int, len(commits))
 	for _, info := range commits {
-		pathDates[info.Path] = info.CommittedDate
+		pathCounts[info.Path] = info.Commits
 	}
 
 	filtered := make([]github.TreeEntry, 0, len(entries))
 	for _, entry := range entries {
-		commitDate, ok := pathDates[entry.Path]
+		count, ok := pathCounts[entry.Path]
 		if !ok {
-			continue // No matching path, skip
+			continue
 		}
-
-		if changedAfter != nil && commitDate.Before(*changedAfter) {
+		if minCommits > 0 && count < minCommits {
 			continue
 		}
-		if changedBefore != nil && commitDate.After(*changedBefore) {
+		if maxCommits > 0 && count > maxCommits {
 			continue
 		}
-
 		filtered = append(filtered, entry)
 	}
 
 	return filtered
 }
 
-func (f *Finder) searchRepo(ctx context.Context, repo github.Repository, opts *Options) error {
-	tree, err := f.client.GetTree(ctx, repo)
+// searchRepo returns the matches in a repository.
+func (f *Finder) searchRepo(ctx context.Context, repo github.Repository, opts *Options) ([]result, error) {
+	// Empty repositories have no tree, so they can't have any matches.
+	if repo.Empty() {
+		return nil, nil
+	}
+
+	// Search the repository as it was at opts.AsOf by pinning it to the
+	// last commit before then.
+	if opts.AsOf != nil {
+		sha, err := f.client.ResolveRefAt(ctx, repo, *opts.AsOf)
+		if err != nil {
+			return nil, err
+		}
+		repo.CommitSHA = sha
+	}
+
+	tree, err := f.getTree(ctx, &repo, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if tree.Truncated {
+		f.stats.truncated.Add(1)
 		f.output.Warningf("%s: exceeds GitHub's API limit (100k files or 7MB) - results are incomplete", repo.FullName)
 	}
 
-	entries := tree.Tree
-	entries = filterByType(entries, opts.FileTypes)
-	entries = filterByExtension(entries, opts.Extensions, opts.IgnoreCase)
-	entries = filterBySize(entries, opts.MinSize, opts.MaxSize)
-
-	entries, err = filterByPattern(entries, opts.Pattern, opts.FullPath, opts.IgnoreCase)
+	results, err := f.matchTree(ctx, repo, tree.Tree, opts)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Files changed by specific commits can't have been inherited.
+	if opts.CommunityHealth && len(opts.Commits) == 0 {
+		results = append(results, f.inheritedHealthFiles(ctx, repo, tree.Tree, opts)...)
+		results = limitMatches(results, opts.MaxPerRepo)
+	}
+
+	return results, nil
+}
+
+// getTree fetches the repository's tree. If opts.Ref is set and the
+// repository's spec didn't name a ref, the tree is fetched at opts.Ref
+// instead, falling back to the default branch with a warning if the
+// repository has no such ref. repo is updated to the ref that was used, and
+// to the commit it was pinned to for permalinks.
+func (f *Finder) getTree(ctx context.Context, repo *github.Repository, opts *Options) (*github.TreeResponse, error) {
+	if opts.Ref == "" || repo.ExplicitRef {
+		return f.getPinnedTree(ctx, repo, opts)
+	}
+
+	override := *repo
+	override.Ref = opts.Ref
+	override.ExplicitRef = true
+	tree, err := f.getPinnedTree(ctx, &override, opts)
+	if err == nil {
+		*repo = override
+		return tree, nil
+	}
+	if !github.IsNotFound(err) {
+		return nil, err
+	}
+
+	f.output.Warningf("%s: ref %s not found, searching the default branch (%s)", repo.FullName, opts.Ref, repo.Ref)
+	return f.getPinnedTree(ctx, repo, opts)
+}
+
+// getPinnedTree fetches the repository's tree, pinning its ref first so
+// that permalinks point at the commit that was searched even if the ref
+// moves during the search.
+func (f *Finder) getPinnedTree(ctx context.Context, repo *github.Repository, opts *Options) (*github.TreeResponse, error) {
+	if err := f.pinRef(ctx, repo, opts); err != nil {
+		return nil, err
 	}
+	return f.client.GetTree(ctx, *repo)
+}
 
-	entries, err = filterByExcludes(entries, opts.Excludes, opts.FullPath, opts.IgnoreCase)
+// pinRef resolves the repository's ref to a commit SHA for permalinks, if
+// they were requested and it isn't already pinned.
+func (f *Finder) pinRef(ctx context.Context, repo *github.Repository, opts *Options) error {
+	if !opts.Permalink || repo.CommitSHA != "" {
+		return nil
+	}
+	sha, err := f.client.ResolveRef(ctx, *repo)
 	if err != nil {
 		return err
 	}
+	repo.CommitSHA = sha
+	return nil
+}
+
+// matchTree returns the entries in a repository's tree that match opts.
+func (f *Finder) matchTree(ctx context.Context, repo github.Repository, tree []github.TreeEntry, opts *Options) ([]result, error) {
+	// Scoping to directories is cheap and narrows everything else. Then
+	// prune, while the directories are still there to match.
+	entries := filterByDirs(tree, opts.Paths)
+	entries = filterByPrune(entries, opts.Prune, opts.IgnoreCase)
+	collisions := tree
+	if opts.CaseCollisions {
+		var err error
+		if collisions, err = f.collisionTree(ctx, repo, tree); err != nil {
+			return nil, err
+		}
+	}
+	entries = filterByCaseCollision(entries, collisions, opts.CaseCollisions)
+	entries = filterByWindowsUnsafe(entries, opts.WindowsUnsafe)
+
+	if len(opts.Commits) > 0 {
+		changed, err := f.changedPaths(ctx, repo, opts.Commits)
+		if err != nil {
+			return nil, err
+		}
+		entries = filterByPaths(entries, changed)
+	}
+
+	entries = filterByType(entries, opts.FileTypes, opts.ContentTypes)
+	entries = filterByMode(entries, opts.Modes)
+	entries = filterByBlobSHA(entries, opts.BlobSHAs)
+	entries = filterByExtension(entries, opts.Extensions, opts.IgnoreCase)
+	entries = filterBySize(entries, opts.MinSize, opts.MaxSize)
+	entries = filterByEmpty(entries, opts.Empty)
+	entries = filterByDepth(entries, repo.Subtree, opts.MinDepth, opts.MaxDepth)
+
+	switch {
+	case opts.Regexp != nil:
+		entries = filterByRegexp(entries, opts.Regexp, opts.ExcludeRegexps, opts.FullPath)
+	case opts.FixedStrings:
+		entries = filterBySubstring(entries, opts.Patterns, opts.Excludes, opts.FullPath, opts.IgnoreCase)
+	default:
+		var err error
+		entries, err = filterByPattern(entries, opts.Patterns, opts.FullPath, opts.IgnoreCase)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err = filterByExcludes(entries, opts.Excludes, opts.FullPath, opts.IgnoreCase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	entries = filterByIgnore(entries, opts.Ignore)
+
+	// Only fetch .gitattributes if there is something left to filter. A repo
+	// without one has no generated or LFS-tracked files to report.
+	if (opts.NoGenerated || opts.LFS || opts.NoLFS) && len(entries) > 0 {
+		data, err := f.rootAttributes(ctx, repo, tree)
+		if err != nil {
+			return nil, err
+		}
+		attrs := gitattributes.Parse(data)
+		if opts.NoGenerated {
+			entries = filterByAttributes(entries, attrs)
+		}
+		entries = filterByLFS(entries, attrs, opts.LFS, opts.NoLFS)
+	}
+
+	if len(opts.ContentTypes) > 0 {
+		filtered, err := f.filterByContent(ctx, repo, entries, opts.FileTypes, opts.ContentTypes, opts.SniffContent)
+		if err != nil {
+			return nil, err
+		}
+		entries = filtered
+	}
+
+	if len(opts.SymlinkTargets) > 0 {
+		filtered, err := f.filterBySymlinkTarget(ctx, repo, entries, opts.SymlinkTargets)
+		if err != nil {
+			return nil, err
+		}
+		entries = filtered
+	}
+
+	// Apply the per-repository limit as early as possible so no requests
+	// are spent on matches that won't be kept: before fetching commit dates,
+	// unless they're needed to filter.
+	filterCreated := opts.CreatedAfter != nil || opts.CreatedBefore != nil
+	filterCount := opts.MinCommits > 0 || opts.MaxCommits > 0
+	filterCommits := opts.ChangedAfter != nil || opts.ChangedBefore != nil || len(opts.Authors) > 0 ||
+		filterCount || filterCreated
+	if !filterCommits {
+		entries = limitMatches(entries, opts.MaxPerRepo)
+	}
 
-	if opts.ChangedAfter != nil || opts.ChangedBefore != nil {
+	var commits map[string]github.FileCommitInfo
+	if (filterCommits || opts.ShowCommit || opts.CommitDates) && len(entries) > 0 {
 		paths := make([]string, len(entries))
 		for i, entry := range entries {
 			paths[i] = entry.Path
 		}
 
-		commits, err := f.client.GetFileCommitDates(ctx, repo, paths)
+		infos, err := f.client.GetFileCommitDates(ctx, repo, paths, filterCreated, filterCount)
 		if err != nil {
-			return err
+			if ctx.Err() != nil || len(infos) == 0 {
+				return nil, err
+			}
+			// Keep the matches from the batches that succeeded.
+			f.output.Warningf("%s: %v (results may be incomplete)", repo.FullName, err)
 		}
 
-		entries = filterByDate(commits, entries, opts.ChangedAfter, opts.ChangedBefore)
+		entries = filterByDate(infos, entries, opts.ChangedAfter, opts.ChangedBefore)
+		entries = filterByAuthor(infos, entries, opts.Authors)
+		entries = filterByCommitCount(infos, entries, opts.MinCommits, opts.MaxCommits)
+		entries = filterByCreated(infos, entries, opts.CreatedAfter, opts.CreatedBefore)
+		entries = limitMatches(entries, opts.MaxPerRepo)
+
+		commits = make(map[string]github.FileCommitInfo, len(infos))
+		for _, info := range infos {
+			commits[info.Path] = info
+		}
 	}
 
-	for _, entry := range entries {
-		f.output.Match(repo, entry.Path)
+	results := make([]result, len(entries))
+	for i, entry := range entries {
+		results[i] = result{repo: repo, entry: entry}
+		if info, ok := commits[entry.Path]; ok {
+			results[i].commit = &info
+		}
 	}
 
+	return results, nil
+}
+
+// emit writes a repository's results to the output and, if configured, the
+// sink.
+func (f *Finder) emit(ctx context.Context, repo github.Repository, results []result) error {
+	f.stats.addResults(results)
+	f.output.writeAll(results)
+	f.output.writeRepoCount(repo, len(results))
+	if f.sink != nil {
+		for _, r := range results {
+			if err := f.sink.Add(ctx, f.output.record(r)); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
+
+// changedPaths returns the union of the paths changed by the given commits.
+func (f *Finder) changedPaths(ctx context.Context, repo github.Repository, shas []string) (map[string]bool, error) {
+	paths := make(map[string]bool)
+	for _, sha := range shas {
+		commit, err := f.client.GetCommit(ctx, repo, sha)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range commit.Files {
+			paths[path] = true
+		}
+	}
+	return paths, nil
+}
+
+// limitMatches returns the first n matches, or all of them if n is 0.
+func limitMatches[T any](matches []T, n int) []T {
+	if n > 0 && len(matches) > n {
+		return matches[:n]
+	}
+	return matches
+}
+
+// rootAttributes returns the contents of the repository's root
+// .gitattributes, or nil if it has none. A tree that only covers a subtree
+// can't say whether the file exists, so it's fetched regardless.
+func (f *Finder) rootAttributes(ctx context.Context, repo github.Repository, tree []github.TreeEntry) ([]byte, error) {
+	if repo.Subtree == "" && !hasPath(tree, ".gitattributes") {
+		return nil, nil
+	}
+	data, err := f.client.GetFileContent(ctx, repo, ".gitattributes")
+	if repo.Subtree != "" && github.IsNotFound(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// collisionTree returns the tree that matches are checked against for case
+// collisions. A subtree's paths can also collide with paths outside it, such
+// as docs/README.md with Docs/README.md, so the whole tree is fetched.
+func (f *Finder) collisionTree(ctx context.Context, repo github.Repository, tree []github.TreeEntry) ([]github.TreeEntry, error) {
+	if repo.Subtree == "" {
+		return tree, nil
+	}
+	repo.Subtree = ""
+	root, err := f.client.GetTree(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	return root.Tree, nil
+}
+
+// hasPath reports whether the tree contains an entry with the given path.
+func hasPath(entries []github.TreeEntry, path string) bool {
+	return slices.ContainsFunc(entries, func(entry github.TreeEntry) bool {
+		return entry.Path == path
+	})
+}
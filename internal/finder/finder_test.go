@@ -1,11 +1,23 @@
 package finder
 
 import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
 	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/jparise/gh-find/internal/gitattributes"
 	"github.com/jparise/gh-find/internal/github"
+	"github.com/jparise/gh-find/internal/gitignore"
+	"golang.org/x/sync/semaphore"
+	"gopkg.in/h2non/gock.v1"
 )
 
 func treePaths(entries []github.TreeEntry) []string {
@@ -16,11 +28,399 @@ func treePaths(entries []github.TreeEntry) []string {
 	return paths
 }
 
+// testFinder creates a Finder backed by a real client for use with gock mocks.
+func testFinder(t *testing.T) (f *Finder, stdout, stderr *bytes.Buffer) {
+	t.Helper()
+	t.Cleanup(gock.Off)
+
+	// Intercept before creating the client so it picks up gock's transport.
+	gock.Intercept()
+	client, err := github.NewClient(github.ClientOptions{
+		AuthToken:    "fake-token",
+		DisableCache: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	stdout = &bytes.Buffer{}
+	stderr = &bytes.Buffer{}
+	f = New(stdout, stderr, OutputOptions{})
+	f.client = client
+	return f, stdout, stderr
+}
+
+// repoJSON creates a JSON object for a repository on its "main" branch.
+func repoJSON(owner, name string) string {
+	return fmt.Sprintf(
+		`{"name": %q, "full_name": %q, "owner": {"login": %q}, "default_branch": "main", "size": 1024, "html_url": %q}`,
+		name, owner+"/"+name, owner, "https://github.com/"+owner+"/"+name,
+	)
+}
+
+// reposPageJSON creates a JSON array of count repositories named repo<N>.
+func reposPageJSON(owner string, start, count int) string {
+	parts := make([]string, count)
+	for i := range count {
+		parts[i] = repoJSON(owner, fmt.Sprintf("repo%d", start+i))
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// mockOwner mocks the owner type lookup for an organization.
+func mockOwner(owner string) {
+	gock.New("https://api.github.com").
+		Get("/users/" + owner).
+		Reply(200).
+		JSON(fmt.Sprintf(`{"type": "Organization", "login": %q}`, owner))
+}
+
+// mockTree mocks a recursive tree response containing the given file paths.
+func mockTree(owner, repo string, paths ...string) {
+	entries := make([]string, len(paths))
+	for i, p := range paths {
+		entries[i] = fmt.Sprintf(`{"path": %q, "mode": "100644", "type": "blob", "size": 100}`, p)
+	}
+	gock.New("https://api.github.com").
+		Get("/repos/"+owner+"/"+repo+"/git/trees/main").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [` + strings.Join(entries, ",") + `], "truncated": false}`)
+}
+
+func TestFind(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	mockOwner("octo")
+	gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "1").
+		Reply(200).
+		JSON("[" + repoJSON("octo", "a") + "," + repoJSON("octo", "b") + "]")
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	mockTree("octo", "a", "main.go")
+	mockTree("octo", "b", "util.go", "README.md")
+
+	opts := &Options{
+		Patterns: []string{"*.go"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "octo", Repo: "a"},
+			{Owner: "octo"},
+		},
+		RepoTypes: github.RepoTypes{Sources: true},
+		Jobs:      2,
+	}
+
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	slices.Sort(lines)
+	want := []string{"octo/a:main.go", "octo/b:util.go"}
+	if !slices.Equal(lines, want) {
+		t.Errorf("find() output = %v, want %v", lines, want)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("find() wrote to stderr: %q", stderr.String())
+	}
+}
+
+func TestFindNoDedup(t *testing.T) {
+	tests := []struct {
+		name    string
+		noDedup bool
+		want    []string
+	}{
+		{name: "dedup", want: []string{"octo/a:main.go"}},
+		{name: "no dedup", noDedup: true, want: []string{"octo/a:main.go", "octo/a:main.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, stdout, _ := testFinder(t)
+
+			searches := 1
+			if tt.noDedup {
+				searches = 2
+			}
+			// The tree mocks come first because the repo mock's path would
+			// also match tree requests, which can be made while the second
+			// spec is still being expanded.
+			for range searches {
+				mockTree("octo", "a", "main.go")
+			}
+			gock.New("https://api.github.com").
+				Get("/repos/octo/a").
+				Times(2).
+				Reply(200).
+				JSON(repoJSON("octo", "a"))
+
+			opts := &Options{
+				Patterns:  []string{"*.go"},
+				RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}, {Owner: "octo", Repo: "a"}},
+				Jobs:      1,
+				NoDedup:   tt.noDedup,
+			}
+			if err := f.find(context.Background(), opts); err != nil {
+				t.Fatalf("find() error = %v", err)
+			}
+
+			lines := strings.Fields(stdout.String())
+			if !slices.Equal(lines, tt.want) {
+				t.Errorf("find() output = %v, want %v", lines, tt.want)
+			}
+			if !gock.IsDone() {
+				t.Errorf("not all mocks were called: %v", gock.Pending())
+			}
+		})
+	}
+}
+
+func TestExpandRepos_Pipelined(t *testing.T) {
+	f, _, _ := testFinder(t)
+
+	mockOwner("octo")
+	gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "1").
+		Reply(200).
+		JSON(reposPageJSON("octo", 1, 100))
+	page2 := gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "2").
+		Reply(200).
+		JSON(reposPageJSON("octo", 101, 1))
+
+	opts := &Options{
+		RepoSpecs: []RepoSpec{{Owner: "octo"}},
+		RepoTypes: github.RepoTypes{Sources: true},
+	}
+
+	out := make(chan github.Repository)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f.expandRepos(context.Background(), opts, semaphore.NewWeighted(1), out)
+		close(out)
+	}()
+
+	// The first repo arrives while the rest of the first page is still being
+	// sent, so the second page can't have been requested yet.
+	first := <-out
+	if first.Name != "repo1" {
+		t.Errorf("first repo = %q, want %q", first.Name, "repo1")
+	}
+	if page2.Mock.Done() {
+		t.Error("second page fetched before first page was consumed")
+	}
+
+	count := 1
+	for range out {
+		count++
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("expandRepos() error = %v", err)
+	}
+	if count != 101 {
+		t.Errorf("got %d repos, want 101", count)
+	}
+	if !page2.Mock.Done() {
+		t.Error("second page was never fetched")
+	}
+}
+
+func TestExpandRepos_Concurrent(t *testing.T) {
+	f, _, _ := testFinder(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/octo/slow").
+		Reply(200).
+		Delay(100 * time.Millisecond).
+		JSON(repoJSON("octo", "slow"))
+	fast := gock.New("https://api.github.com").
+		Get("/repos/octo/fast").
+		Reply(200).
+		JSON(repoJSON("octo", "fast"))
+
+	opts := &Options{
+		RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "slow"}, {Owner: "octo", Repo: "fast"}},
+	}
+
+	out := make(chan github.Repository)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f.expandRepos(context.Background(), opts, semaphore.NewWeighted(2), out)
+		close(out)
+	}()
+
+	// The second spec is expanded while the first is still being fetched,
+	// but its repository is still sent second.
+	var names []string
+	for repo := range out {
+		if len(names) == 0 && !fast.Mock.Done() {
+			t.Error("second spec wasn't expanded while the first was being fetched")
+		}
+		names = append(names, repo.Name)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("expandRepos() error = %v", err)
+	}
+	if want := []string{"slow", "fast"}; !slices.Equal(names, want) {
+		t.Errorf("expandRepos() sent %v, want %v", names, want)
+	}
+}
+
+func TestExpandRepos_Missing(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		ignoreMissing bool
+		wantWarning   bool
+		wantErr       bool
+	}{
+		{name: "not found warns", status: 404, wantWarning: true, wantErr: true},
+		{name: "not found ignored", status: 404, ignoreMissing: true},
+		{name: "server error warns", status: 500, wantWarning: true, wantErr: true},
+		{name: "server error still warns", status: 500, ignoreMissing: true, wantWarning: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, _, stderr := testFinder(t)
+
+			gock.New("https://api.github.com").
+				Get("/repos/octo/gone").
+				Reply(tt.status).
+				JSON(`{"message": "error"}`)
+			gock.New("https://api.github.com").
+				Get("/users/ghost").
+				Reply(tt.status).
+				JSON(`{"message": "error"}`)
+
+			opts := &Options{
+				RepoSpecs:     []RepoSpec{{Owner: "octo", Repo: "gone"}, {Owner: "ghost"}},
+				RepoTypes:     github.RepoTypes{Sources: true},
+				IgnoreMissing: tt.ignoreMissing,
+			}
+
+			out := make(chan github.Repository)
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- f.expandRepos(context.Background(), opts, semaphore.NewWeighted(1), out)
+				close(out)
+			}()
+			for range out {
+				t.Error("expandRepos() sent a repository")
+			}
+
+			if err := <-errCh; (err != nil) != tt.wantErr {
+				t.Errorf("expandRepos() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := stderr.Len() != 0; got != tt.wantWarning {
+				t.Errorf("expandRepos() warned = %v, want %v (stderr: %q)", got, tt.wantWarning, stderr.String())
+			}
+		})
+	}
+}
+
+func TestExpandRepos_Canceled(t *testing.T) {
+	f, _, _ := testFinder(t)
+
+	mockOwner("octo")
+	gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "1").
+		Reply(200).
+		JSON(reposPageJSON("octo", 1, 2))
+
+	opts := &Options{
+		RepoSpecs: []RepoSpec{{Owner: "octo"}},
+		RepoTypes: github.RepoTypes{Sources: true},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := make(chan github.Repository)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- f.expandRepos(ctx, opts, semaphore.NewWeighted(1), out)
+		close(out)
+	}()
+
+	<-out
+	cancel()
+
+	if err := <-errCh; err == nil {
+		t.Error("expected error after cancellation")
+	}
+}
+
+func TestRepoSet(t *testing.T) {
+	set := newRepoSet()
+
+	main := github.Repository{FullName: "cli/cli", Ref: "main"}
+	trunk := github.Repository{FullName: "cli/cli", Ref: "trunk"}
+
+	const numGoroutines = 10
+	var added sync.WaitGroup
+	var mu sync.Mutex
+	adds := 0
+
+	added.Add(numGoroutines)
+	for range numGoroutines {
+		go func() {
+			defer added.Done()
+			if set.Add(main) {
+				mu.Lock()
+				adds++
+				mu.Unlock()
+			}
+		}()
+	}
+	added.Wait()
+
+	if adds != 1 {
+		t.Errorf("concurrent Add() succeeded %d times, want 1", adds)
+	}
+	if !set.Add(trunk) {
+		t.Error("Add() rejected the same repo at a different ref")
+	}
+	if set.Add(github.Repository{FullName: "CLI/Cli", Ref: "main"}) {
+		t.Error("Add() accepted the same repo with a differently-cased name")
+	}
+	if !set.Add(github.Repository{FullName: "cli/cli", Ref: "MAIN"}) {
+		t.Error("Add() rejected a ref differing only in case")
+	}
+}
+
+func TestRepoOrder(t *testing.T) {
+	var order repoOrder
+	var got []int
+	record := func(i int) func() {
+		return func() { got = append(got, i) }
+	}
+
+	order.finish(2, record(2))
+	order.finish(1, record(1))
+	if len(got) != 0 {
+		t.Fatalf("finish() ran %v before repository 0 finished", got)
+	}
+	order.finish(0, record(0))
+	order.finish(3, record(3))
+
+	if want := []int{0, 1, 2, 3}; !slices.Equal(got, want) {
+		t.Errorf("finish() ran %v, want %v", got, want)
+	}
+}
+
 func TestFilterByType(t *testing.T) {
 	tests := []struct {
 		name      string
 		entries   []github.TreeEntry
 		types     []github.FileType
+		contents  []ContentType
 		wantPaths []string
 	}{
 		{
@@ -135,17 +535,248 @@ func TestFilterByType(t *testing.T) {
 			types:     []github.FileType{github.FileTypeFile},
 			wantPaths: []string{"data.txt"},
 		},
+		{
+			name: "content classes keep files and executables",
+			entries: []github.TreeEntry{
+				{Path: "main.go", Mode: "100644"},
+				{Path: "src", Mode: "040000"},
+				{Path: "build.sh", Mode: "100755"},
+				{Path: "link", Mode: "120000"},
+			},
+			contents:  []ContentType{ContentText},
+			wantPaths: []string{"main.go", "build.sh"},
+		},
+		{
+			name: "content classes OR with types",
+			entries: []github.TreeEntry{
+				{Path: "main.go", Mode: "100644"},
+				{Path: "src", Mode: "040000"},
+				{Path: "link", Mode: "120000"},
+			},
+			types:     []github.FileType{github.FileTypeDirectory},
+			contents:  []ContentType{ContentBinary},
+			wantPaths: []string{"main.go", "src"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByType(tt.entries, tt.types, tt.contents)
+
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByDirs(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "cmd"},
+		{Path: "cmd/root.go"},
+		{Path: "cmdline.go"},
+		{Path: "internal/finder/finder.go"},
+		{Path: "main.go"},
+	}
+
+	tests := []struct {
+		name      string
+		dirs      []string
+		wantPaths []string
+	}{
+		{
+			name:      "no dirs",
+			wantPaths: treePaths(entries),
+		},
+		{
+			name:      "beneath a directory",
+			dirs:      []string{"cmd"},
+			wantPaths: []string{"cmd/root.go"},
+		},
+		{
+			name:      "several directories",
+			dirs:      []string{"cmd", "internal"},
+			wantPaths: []string{"cmd/root.go", "internal/finder/finder.go"},
+		},
+		{
+			name:      "nested directory",
+			dirs:      []string{"internal/finder"},
+			wantPaths: []string{"internal/finder/finder.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByDirs(entries, tt.dirs)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByPrune(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "node_modules", Mode: "040000"},
+		{Path: "node_modules/lib/index.js", Mode: "100644"},
+		{Path: "src", Mode: "040000"},
+		{Path: "src/index.js", Mode: "100644"},
+		{Path: "src/node_modules", Mode: "040000"},
+		{Path: "src/node_modules/dep.js", Mode: "100644"},
+		{Path: "src/testdata", Mode: "040000"},
+		{Path: "src/testdata/fixture.js", Mode: "100644"},
+		{Path: "vendor", Mode: "100644"},
+	}
+
+	tests := []struct {
+		name       string
+		patterns   []string
+		ignoreCase bool
+		wantPaths  []string
+	}{
+		{
+			name:      "no patterns",
+			wantPaths: treePaths(entries),
+		},
+		{
+			name:      "basename at any depth",
+			patterns:  []string{"node_modules"},
+			wantPaths: []string{"src", "src/index.js", "src/testdata", "src/testdata/fixture.js", "vendor"},
+		},
+		{
+			name:      "full path",
+			patterns:  []string{"src/*"},
+			wantPaths: []string{"node_modules", "node_modules/lib/index.js", "src", "src/index.js", "vendor"},
+		},
+		{
+			name:      "files are not pruned",
+			patterns:  []string{"vendor"},
+			wantPaths: treePaths(entries),
+		},
+		{
+			name:       "ignore case",
+			patterns:   []string{"TESTDATA"},
+			ignoreCase: true,
+			wantPaths:  []string{"node_modules", "node_modules/lib/index.js", "src", "src/index.js", "src/node_modules", "src/node_modules/dep.js", "vendor"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := filterByType(tt.entries, tt.types)
+			got := filterByPrune(entries, tt.patterns, tt.ignoreCase)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByCaseCollision(t *testing.T) {
+	tree := []github.TreeEntry{
+		{Path: "README.md", Mode: "100644"},
+		{Path: "Readme.md", Mode: "100644"},
+		{Path: "Docs", Mode: "040000"},
+		{Path: "Docs/intro.md", Mode: "100644"},
+		{Path: "docs", Mode: "040000"},
+		{Path: "docs/guide.md", Mode: "100644"},
+		{Path: "main.go", Mode: "100644"},
+	}
+
+	// Only "*.md" matched, but the directories still collide with each
+	// other, and README.md with Readme.md.
+	entries := []github.TreeEntry{tree[0], tree[3], tree[5]}
+	got := filterByCaseCollision(entries, tree, true)
+	want := []string{"README.md"}
+	if !slices.Equal(treePaths(got), want) {
+		t.Errorf("got %v, want %v", treePaths(got), want)
+	}
+
+	got = filterByCaseCollision(tree, tree, true)
+	want = []string{"README.md", "Readme.md", "Docs", "docs"}
+	if !slices.Equal(treePaths(got), want) {
+		t.Errorf("got %v, want %v", treePaths(got), want)
+	}
+
+	if got := filterByCaseCollision(tree, tree, false); len(got) != len(tree) {
+		t.Errorf("filterByCaseCollision(false) kept %d entries, want %d", len(got), len(tree))
+	}
+}
+
+func TestFilterByEmpty(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "docs", Mode: "040000"},
+		{Path: "docs/.gitkeep", Mode: "100644", Size: 0},
+		{Path: "docs/index.md", Mode: "100644", Size: 512},
+		{Path: "run.sh", Mode: "100755", Size: 0},
+		{Path: "vendor/lib", Mode: "160000"},
+	}
+
+	if got := filterByEmpty(entries, false); len(got) != len(entries) {
+		t.Errorf("filterByEmpty(false) kept %d entries, want %d", len(got), len(entries))
+	}
+
+	got := filterByEmpty(entries, true)
+	want := []string{"docs/.gitkeep", "run.sh"}
+	if !slices.Equal(treePaths(got), want) {
+		t.Errorf("got %v, want %v", treePaths(got), want)
+	}
+}
+
+func TestFilterByDepth(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "go.mod"},
+		{Path: "cmd"},
+		{Path: "cmd/root.go"},
+		{Path: "internal/finder/finder.go"},
+	}
+
+	tests := []struct {
+		name      string
+		minDepth  int
+		maxDepth  int
+		wantPaths []string
+	}{
+		{
+			name:      "no limits",
+			wantPaths: []string{"go.mod", "cmd", "cmd/root.go", "internal/finder/finder.go"},
+		},
+		{
+			name:      "top level only",
+			maxDepth:  1,
+			wantPaths: []string{"go.mod", "cmd"},
+		},
+		{
+			name:      "skip shallow entries",
+			minDepth:  3,
+			wantPaths: []string{"internal/finder/finder.go"},
+		},
+		{
+			name:      "exact depth",
+			minDepth:  2,
+			maxDepth:  2,
+			wantPaths: []string{"cmd/root.go"},
+		},
+	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByDepth(entries, "", tt.minDepth, tt.maxDepth)
 			if !slices.Equal(treePaths(got), tt.wantPaths) {
 				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
 			}
 		})
 	}
+
+	// Searching a directory counts depth from the directory, like find(1)
+	// does from its starting point.
+	subtree := []github.TreeEntry{
+		{Path: "internal/finder/finder.go"},
+		{Path: "internal/finder/testdata/tree.json"},
+	}
+	got := filterByDepth(subtree, "internal/finder", 0, 1)
+	if want := []string{"internal/finder/finder.go"}; !slices.Equal(treePaths(got), want) {
+		t.Errorf("subtree: got %v, want %v", treePaths(got), want)
+	}
 }
 
 func TestFilterBySize(t *testing.T) {
@@ -243,6 +874,18 @@ func TestFilterBySize(t *testing.T) {
 			maxSize:   1000,
 			wantPaths: []string{"below.txt", "exact.txt"},
 		},
+		{
+			name: "directories and submodules have no size",
+			entries: []github.TreeEntry{
+				{Path: "docs", Mode: "040000"},
+				{Path: "vendor/lib", Mode: "160000"},
+				{Path: "small.txt", Mode: "100644", Size: 100},
+				{Path: "large.txt", Mode: "100644", Size: 2048},
+			},
+			minSize:   1024,
+			maxSize:   0,
+			wantPaths: []string{"docs", "vendor/lib", "large.txt"},
+		},
 		{
 			name: "impossible range - min > max returns nothing",
 			entries: []github.TreeEntry{
@@ -279,51 +922,69 @@ func TestFilterByPattern(t *testing.T) {
 		{Path: "internal/foo/bar.go"},
 		{Path: "README.md"},
 		{Path: "Test.GO"},
+		{Path: "docs/file1.txt"},
+		{Path: "docs/fileA.txt"},
 	}
 
 	tests := []struct {
 		name       string
-		pattern    string
+		patterns   []string
 		fullPath   bool
 		ignoreCase bool
 		wantPaths  []string
 	}{
 		{
 			name:      "simple wildcard basename",
-			pattern:   "*.go",
+			patterns:  []string{"*.go"},
 			fullPath:  false,
 			wantPaths: []string{"main.go", "cmd/root.go", "internal/foo/bar.go"},
 		},
 		{
 			name:      "glob pattern with fullpath",
-			pattern:   "**/*.go",
+			patterns:  []string{"**/*.go"},
 			fullPath:  true,
 			wantPaths: []string{"main.go", "cmd/root.go", "internal/foo/bar.go"},
 		},
 		{
 			name:       "case insensitive",
-			pattern:    "*.go",
+			patterns:   []string{"*.go"},
 			fullPath:   false,
 			ignoreCase: true,
 			wantPaths:  []string{"main.go", "cmd/root.go", "internal/foo/bar.go", "Test.GO"},
 		},
 		{
 			name:      "specific filename",
-			pattern:   "README.md",
+			patterns:  []string{"README.md"},
 			fullPath:  false,
 			wantPaths: []string{"README.md"},
 		},
 		{
 			name:      "no matches",
-			pattern:   "*.py",
+			patterns:  []string{"*.py"},
 			fullPath:  false,
 			wantPaths: []string{},
 		},
+		{
+			name:      "POSIX digit class",
+			patterns:  []string{"file[[:digit:]].txt"},
+			wantPaths: []string{"docs/file1.txt"},
+		},
+		{
+			name:      "negated POSIX class with fullpath",
+			patterns:  []string{"docs/file[![:digit:]].txt"},
+			fullPath:  true,
+			wantPaths: []string{"docs/fileA.txt"},
+		},
+		{
+			name:      "any of several patterns",
+			patterns:  []string{"*.md", "main.*"},
+			wantPaths: []string{"main.go", "README.md"},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := filterByPattern(entries, tt.pattern, tt.fullPath, tt.ignoreCase)
+			got, err := filterByPattern(entries, tt.patterns, tt.fullPath, tt.ignoreCase)
 			if err != nil {
 				t.Fatalf("filterByPattern() error = %v", err)
 			}
@@ -420,11 +1081,218 @@ func TestFilterByExcludes(t *testing.T) {
 	}
 }
 
-func TestFilterByExtension(t *testing.T) {
+func TestFilterByRegexp(t *testing.T) {
 	entries := []github.TreeEntry{
 		{Path: "main.go"},
-		{Path: "README.md"},
-		{Path: "config.yaml"},
+		{Path: "main_test.go"},
+		{Path: "cmd/root.go"},
+		{Path: "cmd/root_test.go"},
+		{Path: "README.md"},
+	}
+
+	tests := []struct {
+		name       string
+		pattern    string
+		excludes   []string
+		fullPath   bool
+		ignoreCase bool
+		wantPaths  []string
+	}{
+		{
+			name:      "unanchored",
+			pattern:   "_test",
+			wantPaths: []string{"main_test.go", "cmd/root_test.go"},
+		},
+		{
+			name:      "anchored",
+			pattern:   `^main\.go$`,
+			wantPaths: []string{"main.go"},
+		},
+		{
+			name:      "base name only",
+			pattern:   "^cmd/",
+			wantPaths: nil,
+		},
+		{
+			name:      "full path",
+			pattern:   "^cmd/",
+			fullPath:  true,
+			wantPaths: []string{"cmd/root.go", "cmd/root_test.go"},
+		},
+		{
+			name:      "excludes",
+			pattern:   `\.go$`,
+			excludes:  []string{"_test"},
+			wantPaths: []string{"main.go", "cmd/root.go"},
+		},
+		{
+			name:       "ignore case",
+			pattern:    "^readme",
+			ignoreCase: true,
+			wantPaths:  []string{"README.md"},
+		},
+		{
+			name:      "empty matches everything",
+			pattern:   "",
+			wantPaths: []string{"main.go", "main_test.go", "cmd/root.go", "cmd/root_test.go", "README.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			re, err := CompileRegexp(tt.pattern, tt.ignoreCase)
+			if err != nil {
+				t.Fatalf("CompileRegexp(%q) error = %v", tt.pattern, err)
+			}
+			var excludes []*regexp.Regexp
+			for _, exclude := range tt.excludes {
+				excludes = append(excludes, regexp.MustCompile(exclude))
+			}
+
+			got := filterByRegexp(entries, re, excludes, tt.fullPath)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterBySubstring(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "main.go"},
+		{Path: "main_test.go"},
+		{Path: "cmd/root.go"},
+		{Path: "cmd/root_test.go"},
+		{Path: "docs/[draft].md"},
+	}
+
+	tests := []struct {
+		name       string
+		substr     string
+		excludes   []string
+		fullPath   bool
+		ignoreCase bool
+		wantPaths  []string
+	}{
+		{
+			name:      "substring",
+			substr:    "_test",
+			wantPaths: []string{"main_test.go", "cmd/root_test.go"},
+		},
+		{
+			name:      "glob characters are literal",
+			substr:    "[draft]",
+			wantPaths: []string{"docs/[draft].md"},
+		},
+		{
+			name:      "base name only",
+			substr:    "cmd/",
+			wantPaths: nil,
+		},
+		{
+			name:      "full path",
+			substr:    "cmd/",
+			fullPath:  true,
+			wantPaths: []string{"cmd/root.go", "cmd/root_test.go"},
+		},
+		{
+			name:      "excludes",
+			substr:    ".go",
+			excludes:  []string{"_test"},
+			wantPaths: []string{"main.go", "cmd/root.go"},
+		},
+		{
+			name:       "ignore case",
+			substr:     "MAIN",
+			excludes:   []string{"TEST"},
+			ignoreCase: true,
+			wantPaths:  []string{"main.go"},
+		},
+		{
+			name:      "case sensitive",
+			substr:    "MAIN",
+			wantPaths: nil,
+		},
+		{
+			name:      "empty matches everything",
+			substr:    "",
+			wantPaths: []string{"main.go", "main_test.go", "cmd/root.go", "cmd/root_test.go", "docs/[draft].md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterBySubstring(entries, []string{tt.substr}, tt.excludes, tt.fullPath, tt.ignoreCase)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByMode(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "README.md", Mode: "100644"},
+		{Path: "shared.txt", Mode: "100664"},
+		{Path: "run.sh", Mode: "100755"},
+		{Path: "docs", Mode: "040000"},
+	}
+
+	tests := []struct {
+		name      string
+		modes     []string
+		wantPaths []string
+	}{
+		{name: "no modes", wantPaths: []string{"README.md", "shared.txt", "run.sh", "docs"}},
+		{name: "group-writable files", modes: []string{"100664"}, wantPaths: []string{"shared.txt"}},
+		{name: "several modes", modes: []string{"100755", "040000"}, wantPaths: []string{"run.sh", "docs"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByMode(entries, tt.modes)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByBlobSHA(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "a/install.sh", Mode: "100755", SHA: "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"},
+		{Path: "b/install.sh", Mode: "100755", SHA: "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"},
+		{Path: "README.md", Mode: "100644", SHA: "8ab686eafeb1f44702738c8b0f24f2567c36da6d"},
+		{Path: "docs", Mode: "040000", SHA: "3b18e512dba79e4c8300dd08aeb37f8e728b8dad"},
+	}
+
+	tests := []struct {
+		name      string
+		shas      []string
+		wantPaths []string
+	}{
+		{name: "no shas", wantPaths: []string{"a/install.sh", "b/install.sh", "README.md", "docs"}},
+		{name: "full sha", shas: []string{"3b18e512dba79e4c8300dd08aeb37f8e728b8dad"}, wantPaths: []string{"a/install.sh", "b/install.sh"}},
+		{name: "abbreviated sha", shas: []string{"8ab686e"}, wantPaths: []string{"README.md"}},
+		{name: "several shas", shas: []string{"3b18", "8ab6"}, wantPaths: []string{"a/install.sh", "b/install.sh", "README.md"}},
+		{name: "no matches", shas: []string{"ffff"}, wantPaths: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByBlobSHA(entries, tt.shas)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByExtension(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "main.go"},
+		{Path: "README.md"},
+		{Path: "config.yaml"},
 		{Path: "Test.GO"},
 		{Path: "noext"},
 	}
@@ -474,113 +1342,470 @@ func TestFilterByExtension(t *testing.T) {
 	}
 }
 
-func TestFilterByDate(t *testing.T) {
-	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
-	oneWeekAgo := now.Add(-7 * 24 * time.Hour)
-	twoWeeksAgo := now.Add(-14 * 24 * time.Hour)
-	threeWeeksAgo := now.Add(-21 * 24 * time.Hour)
-
+func TestFilterByPaths(t *testing.T) {
 	entries := []github.TreeEntry{
-		{Path: "recent.go"},
-		{Path: "week.go"},
-		{Path: "twoweeks.go"},
-		{Path: "old.go"},
-		{Path: "nodate.go"},
+		{Path: "main.go"},
+		{Path: "cmd"},
+		{Path: "cmd/root.go"},
+		{Path: "README.md"},
 	}
 
 	tests := []struct {
-		name          string
-		commits       []github.FileCommitInfo
-		changedAfter  *time.Time
-		changedBefore *time.Time
-		wantPaths     []string
+		name      string
+		paths     map[string]bool
+		wantPaths []string
 	}{
 		{
-			name: "no date filters - returns all",
-			commits: []github.FileCommitInfo{
-				{Path: "recent.go", CommittedDate: now},
-				{Path: "week.go", CommittedDate: oneWeekAgo},
-				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
-				{Path: "old.go", CommittedDate: threeWeeksAgo},
-			},
-			changedAfter:  nil,
-			changedBefore: nil,
-			wantPaths:     []string{"recent.go", "week.go", "twoweeks.go", "old.go", "nodate.go"},
-		},
-		{
-			name: "changed after filter - files newer than cutoff",
-			commits: []github.FileCommitInfo{
-				{Path: "recent.go", CommittedDate: now},
-				{Path: "week.go", CommittedDate: oneWeekAgo},
-				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
-				{Path: "old.go", CommittedDate: threeWeeksAgo},
-			},
-			changedAfter:  &oneWeekAgo,
-			changedBefore: nil,
-			wantPaths:     []string{"recent.go", "week.go"},
+			name:      "intersects with changed paths",
+			paths:     map[string]bool{"cmd/root.go": true, "README.md": true},
+			wantPaths: []string{"cmd/root.go", "README.md"},
 		},
 		{
-			name: "changed before filter - files older than cutoff",
-			commits: []github.FileCommitInfo{
-				{Path: "recent.go", CommittedDate: now},
-				{Path: "week.go", CommittedDate: oneWeekAgo},
-				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
-				{Path: "old.go", CommittedDate: threeWeeksAgo},
-			},
-			changedAfter:  nil,
-			changedBefore: &twoWeeksAgo,
-			wantPaths:     []string{"twoweeks.go", "old.go"},
+			name:      "removed files are ignored",
+			paths:     map[string]bool{"deleted.go": true},
+			wantPaths: []string{},
 		},
 		{
-			name: "both filters - date range",
-			commits: []github.FileCommitInfo{
-				{Path: "recent.go", CommittedDate: now},
-				{Path: "week.go", CommittedDate: oneWeekAgo},
-				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
-				{Path: "old.go", CommittedDate: threeWeeksAgo},
-			},
-			changedAfter:  &threeWeeksAgo,
-			changedBefore: &oneWeekAgo,
-			wantPaths:     []string{"week.go", "twoweeks.go", "old.go"},
+			name:      "no changed paths",
+			paths:     map[string]bool{},
+			wantPaths: []string{},
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByPaths(entries, tt.paths)
+
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByAttributes(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "main.go"},
+		{Path: "api/api.pb.go"},
+		{Path: "web/app.min.js"},
+		{Path: "vendor/lib/lib.go"},
+		{Path: "vendor/keep/keep.go"},
+	}
+
+	attrs := gitattributes.Parse([]byte(`
+*.pb.go        linguist-generated
+*.min.js       linguist-generated=true
+vendor/**      linguist-vendored
+vendor/keep/** -linguist-vendored
+`))
+
+	got := filterByAttributes(entries, attrs)
+	want := []string{"main.go", "vendor/keep/keep.go"}
+	if !slices.Equal(treePaths(got), want) {
+		t.Errorf("got %v, want %v", treePaths(got), want)
+	}
+}
+
+func TestFilterByLFS(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "main.go", Mode: "100644"},
+		{Path: "assets", Mode: "040000"},
+		{Path: "assets/logo.png", Mode: "100644"},
+		{Path: "assets/small.png", Mode: "100644"},
+		{Path: "data/model.bin", Mode: "100644"},
+	}
+
+	attrs := gitattributes.Parse([]byte(`
+*.bin            filter=lfs diff=lfs merge=lfs -text
+assets/**        filter=lfs diff=lfs merge=lfs -text
+assets/small.png !filter
+`))
+
+	tests := []struct {
+		name  string
+		lfs   bool
+		noLFS bool
+		want  []string
+	}{
 		{
-			name: "boundary - exact match on changedAfter",
-			commits: []github.FileCommitInfo{
-				{Path: "recent.go", CommittedDate: now},
-				{Path: "week.go", CommittedDate: oneWeekAgo},
-				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
-			},
-			changedAfter:  &oneWeekAgo,
-			changedBefore: nil,
-			wantPaths:     []string{"recent.go", "week.go"},
+			name: "no filter",
+			want: []string{"main.go", "assets", "assets/logo.png", "assets/small.png", "data/model.bin"},
 		},
 		{
-			name: "boundary - exact match on changedBefore",
-			commits: []github.FileCommitInfo{
-				{Path: "recent.go", CommittedDate: now},
-				{Path: "week.go", CommittedDate: oneWeekAgo},
-				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
-			},
-			changedAfter:  nil,
-			changedBefore: &oneWeekAgo,
-			wantPaths:     []string{"week.go", "twoweeks.go"},
+			name: "lfs only",
+			lfs:  true,
+			want: []string{"assets/logo.png", "data/model.bin"},
 		},
 		{
-			name: "no matches - all files too old",
-			commits: []github.FileCommitInfo{
-				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
-				{Path: "old.go", CommittedDate: threeWeeksAgo},
-			},
-			changedAfter:  &now,
-			changedBefore: nil,
-			wantPaths:     []string{},
+			name:  "no lfs",
+			noLFS: true,
+			want:  []string{"main.go", "assets", "assets/small.png"},
 		},
-		{
-			name: "no matches - all files too new",
-			commits: []github.FileCommitInfo{
-				{Path: "recent.go", CommittedDate: now},
-				{Path: "week.go", CommittedDate: oneWeekAgo},
-			},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByLFS(entries, attrs, tt.lfs, tt.noLFS)
+			if !slices.Equal(treePaths(got), tt.want) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterByIgnore(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "main.go", Mode: "100644"},
+		{Path: "build", Mode: "040000"},
+		{Path: "build/out.js", Mode: "100644"},
+		{Path: "debug.log", Mode: "100644"},
+		{Path: "keep.log", Mode: "100644"},
+		{Path: "tools/build", Mode: "100755"},
+	}
+
+	m := gitignore.Parse([]byte("build/\n*.log\n!keep.log\n"))
+
+	got := filterByIgnore(entries, m)
+	want := []string{"main.go", "keep.log", "tools/build"}
+	if !slices.Equal(treePaths(got), want) {
+		t.Errorf("got %v, want %v", treePaths(got), want)
+	}
+
+	if got := filterByIgnore(entries, nil); len(got) != len(entries) {
+		t.Errorf("filterByIgnore(nil) kept %d entries, want %d", len(got), len(entries))
+	}
+}
+
+func TestFindNoGenerated(t *testing.T) {
+	tests := []struct {
+		name        string
+		subtree     string
+		tree        []string
+		mockAttrs   bool
+		attrsStatus int
+		want        []string
+	}{
+		{
+			name:      "excludes generated files",
+			tree:      []string{".gitattributes", "main.go", "api.pb.go"},
+			mockAttrs: true,
+			want:      []string{"octo/a:main.go"},
+		},
+		{
+			name: "no .gitattributes skips fetch",
+			tree: []string{"main.go", "api.pb.go"},
+			want: []string{"octo/a:api.pb.go", "octo/a:main.go"},
+		},
+		{
+			// The root .gitattributes isn't in a subtree's tree.
+			name:      "subtree uses root .gitattributes",
+			subtree:   "api",
+			tree:      []string{"main.go", "api.pb.go"},
+			mockAttrs: true,
+			want:      []string{"octo/a:api/main.go"},
+		},
+		{
+			name:        "subtree without .gitattributes",
+			subtree:     "api",
+			tree:        []string{"main.go", "api.pb.go"},
+			mockAttrs:   true,
+			attrsStatus: 404,
+			want:        []string{"octo/a:api/api.pb.go", "octo/a:api/main.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, stdout, stderr := testFinder(t)
+
+			gock.New("https://api.github.com").
+				Get("/repos/octo/a").
+				Reply(200).
+				JSON(repoJSON("octo", "a"))
+			if tt.subtree != "" {
+				entries := make([]string, len(tt.tree))
+				for i, p := range tt.tree {
+					entries[i] = fmt.Sprintf(`{"path": %q, "mode": "100644", "type": "blob", "size": 100}`, p)
+				}
+				gock.New("https://api.github.com").
+					Get("/repos/octo/a/git/trees/main:"+tt.subtree).
+					MatchParam("recursive", "1").
+					Reply(200).
+					JSON(`{"tree": [` + strings.Join(entries, ",") + `], "truncated": false}`)
+			} else {
+				mockTree("octo", "a", tt.tree...)
+			}
+			if tt.mockAttrs {
+				gock.New("https://api.github.com").
+					Get("/repos/octo/a/contents/.gitattributes").
+					MatchParam("ref", "main").
+					Reply(cmp.Or(tt.attrsStatus, 200)).
+					JSON(`{"encoding": "base64", "content": "Ki5wYi5nbyBsaW5ndWlzdC1nZW5lcmF0ZWQK"}`)
+			}
+
+			opts := &Options{
+				Patterns:    []string{"*.go"},
+				RepoSpecs:   []RepoSpec{{Owner: "octo", Repo: "a", Path: tt.subtree}},
+				NoGenerated: true,
+				Jobs:        1,
+			}
+			if err := f.find(context.Background(), opts); err != nil {
+				t.Fatalf("find() error = %v", err)
+			}
+
+			lines := strings.Fields(stdout.String())
+			slices.Sort(lines)
+			if !slices.Equal(lines, tt.want) {
+				t.Errorf("find() output = %v, want %v", lines, tt.want)
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("find() wrote to stderr: %q", stderr.String())
+			}
+			if !gock.IsDone() {
+				t.Errorf("not all mocks were called: %v", gock.Pending())
+			}
+		})
+	}
+}
+
+func TestFilterByAuthor(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "main.go"},
+		{Path: "go.sum"},
+		{Path: "README.md"},
+		{Path: "unknown.go"},
+	}
+	commits := []github.FileCommitInfo{
+		{Path: "main.go", Author: "octocat"},
+		{Path: "go.sum", Author: "dependabot[bot]"},
+		{Path: "README.md", Author: "Mona Lisa"},
+	}
+
+	tests := []struct {
+		name      string
+		authors   []string
+		wantPaths []string
+	}{
+		{
+			name:      "no authors",
+			wantPaths: []string{"main.go", "go.sum", "README.md", "unknown.go"},
+		},
+		{
+			name:      "login",
+			authors:   []string{"octocat"},
+			wantPaths: []string{"main.go"},
+		},
+		{
+			name:      "ignore case",
+			authors:   []string{"OctoCat"},
+			wantPaths: []string{"main.go"},
+		},
+		{
+			name:      "glob",
+			authors:   []string{`*\[bot\]`},
+			wantPaths: []string{"go.sum"},
+		},
+		{
+			name:      "name without a login",
+			authors:   []string{"mona *"},
+			wantPaths: []string{"README.md"},
+		},
+		{
+			name:      "any of several",
+			authors:   []string{"octocat", "dependabot*"},
+			wantPaths: []string{"main.go", "go.sum"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByAuthor(commits, entries, tt.authors)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByCreated(t *testing.T) {
+	lastMonth := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastYear := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entries := []github.TreeEntry{
+		{Path: "new.yml"},
+		{Path: "old.yml"},
+		{Path: "unknown.yml"},
+	}
+	commits := []github.FileCommitInfo{
+		{Path: "new.yml", CreatedDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{Path: "old.yml", CreatedDate: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Path: "unknown.yml"},
+	}
+
+	tests := []struct {
+		name          string
+		createdAfter  *time.Time
+		createdBefore *time.Time
+		wantPaths     []string
+	}{
+		{name: "no filter", wantPaths: []string{"new.yml", "old.yml", "unknown.yml"}},
+		{name: "created after", createdAfter: &lastMonth, wantPaths: []string{"new.yml"}},
+		{name: "created before", createdBefore: &lastYear, wantPaths: []string{"old.yml"}},
+		{name: "range", createdAfter: &lastYear, createdBefore: &lastMonth, wantPaths: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByCreated(commits, entries, tt.createdAfter, tt.createdBefore)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByCommitCount(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "hot.go"},
+		{Path: "warm.go"},
+		{Path: "cold.go"},
+		{Path: "unknown.go"},
+	}
+	commits := []github.FileCommitInfo{
+		{Path: "hot.go", Commits: 120},
+		{Path: "warm.go", Commits: 10},
+		{Path: "cold.go", Commits: 1},
+	}
+
+	tests := []struct {
+		name       string
+		minCommits int
+		maxCommits int
+		wantPaths  []string
+	}{
+		{name: "no limits", wantPaths: []string{"hot.go", "warm.go", "cold.go", "unknown.go"}},
+		{name: "hot files", minCommits: 100, wantPaths: []string{"hot.go"}},
+		{name: "rarely touched files", maxCommits: 1, wantPaths: []string{"cold.go"}},
+		{name: "range", minCommits: 2, maxCommits: 100, wantPaths: []string{"warm.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterByCommitCount(commits, entries, tt.minCommits, tt.maxCommits)
+			if !slices.Equal(treePaths(got), tt.wantPaths) {
+				t.Errorf("got %v, want %v", treePaths(got), tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestFilterByDate(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	oneWeekAgo := now.Add(-7 * 24 * time.Hour)
+	twoWeeksAgo := now.Add(-14 * 24 * time.Hour)
+	threeWeeksAgo := now.Add(-21 * 24 * time.Hour)
+
+	entries := []github.TreeEntry{
+		{Path: "recent.go"},
+		{Path: "week.go"},
+		{Path: "twoweeks.go"},
+		{Path: "old.go"},
+		{Path: "nodate.go"},
+	}
+
+	tests := []struct {
+		name          string
+		commits       []github.FileCommitInfo
+		changedAfter  *time.Time
+		changedBefore *time.Time
+		wantPaths     []string
+	}{
+		{
+			name: "no date filters - returns all",
+			commits: []github.FileCommitInfo{
+				{Path: "recent.go", CommittedDate: now},
+				{Path: "week.go", CommittedDate: oneWeekAgo},
+				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
+				{Path: "old.go", CommittedDate: threeWeeksAgo},
+			},
+			changedAfter:  nil,
+			changedBefore: nil,
+			wantPaths:     []string{"recent.go", "week.go", "twoweeks.go", "old.go", "nodate.go"},
+		},
+		{
+			name: "changed after filter - files newer than cutoff",
+			commits: []github.FileCommitInfo{
+				{Path: "recent.go", CommittedDate: now},
+				{Path: "week.go", CommittedDate: oneWeekAgo},
+				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
+				{Path: "old.go", CommittedDate: threeWeeksAgo},
+			},
+			changedAfter:  &oneWeekAgo,
+			changedBefore: nil,
+			wantPaths:     []string{"recent.go", "week.go"},
+		},
+		{
+			name: "changed before filter - files older than cutoff",
+			commits: []github.FileCommitInfo{
+				{Path: "recent.go", CommittedDate: now},
+				{Path: "week.go", CommittedDate: oneWeekAgo},
+				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
+				{Path: "old.go", CommittedDate: threeWeeksAgo},
+			},
+			changedAfter:  nil,
+			changedBefore: &twoWeeksAgo,
+			wantPaths:     []string{"twoweeks.go", "old.go"},
+		},
+		{
+			name: "both filters - date range",
+			commits: []github.FileCommitInfo{
+				{Path: "recent.go", CommittedDate: now},
+				{Path: "week.go", CommittedDate: oneWeekAgo},
+				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
+				{Path: "old.go", CommittedDate: threeWeeksAgo},
+			},
+			changedAfter:  &threeWeeksAgo,
+			changedBefore: &oneWeekAgo,
+			wantPaths:     []string{"week.go", "twoweeks.go", "old.go"},
+		},
+		{
+			name: "boundary - exact match on changedAfter",
+			commits: []github.FileCommitInfo{
+				{Path: "recent.go", CommittedDate: now},
+				{Path: "week.go", CommittedDate: oneWeekAgo},
+				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
+			},
+			changedAfter:  &oneWeekAgo,
+			changedBefore: nil,
+			wantPaths:     []string{"recent.go", "week.go"},
+		},
+		{
+			name: "boundary - exact match on changedBefore",
+			commits: []github.FileCommitInfo{
+				{Path: "recent.go", CommittedDate: now},
+				{Path: "week.go", CommittedDate: oneWeekAgo},
+				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
+			},
+			changedAfter:  nil,
+			changedBefore: &oneWeekAgo,
+			wantPaths:     []string{"week.go", "twoweeks.go"},
+		},
+		{
+			name: "no matches - all files too old",
+			commits: []github.FileCommitInfo{
+				{Path: "twoweeks.go", CommittedDate: twoWeeksAgo},
+				{Path: "old.go", CommittedDate: threeWeeksAgo},
+			},
+			changedAfter:  &now,
+			changedBefore: nil,
+			wantPaths:     []string{},
+		},
+		{
+			name: "no matches - all files too new",
+			commits: []github.FileCommitInfo{
+				{Path: "recent.go", CommittedDate: now},
+				{Path: "week.go", CommittedDate: oneWeekAgo},
+			},
 			changedAfter:  nil,
 			changedBefore: &threeWeeksAgo,
 			wantPaths:     []string{},
@@ -614,3 +1839,1211 @@ func TestFilterByDate(t *testing.T) {
 		})
 	}
 }
+
+func TestFindCommunityHealth(t *testing.T) {
+	tests := []struct {
+		name      string
+		hasSource bool
+		want      []string
+	}{
+		{
+			name:      "inherits missing files",
+			hasSource: true,
+			want: []string{
+				"octo/a:CONTRIBUTING.md (inherited from octo/.github)",
+				"octo/a:docs/SECURITY.md",
+				"octo/b:.github/SECURITY.md (inherited from octo/.github)",
+				"octo/b:CONTRIBUTING.md (inherited from octo/.github)",
+			},
+		},
+		{
+			name: "no .github repository",
+			want: []string{"octo/a:docs/SECURITY.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, stdout, stderr := testFinder(t)
+
+			for _, name := range []string{"a", "b"} {
+				gock.New("https://api.github.com").
+					Get("/repos/octo/" + name).
+					Reply(200).
+					JSON(repoJSON("octo", name))
+			}
+			mockTree("octo", "a", "main.go", "docs/SECURITY.md")
+			mockTree("octo", "b", "main.go")
+
+			// The .github repository is only fetched once per owner.
+			if tt.hasSource {
+				gock.New("https://api.github.com").
+					Get("/repos/octo/.github").
+					Reply(200).
+					JSON(repoJSON("octo", ".github"))
+				mockTree("octo", ".github",
+					"CONTRIBUTING.md", "docs/CONTRIBUTING.md", "SECURITY.md",
+					".github/SECURITY.md", "profile/README.md", "LICENSE.md")
+			} else {
+				gock.New("https://api.github.com").
+					Get("/repos/octo/.github").
+					Reply(404).
+					JSON(`{"message": "Not Found"}`)
+			}
+
+			opts := &Options{
+				Patterns:        []string{"*.md"},
+				RepoSpecs:       []RepoSpec{{Owner: "octo", Repo: "a"}, {Owner: "octo", Repo: "b"}},
+				CommunityHealth: true,
+				Jobs:            1,
+			}
+			if err := f.find(context.Background(), opts); err != nil {
+				t.Fatalf("find() error = %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+			slices.Sort(lines)
+			if !slices.Equal(lines, tt.want) {
+				t.Errorf("find() output = %q, want %q", lines, tt.want)
+			}
+			if stderr.Len() != 0 {
+				t.Errorf("find() wrote to stderr: %q", stderr.String())
+			}
+			if !gock.IsDone() {
+				t.Errorf("not all mocks were called: %v", gock.Pending())
+			}
+		})
+	}
+}
+
+func TestFindShowCommit(t *testing.T) {
+	f, stdout, _ := testFinder(t)
+	f.output = NewOutput(stdout, &bytes.Buffer{}, OutputOptions{ShowCommit: true})
+
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	mockTree("octo", "a", "main.go", "README.md")
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		Reply(200).
+		JSON(`{"data":{"repository":{"object":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z","oid":"8b4c2a1f"}]}}}}}`)
+
+	opts := &Options{
+		Patterns:   []string{"*.go"},
+		RepoSpecs:  []RepoSpec{{Owner: "octo", Repo: "a"}},
+		ShowCommit: true,
+		Jobs:       1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	want := "octo/a:main.go https://github.com/octo/a/commit/8b4c2a1f\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("find() output = %q, want %q", got, want)
+	}
+	if !gock.IsDone() {
+		t.Errorf("not all mocks were called: %v", gock.Pending())
+	}
+}
+
+func TestFindPreview(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/main").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [
+			{"path": "notes.txt", "mode": "100644", "type": "blob", "size": 100, "sha": "aaa"},
+			{"path": "logo.txt", "mode": "100644", "type": "blob", "size": 100, "sha": "bbb"},
+			{"path": "missing.txt", "mode": "100644", "type": "blob", "size": 100, "sha": "ccc"}
+		], "truncated": false}`)
+	// Only the leading bytes of each file are requested.
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/blobs/aaa").
+		MatchHeader("Range", "^bytes=0-7999$").
+		Reply(206).
+		BodyString("first line\nsecond líne\nthird line\n")
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/blobs/bbb").
+		MatchHeader("Range", "^bytes=0-7999$").
+		Reply(206).
+		BodyString("\x89PNG\r\n\x1a\n\x00\x00")
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/blobs/ccc").
+		Reply(404).
+		JSON(`{"message": "Not Found"}`)
+
+	opts := &Options{
+		Patterns:  []string{"*.txt"},
+		RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}},
+		Preview:   20, // ends inside the two-byte "í"
+		Jobs:      1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	want := "octo/a:notes.txt\n    first line\n    second l\nocto/a:logo.txt\nocto/a:missing.txt\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("find() output = %q, want %q", got, want)
+	}
+	if !strings.Contains(stderr.String(), "missing.txt") {
+		t.Errorf("expected warning about missing.txt, got: %q", stderr.String())
+	}
+	if !gock.IsDone() {
+		t.Errorf("not all mocks were called: %v", gock.Pending())
+	}
+}
+
+func TestFindPreviewLimited(t *testing.T) {
+	tests := []struct {
+		name       string
+		sortKey    SortKey
+		duplicates bool
+		want       string
+	}{
+		{
+			name: "streamed",
+			want: "octo/a:b.txt\n    bbb\n",
+		},
+		{
+			// Sorting picks which matches are written, so previews wait
+			// until it has.
+			name:    "sorted",
+			sortKey: SortPath,
+			want:    "octo/a:a.txt\n    aaa\n",
+		},
+		{
+			name:       "duplicates",
+			duplicates: true,
+			want:       "aaa (2 copies, 100)\n  octo/a:a.txt\n    aaa\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, stdout, stderr := testFinder(t)
+			f.output = NewOutput(stdout, stderr, OutputOptions{MaxResults: 1, Sort: tt.sortKey, Duplicates: tt.duplicates})
+
+			gock.New("https://api.github.com").
+				Get("/repos/octo/a").
+				Reply(200).
+				JSON(repoJSON("octo", "a"))
+			gock.New("https://api.github.com").
+				Get("/repos/octo/a/git/trees/main").
+				MatchParam("recursive", "1").
+				Reply(200).
+				JSON(`{"tree": [
+					{"path": "b.txt", "mode": "100644", "type": "blob", "size": 100, "sha": "bbb"},
+					{"path": "a.txt", "mode": "100644", "type": "blob", "size": 100, "sha": "aaa"},
+					{"path": "c.txt", "mode": "100644", "type": "blob", "size": 100, "sha": "aaa"}
+				], "truncated": false}`)
+			for _, sha := range []string{"aaa", "bbb"} {
+				gock.New("https://api.github.com").
+					Get("/repos/octo/a/git/blobs/" + sha).
+					Reply(206).
+					BodyString(sha + "\n")
+			}
+
+			opts := &Options{
+				Patterns:  []string{"*.txt"},
+				RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}},
+				Preview:   20,
+				Jobs:      1,
+			}
+			if err := f.find(context.Background(), opts); err != nil {
+				t.Fatalf("find() error = %v", err)
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("find() output = %q, want %q", got, tt.want)
+			}
+			// The match that isn't written isn't previewed.
+			if pending := gock.Pending(); len(pending) != 1 {
+				t.Errorf("fetched %d previews, want 1", 2-len(pending))
+			}
+		})
+	}
+}
+
+func TestFindPostToExpandError(t *testing.T) {
+	f, _, stderr := testFinder(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a$").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	mockTree("octo", "a", "main.go")
+	gock.New("https://api.github.com").
+		Get("/users/ghost").
+		Reply(500).
+		JSON(`{"message": "error"}`)
+	gock.New("https://sink.example.com").
+		Post("/matches").
+		Reply(200)
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}, {Owner: "ghost"}},
+		RepoTypes: github.RepoTypes{Sources: true},
+		PostTo:    "https://sink.example.com/matches",
+		Jobs:      1,
+	}
+
+	// The match from octo/a is still sent, although expanding ghost failed.
+	if err := f.find(context.Background(), opts); err == nil {
+		t.Errorf("find() error = nil, want the expansion error; stderr: %s", stderr)
+	}
+	if !gock.IsDone() {
+		t.Errorf("not all mocks were called: %v", gock.Pending())
+	}
+}
+
+func TestFindStats(t *testing.T) {
+	f, _, stderr := testFinder(t)
+
+	mockOwner("octo")
+	gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "1").
+		Reply(200).
+		JSON("[" + repoJSON("octo", "a") + "," + repoJSON("octo", "b") + "," + repoJSON("octo", "c") + "]")
+	mockTree("octo", "a", "main.go", "util.go", "README.md")
+	gock.New("https://api.github.com").
+		Get("/repos/octo/b/git/trees/main").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "b.go", "mode": "100644", "type": "blob", "size": 50}], "truncated": true}`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/c/git/trees/main").
+		Reply(500)
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo"}},
+		RepoTypes: github.RepoTypes{Sources: true},
+		Jobs:      1,
+		Stats:     true,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"Repositories: 3 searched, 1 failed, 1 truncated\n",
+		"Matches:      3 (250 bytes)\n",
+		"API requests: 5\n",
+		"Elapsed:      ",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr missing %q, got: %q", want, stderr.String())
+		}
+	}
+}
+
+func TestFindCount(t *testing.T) {
+	f, stdout, _ := testFinder(t)
+	f.output = NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Format: FormatCount})
+
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	gock.New("https://api.github.com").
+		Get("/repos/octo/b").
+		Reply(200).
+		JSON(repoJSON("octo", "b"))
+	mockTree("octo", "a", "main.go", "util.go", "README.md")
+	mockTree("octo", "b", "README.md")
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}, {Owner: "octo", Repo: "b"}},
+		Jobs:      1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	want := "octo/a:2\nocto/b:0\ntotal:2\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("find() output = %q, want %q", got, want)
+	}
+}
+
+func TestFindQuiet(t *testing.T) {
+	for _, quiet := range []bool{false, true} {
+		t.Run(fmt.Sprintf("quiet=%v", quiet), func(t *testing.T) {
+			f, stdout, stderr := testFinder(t)
+			format := FormatText
+			if quiet {
+				format = FormatQuiet
+			}
+			f.output = NewOutput(stdout, stderr, OutputOptions{Format: format})
+
+			gock.New("https://api.github.com").
+				Get("/repos/octo/a").
+				Reply(200).
+				JSON(repoJSON("octo", "a"))
+			mockTree("octo", "a", "main.go", "README.md")
+
+			opts := &Options{
+				Patterns:   []string{"*.md"},
+				Extensions: []string{"go"},
+				RepoSpecs:  []RepoSpec{{Owner: "octo", Repo: "a"}},
+				Jobs:       1,
+				Quiet:      quiet,
+			}
+			if err := f.find(context.Background(), opts); err != nil {
+				t.Fatalf("find() error = %v", err)
+			}
+
+			if got := strings.Contains(stderr.String(), "can't match"); got == quiet {
+				t.Errorf("conflict hint shown = %v with quiet = %v: %q", got, quiet, stderr.String())
+			}
+			if f.Matches() != 0 || f.Failures() != 0 {
+				t.Errorf("Matches() = %d, Failures() = %d, want 0, 0", f.Matches(), f.Failures())
+			}
+		})
+	}
+}
+
+func TestFindMaxResultsStopsEarly(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+	f.output = NewOutput(stdout, stderr, OutputOptions{MaxResults: 1})
+
+	mockOwner("octo")
+	gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "1").
+		Reply(200).
+		JSON("[" + repoJSON("octo", "a") + "," + repoJSON("octo", "b") + "," + repoJSON("octo", "c") + "]")
+	mockTree("octo", "a", "main.go", "util.go")
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo"}},
+		RepoTypes: github.RepoTypes{Sources: true},
+		Jobs:      1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	if got, want := stdout.String(), "octo/a:main.go\n"; got != want {
+		t.Errorf("find() output = %q, want %q", got, want)
+	}
+	if got, want := stderr.String(), "... (showing first 1 matches; stopped searching at the limit)\n"; got != want {
+		t.Errorf("find() stderr = %q, want %q", got, want)
+	}
+	if f.Failures() != 0 {
+		t.Errorf("Failures() = %d, want 0", f.Failures())
+	}
+	if gock.HasUnmatchedRequest() {
+		t.Errorf("find() searched repositories after reaching the limit: %v", gock.GetUnmatchedRequests())
+	}
+}
+
+func TestFindMaxPerRepo(t *testing.T) {
+	f, stdout, _ := testFinder(t)
+
+	for _, name := range []string{"a", "b"} {
+		gock.New("https://api.github.com").
+			Get("/repos/octo/" + name).
+			Reply(200).
+			JSON(repoJSON("octo", name))
+	}
+	mockTree("octo", "a", "a1.go", "a2.go", "a3.go")
+	mockTree("octo", "b", "b1.go")
+
+	opts := &Options{
+		Patterns:   []string{"*.go"},
+		RepoSpecs:  []RepoSpec{{Owner: "octo", Repo: "a"}, {Owner: "octo", Repo: "b"}},
+		MaxPerRepo: 2,
+		Jobs:       1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	if got, want := stdout.String(), "octo/a:a1.go\nocto/a:a2.go\nocto/b:b1.go\n"; got != want {
+		t.Errorf("find() output = %q, want %q", got, want)
+	}
+}
+
+func TestFindOrdered(t *testing.T) {
+	f, stdout, _ := testFinder(t)
+
+	for _, name := range []string{"a", "b"} {
+		gock.New("https://api.github.com").
+			Get("/repos/octo/" + name).
+			Reply(200).
+			JSON(repoJSON("octo", name))
+	}
+	// Repository a finishes last.
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/main").
+		MatchParam("recursive", "1").
+		Reply(200).
+		Delay(50 * time.Millisecond).
+		JSON(`{"tree": [{"path": "a.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	mockTree("octo", "b", "b.go")
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}, {Owner: "octo", Repo: "b"}},
+		Ordered:   true,
+		Jobs:      2,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	if got, want := stdout.String(), "octo/a:a.go\nocto/b:b.go\n"; got != want {
+		t.Errorf("find() output = %q, want %q", got, want)
+	}
+}
+
+func TestFindPermalink(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+	f.output = NewOutput(stdout, stderr, OutputOptions{Format: FormatNDJSON})
+
+	const sha = "abc1234def5678abc1234def5678abc1234def56"
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/commits").
+		MatchParam("sha", "main").
+		Reply(200).
+		JSON(`[{"sha": "` + sha + `"}]`)
+	// The tree is fetched at the pinned commit, not the branch, so the
+	// links can't point at a later push.
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/"+sha).
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "main.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}},
+		Permalink: true,
+		Jobs:      1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(stdout.Bytes(), &record); err != nil {
+		t.Fatalf("failed to decode output %q: %v", stdout.String(), err)
+	}
+	if want := "https://github.com/octo/a/blob/" + sha + "/main.go"; record.URL != want {
+		t.Errorf("URL = %q, want %q", record.URL, want)
+	}
+	if record.Ref != "main" {
+		t.Errorf("Ref = %q, want %q", record.Ref, "main")
+	}
+}
+
+func TestFindPermalinkRefFallback(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+	f.output = NewOutput(stdout, stderr, OutputOptions{Format: FormatNDJSON})
+
+	const sha = "abc1234def5678abc1234def5678abc1234def56"
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/commits").
+		MatchParam("sha", "release").
+		Reply(404).
+		JSON(`{"message": "No commit found for SHA: release"}`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/commits").
+		MatchParam("sha", "main").
+		Reply(200).
+		JSON(`[{"sha": "` + sha + `"}]`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/"+sha).
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "main.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}},
+		Ref:       "release",
+		Permalink: true,
+		Jobs:      1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(stdout.Bytes(), &record); err != nil {
+		t.Fatalf("failed to decode output %q: %v", stdout.String(), err)
+	}
+	if want := "https://github.com/octo/a/blob/" + sha + "/main.go"; record.URL != want {
+		t.Errorf("URL = %q, want %q", record.URL, want)
+	}
+	if want := "octo/a: ref release not found, searching the default branch (main)"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+	}
+}
+
+func TestFindRef(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	// Specs are expanded ahead of the search, so any of them may be fetched.
+	for _, name := range []string{"a", "b", "c", "d"} {
+		gock.New("https://api.github.com").
+			Get("/repos/octo/" + name).
+			Reply(200).
+			JSON(repoJSON("octo", name))
+	}
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/release").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "release.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/b/git/trees/release").
+		MatchParam("recursive", "1").
+		Reply(404).
+		JSON(`{"message": "Not Found"}`)
+	mockTree("octo", "b", "main.go")
+	mockTree("octo", "c", "main.go")
+
+	opts := &Options{
+		Patterns: []string{"*.go"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "octo", Repo: "a"},
+			{Owner: "octo", Repo: "b"},
+			{Owner: "octo", Repo: "c", Ref: "main"},
+		},
+		Ref:     "release",
+		Ordered: true,
+		Jobs:    1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	want := "octo/a@release:release.go\nocto/b:main.go\nocto/c@main:main.go\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if want := "octo/b: ref release not found, searching the default branch (main)"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+	}
+	if gock.HasUnmatchedRequest() {
+		t.Error("unexpected unmatched requests")
+	}
+}
+
+func TestFindBranches(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/matching-refs/heads/feature/").
+		Reply(200).
+		JSON(`[{"ref": "refs/heads/feature/x"}, {"ref": "refs/heads/feature/y/z"}]`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/feature/x").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "x.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/b").
+		Reply(200).
+		JSON(repoJSON("octo", "b"))
+	mockTree("octo", "b", "main.go")
+
+	opts := &Options{
+		Patterns: []string{"*.go"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "octo", Repo: "a"},
+			{Owner: "octo", Repo: "b", Ref: "main"},
+		},
+		Branches: "feature/*",
+		Ordered:  true,
+		Jobs:     1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	want := "octo/a@feature/x:x.go\nocto/b@main:main.go\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("unexpected stderr: %q", stderr.String())
+	}
+	if gock.HasUnmatchedRequest() {
+		t.Error("unexpected unmatched requests")
+	}
+}
+
+func TestFindTags(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/matching-refs/heads/main").
+		Reply(200).
+		JSON(`[{"ref": "refs/heads/main"}, {"ref": "refs/heads/main-old"}]`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/matching-refs/tags/v").
+		Reply(200).
+		JSON(`[{"ref": "refs/tags/v1.0"}, {"ref": "refs/tags/v2.0"}]`)
+	mockTree("octo", "a", "main.go")
+	for _, tag := range []string{"v1.0", "v2.0"} {
+		gock.New("https://api.github.com").
+			Get("/repos/octo/a/git/trees/"+tag).
+			MatchParam("recursive", "1").
+			Reply(200).
+			JSON(`{"tree": [{"path": "main.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	}
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}},
+		Branches:  "main",
+		Tags:      "v*",
+		Ordered:   true,
+		Jobs:      1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	want := "octo/a@main:main.go\nocto/a@v1.0:main.go\nocto/a@v2.0:main.go\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("unexpected stderr: %q", stderr.String())
+	}
+}
+
+func TestGlobPrefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"release-*", "release-"},
+		{"feature/**", "feature/"},
+		{"v[0-9]*", "v"},
+		{"{main,trunk}", ""},
+		{"main", "main"},
+		{`a\*`, "a"},
+	}
+
+	for _, tt := range tests {
+		if got := globPrefix(tt.pattern); got != tt.want {
+			t.Errorf("globPrefix(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestFindAsOf(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+	f.output = NewOutput(stdout, stderr, OutputOptions{Format: FormatNDJSON})
+
+	const sha = "abc1234def5678abc1234def5678abc1234def56"
+	asOf := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, name := range []string{"a", "b"} {
+		gock.New("https://api.github.com").
+			Get("/repos/octo/" + name).
+			Reply(200).
+			JSON(repoJSON("octo", name))
+	}
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/commits").
+		MatchParam("sha", "main").
+		MatchParam("until", "2023-01-01T00:00:00Z").
+		Reply(200).
+		JSON(`[{"sha": "` + sha + `"}]`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/"+sha).
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "old.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/b/commits").
+		MatchParam("sha", "main").
+		MatchParam("until", "2023-01-01T00:00:00Z").
+		Reply(200).
+		JSON(`[]`)
+
+	opts := &Options{
+		Patterns: []string{"*.go"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "octo", Repo: "a"},
+			{Owner: "octo", Repo: "b"},
+		},
+		AsOf:      &asOf,
+		Permalink: true,
+		Jobs:      1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	var record Record
+	if err := json.Unmarshal(stdout.Bytes(), &record); err != nil {
+		t.Fatalf("failed to decode output %q: %v", stdout.String(), err)
+	}
+	if want := "https://github.com/octo/a/blob/" + sha + "/old.go"; record.URL != want {
+		t.Errorf("URL = %q, want %q", record.URL, want)
+	}
+	if want := "octo/b: no commits to main before 2023-01-01"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+	}
+	if gock.HasUnmatchedRequest() {
+		t.Error("unexpected unmatched requests")
+	}
+}
+
+func TestFindExcludeRepo(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	mockOwner("octo")
+	gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "1").
+		Reply(200).
+		JSON("[" + repoJSON("octo", "app") + "," + repoJSON("octo", "app-archive") + "," + repoJSON("octo", "Sandbox-x") + "]")
+	gock.New("https://api.github.com").
+		Get("/repos/octo/sandbox-y").
+		Reply(200).
+		JSON(repoJSON("octo", "sandbox-y"))
+	mockTree("octo", "app", "main.go")
+	mockTree("octo", "sandbox-y", "main.go")
+
+	opts := &Options{
+		Patterns: []string{"*.go"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "octo"},
+			{Owner: "octo", Repo: "sandbox-y"},
+		},
+		RepoTypes:    github.RepoTypes{Sources: true},
+		ExcludeRepos: []string{"*-archive", "sandbox-*"},
+		Ordered:      true,
+		Jobs:         2,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	want := "octo/app:main.go\nocto/sandbox-y:main.go\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("unexpected stderr: %q", stderr.String())
+	}
+	if gock.HasUnmatchedRequest() {
+		t.Error("unexpected unmatched requests")
+	}
+}
+
+func TestFindRepoGlob(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	mockOwner("octo")
+	mockOwner("octo")
+	gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "1").
+		Times(2).
+		Reply(200).
+		JSON("[" + repoJSON("octo", "terraform-aws") + "," + repoJSON("octo", "Terraform-GCP") + "," + repoJSON("octo", "app") + "]")
+	gock.New("https://api.github.com").
+		Get("/repos/octo/terraform-aws/git/trees/release").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "main.tf", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/Terraform-GCP/git/trees/release").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "main.tf", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+
+	opts := &Options{
+		Patterns: []string{"*.tf"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "octo", Repo: "terraform-*", Ref: "release"},
+			{Owner: "octo", Repo: "helm-*"},
+		},
+		RepoTypes: github.RepoTypes{Sources: true},
+		Ordered:   true,
+		Jobs:      2,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	want := "octo/terraform-aws@release:main.tf\nocto/Terraform-GCP@release:main.tf\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if want := "octo/helm-*: no repositories match"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+	}
+}
+
+func TestFindRepoQuery(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	gock.New("https://api.github.com").
+		Get("/search/repositories").
+		MatchParam("q", "org:octo topic:kubernetes").
+		Reply(200).
+		JSON(`{"total_count": 1500, "items": [` + repoJSON("octo", "chart") + "," + repoJSON("octo", "chart-archive") + `]}`)
+	mockTree("octo", "chart", "values.yaml")
+
+	opts := &Options{
+		Patterns:     []string{"*.yaml"},
+		RepoSpecs:    []RepoSpec{{Query: "org:octo topic:kubernetes"}},
+		ExcludeRepos: []string{"*-archive"},
+		Jobs:         1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	if want := "octo/chart:values.yaml\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+	want := "search:org:octo topic:kubernetes: matched 1500 repositories, but only the first 1000 can be searched"
+	if !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+	}
+}
+
+func TestFindMaxRepos(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	// Specs are expanded ahead of the search, so any of them may be fetched.
+	for _, name := range []string{"a", "b", "c", "d"} {
+		gock.New("https://api.github.com").
+			Get("/repos/octo/" + name).
+			Reply(200).
+			JSON(repoJSON("octo", name))
+	}
+	mockTree("octo", "a", "main.go")
+	mockTree("octo", "b", "main.go")
+
+	opts := &Options{
+		Patterns: []string{"*.go"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "octo", Repo: "a"},
+			{Owner: "octo", Repo: "b"},
+			{Owner: "octo", Repo: "c"},
+			{Owner: "octo", Repo: "d"},
+		},
+		MaxRepos: 2,
+		Ordered:  true,
+		Jobs:     1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	if want := "octo/a:main.go\nocto/b:main.go\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+	want := "Warning: More than 2 repositories matched; searching only the first 2 (--max-repos)\n"
+	if stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+	if f.Failures() != 0 {
+		t.Errorf("Failures() = %d, want 0", f.Failures())
+	}
+}
+
+func TestFindMaxReposRefs(t *testing.T) {
+	f, stdout, stderr := testFinder(t)
+
+	for _, name := range []string{"a", "b", "c"} {
+		gock.New("https://api.github.com").
+			Get("/repos/octo/" + name + "$").
+			Persist().
+			Reply(200).
+			JSON(repoJSON("octo", name))
+	}
+	mockTree("octo", "a", "main.go")
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/dev").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "dev.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	mockTree("octo", "b", "main.go")
+
+	// Both of octo/a's refs count as one repository.
+	opts := &Options{
+		Patterns: []string{"*.go"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "octo", Repo: "a", Ref: "main"},
+			{Owner: "octo", Repo: "a", Ref: "dev"},
+			{Owner: "octo", Repo: "b"},
+			{Owner: "octo", Repo: "c"},
+		},
+		MaxRepos: 2,
+		Ordered:  true,
+		Jobs:     1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	if want := "octo/a@main:main.go\nocto/a@dev:dev.go\nocto/b:main.go\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+	want := "Warning: More than 2 repositories matched; searching only the first 2 (--max-repos)\n"
+	if stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+}
+
+func TestFindRepoSort(t *testing.T) {
+	starred := func(name string, stars int) string {
+		return strings.TrimSuffix(repoJSON("octo", name), "}") + fmt.Sprintf(`, "stargazers_count": %d}`, stars)
+	}
+
+	tests := []struct {
+		name     string
+		sort     RepoSort
+		wantSort string
+		want     string
+	}{
+		{
+			name: "stars",
+			sort: RepoSortStars,
+			want: "octo/b:main.go\nocto/c:main.go\nocto/a:main.go\n",
+		},
+		{
+			name:     "pushed",
+			sort:     RepoSortPushed,
+			wantSort: "pushed",
+			want:     "octo/a:main.go\nocto/b:main.go\nocto/c:main.go\n",
+		},
+		{
+			name:     "name",
+			sort:     RepoSortName,
+			wantSort: "full_name",
+			want:     "octo/a:main.go\nocto/b:main.go\nocto/c:main.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, stdout, _ := testFinder(t)
+
+			mockOwner("octo")
+			req := gock.New("https://api.github.com").
+				Get("/orgs/octo/repos").
+				MatchParam("page", "1")
+			if tt.wantSort != "" {
+				req.MatchParam("sort", tt.wantSort)
+			}
+			req.Reply(200).
+				JSON("[" + starred("a", 1) + "," + starred("b", 30) + "," + starred("c", 20) + "]")
+			for _, name := range []string{"a", "b", "c"} {
+				mockTree("octo", name, "main.go")
+			}
+
+			opts := &Options{
+				Patterns:  []string{"*.go"},
+				RepoSpecs: []RepoSpec{{Owner: "octo"}},
+				RepoTypes: github.RepoTypes{Sources: true},
+				RepoSort:  tt.sort,
+				Ordered:   true,
+				Jobs:      1,
+			}
+			if err := f.find(context.Background(), opts); err != nil {
+				t.Fatalf("find() error = %v", err)
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindIncludeEmpty(t *testing.T) {
+	emptyJSON := strings.Replace(repoJSON("octo", "empty"), `"size": 1024`, `"size": 0`, 1)
+
+	tests := []struct {
+		name         string
+		includeEmpty bool
+		want         string
+		wantStderr   string
+	}{
+		{
+			name:       "skipped",
+			want:       "octo/a:1\ntotal:1\n",
+			wantStderr: "Warning: octo/empty: repository is empty (no commits yet)\n",
+		},
+		{
+			name:         "included",
+			includeEmpty: true,
+			want:         "octo/a:1\nocto/empty:0\ntotal:1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, stdout, stderr := testFinder(t)
+			f.output = NewOutput(stdout, stderr, OutputOptions{Format: FormatCount})
+
+			gock.New("https://api.github.com").
+				Get("/repos/octo/a").
+				Reply(200).
+				JSON(repoJSON("octo", "a"))
+			gock.New("https://api.github.com").
+				Get("/repos/octo/empty").
+				Reply(200).
+				JSON(emptyJSON)
+			mockTree("octo", "a", "main.go")
+
+			opts := &Options{
+				Patterns:  []string{"*.go"},
+				RepoSpecs: []RepoSpec{{Owner: "octo", Repo: "a"}, {Owner: "octo", Repo: "empty"}},
+				RepoTypes: github.RepoTypes{Sources: true, Empty: tt.includeEmpty},
+				Ordered:   true,
+				Jobs:      1,
+			}
+			if err := f.find(context.Background(), opts); err != nil {
+				t.Fatalf("find() error = %v", err)
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if got := stderr.String(); got != tt.wantStderr {
+				t.Errorf("stderr = %q, want %q", got, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestFindListRepos(t *testing.T) {
+	f, stdout, _ := testFinder(t)
+
+	mockOwner("octo")
+	gock.New("https://api.github.com").
+		Get("/orgs/octo/repos").
+		MatchParam("page", "1").
+		Reply(200).
+		JSON("[" + repoJSON("octo", "a") + "," + repoJSON("octo", "b") + "]")
+
+	opts := &Options{
+		Patterns:  []string{"*.go"},
+		RepoSpecs: []RepoSpec{{Owner: "octo"}},
+		RepoTypes: github.RepoTypes{Sources: true},
+		ListRepos: true,
+		Jobs:      1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	if want := "octo/a\tmain\t1048576\nocto/b\tmain\t1048576\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+	if gock.HasUnmatchedRequest() {
+		t.Errorf("find() fetched trees: %v", gock.GetUnmatchedRequests())
+	}
+}
+
+func TestFindRepoPath(t *testing.T) {
+	f, stdout, _ := testFinder(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/golang/go/git/trees/main:src/crypto").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "tls/conn.go", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	gock.New("https://api.github.com").
+		Get("/repos/golang/go$").
+		Times(2).
+		Reply(200).
+		JSON(repoJSON("golang", "go"))
+	mockTree("golang", "go", "src/crypto/tls/conn.go", "src/net/http/server.go")
+
+	opts := &Options{
+		Patterns: []string{"*.go"},
+		RepoSpecs: []RepoSpec{
+			{Owner: "golang", Repo: "go", Path: "src/crypto"},
+			{Owner: "golang", Repo: "go"},
+		},
+		Ordered: true,
+		Jobs:    1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	// The whole repository isn't a duplicate of its subtree.
+	want := "golang/go:src/crypto/tls/conn.go\ngolang/go:src/crypto/tls/conn.go\ngolang/go:src/net/http/server.go\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestFindRepoPathCaseCollisions(t *testing.T) {
+	f, stdout, _ := testFinder(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a$").
+		Reply(200).
+		JSON(repoJSON("octo", "a"))
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/trees/main:docs").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [{"path": "README.md", "mode": "100644", "type": "blob", "size": 100}, {"path": "guide.md", "mode": "100644", "type": "blob", "size": 100}], "truncated": false}`)
+	// docs/README.md collides with a path outside the subtree.
+	mockTree("octo", "a", "Docs/README.md", "docs/README.md", "docs/guide.md")
+
+	opts := &Options{
+		Patterns:       []string{"*.md"},
+		RepoSpecs:      []RepoSpec{{Owner: "octo", Repo: "a", Path: "docs"}},
+		CaseCollisions: true,
+		Jobs:           1,
+	}
+	if err := f.find(context.Background(), opts); err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+
+	if want := "octo/a:docs/README.md\n"; stdout.String() != want {
+		t.Errorf("output = %q, want %q", stdout.String(), want)
+	}
+	if !gock.IsDone() {
+		t.Errorf("not all mocks were called: %v", gock.Pending())
+	}
+}
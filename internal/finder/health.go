@@ -0,0 +1,207 @@
+package finder
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// healthRepoName is the name of the repository that holds an owner's default
+// community health files.
+const healthRepoName = ".github"
+
+// healthFiles is the set of community health files that GitHub inherits from
+// an owner's .github repository, keyed by lowercase file name.
+var healthFiles = map[string]bool{
+	"code_of_conduct.md":       true,
+	"contributing.md":          true,
+	"funding.yml":              true,
+	"governance.md":            true,
+	"pull_request_template.md": true,
+	"security.md":              true,
+	"support.md":               true,
+}
+
+// healthTemplateDirs are the directories whose templates are inherited as a
+// whole: a repository with any template of its own inherits none of them.
+var healthTemplateDirs = []string{
+	".github/discussion_template/",
+	".github/issue_template/",
+}
+
+// healthFileKey returns the key identifying the community health file at p,
+// or false if p isn't one. Health files may live in the repository root, in
+// docs/, or in .github/, and GitHub uses the first one it finds regardless of
+// location, so the key ignores the directory.
+func healthFileKey(p string) (string, bool) {
+	lower := strings.ToLower(p)
+	for _, dir := range healthTemplateDirs {
+		if strings.HasPrefix(lower, dir) {
+			return dir, true
+		}
+	}
+
+	dir, name := path.Split(lower)
+	switch dir {
+	case "", "docs/", ".github/":
+		return name, healthFiles[name]
+	default:
+		return "", false
+	}
+}
+
+// healthKeys returns the keys of the community health files in entries.
+func healthKeys(entries []github.TreeEntry) map[string]bool {
+	keys := make(map[string]bool)
+	for _, entry := range entries {
+		if key, ok := healthFileKey(entry.Path); ok {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// healthSource holds an owner's default community health files that match
+// the search options. It is loaded at most once per owner.
+type healthSource struct {
+	once    sync.Once
+	repo    github.Repository
+	results []result
+}
+
+// healthCache maps lowercase owner names to their health sources.
+type healthCache struct {
+	mu      sync.Mutex
+	sources map[string]*healthSource
+}
+
+func (c *healthCache) get(owner string) *healthSource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sources == nil {
+		c.sources = make(map[string]*healthSource)
+	}
+	key := strings.ToLower(owner)
+	source, ok := c.sources[key]
+	if !ok {
+		source = &healthSource{}
+		c.sources[key] = source
+	}
+	return source
+}
+
+// healthSource returns the matching default community health files from the
+// owner's .github repository. An owner without a public .github repository
+// has no defaults. Other errors are reported once per owner and treated the
+// same way.
+func (f *Finder) healthSource(ctx context.Context, owner string, opts *Options) *healthSource {
+	source := f.health.get(owner)
+	source.once.Do(func() {
+		repo, results, err := f.loadHealthFiles(ctx, owner, opts)
+		if err != nil && ctx.Err() == nil {
+			f.output.Warningf("%s/%s: %v (community health files not inherited)", owner, healthRepoName, err)
+			return
+		}
+		if err != nil {
+			return // canceled, e.g. because the search stopped early
+		}
+		source.repo = repo
+		source.results = results
+	})
+	return source
+}
+
+func (f *Finder) loadHealthFiles(ctx context.Context, owner string, opts *Options) (github.Repository, []result, error) {
+	repo, err := f.client.GetRepo(ctx, owner, healthRepoName)
+	if err != nil {
+		if github.IsNotFound(err) {
+			return github.Repository{}, nil, nil
+		}
+		return github.Repository{}, nil, err
+	}
+	if repo.Private {
+		return github.Repository{}, nil, nil // GitHub only uses defaults from a public repository
+	}
+	if err := f.pinRef(ctx, &repo, opts); err != nil {
+		return github.Repository{}, nil, err
+	}
+
+	tree, err := f.client.GetTree(ctx, repo)
+	if err != nil {
+		return github.Repository{}, nil, err
+	}
+
+	results, err := f.matchTree(ctx, repo, tree.Tree, opts)
+	if err != nil {
+		return github.Repository{}, nil, err
+	}
+
+	// GitHub uses the first version of each file it finds, looking in
+	// .github/, then the root, then docs/. Templates are kept as a group.
+	chosen := make(map[string]string)
+	for _, r := range results {
+		key, ok := healthFileKey(r.entry.Path)
+		if !ok || strings.HasSuffix(key, "/") {
+			continue
+		}
+		if prev, ok := chosen[key]; !ok || healthPriority(r.entry.Path) < healthPriority(prev) {
+			chosen[key] = r.entry.Path
+		}
+	}
+
+	var health []result
+	for _, r := range results {
+		key, ok := healthFileKey(r.entry.Path)
+		if ok && (strings.HasSuffix(key, "/") || chosen[key] == r.entry.Path) {
+			health = append(health, r)
+		}
+	}
+
+	return repo, health, nil
+}
+
+// healthPriority orders the locations GitHub searches for a health file.
+func healthPriority(p string) int {
+	switch strings.ToLower(path.Dir(p)) {
+	case ".github":
+		return 0
+	case ".":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// inheritedHealthFiles returns the owner's matching default community health
+// files that repo inherits because it doesn't have its own version. tree is
+// the repository's full tree.
+//
+// This is a best-effort approximation of GitHub's behavior: it doesn't
+// account for files provided by other means, such as issue template
+// configuration or a FUNDING.yml in a different location.
+func (f *Finder) inheritedHealthFiles(ctx context.Context, repo github.Repository, tree []github.TreeEntry, opts *Options) []result {
+	if strings.EqualFold(repo.Name, healthRepoName) {
+		return nil
+	}
+
+	source := f.healthSource(ctx, repo.Owner, opts)
+	if len(source.results) == 0 {
+		return nil
+	}
+
+	own := healthKeys(tree)
+	var inherited []result
+	for _, r := range source.results {
+		if key, _ := healthFileKey(r.entry.Path); !own[key] {
+			r.repo = repo
+			r.source = &source.repo
+			inherited = append(inherited, r)
+		}
+	}
+
+	return inherited
+}
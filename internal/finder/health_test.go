@@ -0,0 +1,30 @@
+package finder
+
+import "testing"
+
+func TestHealthFileKey(t *testing.T) {
+	tests := []struct {
+		path    string
+		wantKey string
+		wantOK  bool
+	}{
+		{path: "CONTRIBUTING.md", wantKey: "contributing.md", wantOK: true},
+		{path: "docs/CONTRIBUTING.md", wantKey: "contributing.md", wantOK: true},
+		{path: ".github/contributing.md", wantKey: "contributing.md", wantOK: true},
+		{path: ".github/FUNDING.yml", wantKey: "funding.yml", wantOK: true},
+		{path: ".github/ISSUE_TEMPLATE/bug.yml", wantKey: ".github/issue_template/", wantOK: true},
+		{path: ".github/DISCUSSION_TEMPLATE/idea.yml", wantKey: ".github/discussion_template/", wantOK: true},
+		{path: "src/CONTRIBUTING.md", wantOK: false},
+		{path: "README.md", wantOK: false},
+		{path: "LICENSE", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			key, ok := healthFileKey(tt.path)
+			if ok != tt.wantOK || (ok && key != tt.wantKey) {
+				t.Errorf("healthFileKey(%q) = (%q, %v), want (%q, %v)", tt.path, key, ok, tt.wantKey, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package finder
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Highlighter finds the parts of a matched path that correspond to the
+// literal text of the glob pattern, such as "_test.go" in "*_test.go", so
+// they can be highlighted like ripgrep highlights its matches.
+//
+// doublestar only reports whether a path matches, so the pattern is
+// translated into an equivalent regular expression with a capturing group
+// around each run of literal text. The translation is only used to find
+// spans: doublestar still decides what matches.
+//
+// A regular expression pattern is used as-is, and its whole match is
+// highlighted.
+type Highlighter struct {
+	re       *regexp.Regexp
+	fullPath bool
+	whole    bool // Highlight the whole match rather than its groups
+}
+
+// NewHighlighter returns a Highlighter for patterns, matched against the
+// full path or only the base name as the search does. Highlighting is best
+// effort: it returns nil if any pattern can't be translated.
+func NewHighlighter(patterns []string, fullPath, ignoreCase bool) *Highlighter {
+	exprs := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		pattern, err := expandPOSIXClasses(pattern)
+		if err != nil {
+			return nil
+		}
+
+		expr, ok := globToRegexp(pattern)
+		if !ok {
+			return nil
+		}
+		exprs[i] = expr
+	}
+
+	// Each translation is anchored, so the first pattern that matches
+	// provides the spans.
+	expr := strings.Join(exprs, "|")
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil
+	}
+	return &Highlighter{re: re, fullPath: fullPath}
+}
+
+// NewRegexpHighlighter returns a Highlighter for a regular expression
+// pattern, matched against the full path or only the base name as the search
+// does.
+func NewRegexpHighlighter(re *regexp.Regexp, fullPath bool) *Highlighter {
+	return &Highlighter{re: re, fullPath: fullPath, whole: true}
+}
+
+// spans returns the sorted, non-overlapping [start, end) byte offsets of the
+// literal text matched in p. It returns nil if nothing should be highlighted.
+func (h *Highlighter) spans(p string) [][2]int {
+	offset := 0
+	if !h.fullPath {
+		base := path.Base(p)
+		offset = len(p) - len(base)
+		p = base
+	}
+
+	loc := h.re.FindStringSubmatchIndex(p)
+	if loc == nil {
+		return nil
+	}
+	if h.whole {
+		if loc[0] == loc[1] {
+			return nil
+		}
+		return [][2]int{{offset + loc[0], offset + loc[1]}}
+	}
+
+	var spans [][2]int
+	for i := 2; i < len(loc); i += 2 {
+		start, end := loc[i], loc[i+1]
+		if start < 0 || start == end {
+			continue
+		}
+		if n := len(spans); n > 0 && spans[n-1][1] == offset+start {
+			spans[n-1][1] = offset + end // merge adjacent literals
+			continue
+		}
+		spans = append(spans, [2]int{offset + start, offset + end})
+	}
+	return spans
+}
+
+// globToRegexp translates a doublestar pattern into an anchored regular
+// expression with a capturing group around each run of literal text. It
+// reports false for a malformed pattern.
+func globToRegexp(pattern string) (string, bool) {
+	var buf, literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			buf.WriteString("(" + regexp.QuoteMeta(literal.String()) + ")")
+			literal.Reset()
+		}
+	}
+
+	buf.WriteString("^")
+	depth := 0 // nesting of {...} alternatives
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(pattern) {
+				return "", false
+			}
+			i++
+			literal.WriteByte(pattern[i])
+		case c == '/' && pattern[i+1:] == "**":
+			flush()
+			buf.WriteString("(?:/.*)?") // the directory itself or anything in it
+			i += 2
+		case c == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			flush()
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				buf.WriteString("(?:.*/)?") // zero or more directories
+				i++
+			} else {
+				buf.WriteString(".*")
+			}
+		case c == '*':
+			flush()
+			buf.WriteString("[^/]*")
+		case c == '?':
+			flush()
+			buf.WriteString("[^/]")
+		case c == '[':
+			flush()
+			end, ok := translateClass(pattern[i:], &buf)
+			if !ok {
+				return "", false
+			}
+			i += end
+		case c == '{':
+			flush()
+			depth++
+			buf.WriteString("(?:")
+		case c == ',' && depth > 0:
+			flush()
+			buf.WriteString("|")
+		case c == '}' && depth > 0:
+			flush()
+			depth--
+			buf.WriteString(")")
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	if depth > 0 {
+		return "", false
+	}
+	flush()
+	buf.WriteString("$")
+
+	return buf.String(), true
+}
+
+// translateClass writes the regular expression for the bracket expression at
+// the start of class and returns the index of its closing bracket.
+func translateClass(class string, buf *strings.Builder) (int, bool) {
+	buf.WriteString("[")
+	i := 1
+	if i < len(class) && (class[i] == '!' || class[i] == '^') {
+		buf.WriteString("^")
+		i++
+	}
+	for ; i < len(class); i++ {
+		c := class[i]
+		switch {
+		case c == ']':
+			buf.WriteString("]")
+			return i, true
+		case c == '\\':
+			if i+1 == len(class) {
+				return 0, false
+			}
+			i++
+			writeClassByte(buf, class[i])
+		case c == '-':
+			buf.WriteByte(c) // a range
+		default:
+			writeClassByte(buf, c)
+		}
+	}
+	return 0, false
+}
+
+// writeClassByte writes c as a literal within a regular expression character
+// class, escaping the characters that are special there.
+func writeClassByte(buf *strings.Builder, c byte) {
+	if strings.IndexByte(`\-[]^`, c) >= 0 {
+		buf.WriteByte('\\')
+	}
+	buf.WriteByte(c)
+}
@@ -0,0 +1,165 @@
+package finder
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/jparise/gh-find/internal/github"
+	"github.com/mgutz/ansi"
+)
+
+func TestHighlighterSpans(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		fullPath   bool
+		ignoreCase bool
+		path       string
+		want       [][2]int
+	}{
+		{name: "suffix", pattern: "*_test.go", path: "cmd/root_test.go", want: [][2]int{{8, 16}}},
+		{name: "prefix", pattern: "main.*", path: "main.go", want: [][2]int{{0, 5}}},
+		{name: "literal", pattern: "go.mod", path: "go.mod", want: [][2]int{{0, 6}}},
+		{name: "wildcards only", pattern: "*", path: "README.md", want: nil},
+		{name: "single character", pattern: "file?.txt", path: "file1.txt", want: [][2]int{{0, 4}, {5, 9}}},
+		{name: "class", pattern: "v[0-9].md", path: "v2.md", want: [][2]int{{0, 1}, {2, 5}}},
+		{name: "posix class", pattern: "v[[:digit:]].md", path: "v2.md", want: [][2]int{{0, 1}, {2, 5}}},
+		{name: "alternatives", pattern: "*.{go,md}", path: "README.md", want: [][2]int{{6, 9}}},
+		{name: "escaped wildcard", pattern: `\*.go`, path: "*.go", want: [][2]int{{0, 4}}},
+		{name: "ignore case", pattern: "*.GO", ignoreCase: true, path: "main.go", want: [][2]int{{4, 7}}},
+		{name: "full path", pattern: "cmd/**/*.go", fullPath: true, path: "cmd/gh/main.go", want: [][2]int{{0, 4}, {11, 14}}},
+		{name: "full path root", pattern: "**/main.go", fullPath: true, path: "main.go", want: [][2]int{{0, 7}}},
+		{name: "multibyte", pattern: "*é.txt", path: "café.txt", want: [][2]int{{3, 9}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHighlighter([]string{tt.pattern}, tt.fullPath, tt.ignoreCase)
+			if h == nil {
+				t.Fatalf("NewHighlighter(%q) = nil", tt.pattern)
+			}
+			if got := h.spans(tt.path); !slices.Equal(got, tt.want) {
+				t.Errorf("spans(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHighlighterAgreesWithMatch checks that the translated pattern matches
+// the same names as doublestar, which decides what the search matches.
+func TestHighlighterMultiplePatterns(t *testing.T) {
+	h := NewHighlighter([]string{"*.yml", "*.yaml", "Dockerfile"}, false, false)
+	if h == nil {
+		t.Fatal("NewHighlighter() = nil")
+	}
+
+	tests := map[string][][2]int{
+		"ci/build.yml":     {{8, 12}},
+		"ci/build.yaml":    {{8, 13}},
+		"app/Dockerfile":   {{4, 14}},
+		"app/Dockerfile.x": nil,
+	}
+	for path, want := range tests {
+		if got := h.spans(path); !slices.Equal(got, want) {
+			t.Errorf("spans(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestHighlighterAgreesWithMatch(t *testing.T) {
+	patterns := []string{"*.go", "*_test.go", "file?.txt", "[!a-c]*", "[a\\-c]x", "*.{go,m?}", "**/*.go", "docs/**", "{a,b{c,d}}"}
+	names := []string{"main.go", "main_test.go", "file1.txt", "file10.txt", "abc", "dx", "-x", "bx", "a.md", "pkg/a.go", "docs/x/y", "docs", "a", "bd", "b"}
+
+	for _, pattern := range patterns {
+		h := NewHighlighter([]string{pattern}, true, false)
+		if h == nil {
+			t.Errorf("NewHighlighter(%q) = nil", pattern)
+			continue
+		}
+		for _, name := range names {
+			want := doublestar.MatchUnvalidated(pattern, name)
+			if got := h.re.MatchString(name); got != want {
+				t.Errorf("pattern %q on %q: highlighter matched = %v, doublestar = %v", pattern, name, got, want)
+			}
+		}
+	}
+}
+
+func TestRegexpHighlighterSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		fullPath bool
+		path     string
+		want     [][2]int
+	}{
+		{name: "whole match", expr: `_test\.go$`, path: "cmd/root_test.go", want: [][2]int{{8, 16}}},
+		{name: "groups ignored", expr: `(ma)in`, path: "main.go", want: [][2]int{{0, 4}}},
+		{name: "empty match", expr: "", path: "main.go", want: nil},
+		{name: "no match", expr: "xyz", path: "main.go", want: nil},
+		{name: "full path", expr: "^cmd/.*/", fullPath: true, path: "cmd/gh/main.go", want: [][2]int{{0, 7}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewRegexpHighlighter(regexp.MustCompile(tt.expr), tt.fullPath)
+			if got := h.spans(tt.path); !slices.Equal(got, tt.want) {
+				t.Errorf("spans(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewHighlighterInvalid(t *testing.T) {
+	for _, pattern := range []string{"[abc", "{a,b", `abc\`, "[[:bogus:]]"} {
+		if h := NewHighlighter([]string{pattern}, false, false); h != nil {
+			t.Errorf("NewHighlighter(%q) = %v, want nil", pattern, h)
+		}
+	}
+}
+
+func TestHighlightOutput(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli"}
+	r := result{repo: repo, entry: github.TreeEntry{Path: "cmd/root_test.go"}}
+	h := NewHighlighter([]string{"*_test.go"}, false, false)
+
+	white := ansi.ColorFunc("white")
+	red := ansi.ColorFunc("red+b")
+
+	tests := []struct {
+		name string
+		opts OutputOptions
+		want string
+	}{
+		{
+			name: "colorized",
+			opts: OutputOptions{Colorize: true, Highlight: h},
+			want: ansi.ColorFunc("cyan")("cli") + "/" + ansi.ColorFunc("green+b")("cli") + ":" +
+				white("cmd/root") + red("_test.go") + "\n",
+		},
+		{
+			name: "not colorized",
+			opts: OutputOptions{Highlight: h},
+			want: "cli/cli:cmd/root_test.go\n",
+		},
+		{
+			name: "replaced path",
+			opts: OutputOptions{Highlight: h, PathReplacements: []PathReplacement{mustParsePathReplacement(t, "^cmd/=")}},
+			want: "cli/cli:root_test.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, tt.opts)
+			output.write(r)
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("write() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
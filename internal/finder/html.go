@@ -0,0 +1,91 @@
+package finder
+
+import (
+	"fmt"
+	"html/template"
+	"time"
+)
+
+// htmlRow is a match in an HTML report.
+type htmlRow struct {
+	Record
+	RepoURL  string
+	Modified string // RFC 3339 date of the last commit, if known
+}
+
+// htmlTemplate is a standalone report: it has no external resources, and a
+// small script sorts the table when a column header is clicked.
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>gh find report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; padding: 0.3em 0.8em; border-bottom: 1px solid #d1d9e0; }
+th { cursor: pointer; user-select: none; background: #f6f8fa; }
+th[aria-sort=ascending]::after { content: " \25B2"; }
+th[aria-sort=descending]::after { content: " \25BC"; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+</style>
+</head>
+<body>
+<h1>gh find report</h1>
+<p>{{len .}} {{if eq (len .) 1}}match{{else}}matches{{end}}</p>
+<table>
+<thead>
+<tr><th>Repository</th><th>Ref</th><th>Path</th><th data-type="number">Size</th><th>Type</th><th>Last changed</th></tr>
+</thead>
+<tbody>
+{{- range .}}
+<tr><td><a href="{{.RepoURL}}">{{.Owner}}/{{.Repo}}</a></td><td>{{.Ref}}</td><td><a href="{{.URL}}">{{.Path}}</a>{{with .InheritedFrom}} (inherited from {{.}}){{end}}</td><td class="num" data-value="{{.Size}}">{{or .SizeHuman .Size}}</td><td>{{.Type}}</td><td>{{.Modified}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+document.querySelectorAll("th").forEach(function (th, column) {
+  th.addEventListener("click", function () {
+    var tbody = th.closest("table").tBodies[0];
+    var ascending = th.getAttribute("aria-sort") !== "ascending";
+    var numeric = th.dataset.type === "number";
+    var value = function (row) {
+      var cell = row.cells[column];
+      return numeric ? Number(cell.dataset.value) : cell.textContent;
+    };
+    var rows = Array.from(tbody.rows).sort(function (a, b) {
+      var x = value(a), y = value(b);
+      var order = numeric ? x - y : x.localeCompare(y);
+      return ascending ? order : -order;
+    });
+    th.parentNode.querySelectorAll("th").forEach(function (other) { other.removeAttribute("aria-sort"); });
+    th.setAttribute("aria-sort", ascending ? "ascending" : "descending");
+    rows.forEach(function (row) { tbody.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// htmlRow returns the report row for a result. The caller must hold o.mu.
+func (o *Output) htmlRow(r result) htmlRow {
+	row := htmlRow{Record: o.record(r), RepoURL: r.repo.URL}
+	if r.commit != nil {
+		row.Modified = r.commit.CommittedDate.UTC().Format(time.RFC3339)
+	}
+	return row
+}
+
+// flushHTML writes the buffered matches as an HTML report. The caller must
+// hold o.mu.
+func (o *Output) flushHTML() error {
+	rows := o.htmlRows
+	o.htmlRows = nil
+	if err := htmlTemplate.Execute(o.stdout, rows); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package finder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestFlushHTML(t *testing.T) {
+	repo := github.Repository{
+		Owner:    "cli",
+		Name:     "cli",
+		FullName: "cli/cli",
+		Ref:      "trunk",
+		URL:      "https://github.com/cli/cli",
+	}
+	date := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Format: FormatHTML})
+
+	output.writeAll([]result{
+		{repo: repo, entry: github.TreeEntry{Path: "main.go", Mode: "100644", Size: 42}, commit: &github.FileCommitInfo{CommittedDate: date}},
+		{repo: repo, entry: github.TreeEntry{Path: "<script>.go", Mode: "100644", Size: 7}},
+	})
+	if stdout.Len() != 0 {
+		t.Errorf("writeAll() wrote before Flush(): %q", stdout.String())
+	}
+	if err := output.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	got := stdout.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<p>2 matches</p>",
+		`<a href="https://github.com/cli/cli">cli/cli</a>`,
+		`<a href="https://github.com/cli/cli/blob/trunk/main.go">main.go</a>`,
+		`<td class="num" data-value="42">42</td>`,
+		"<td>2024-01-15T10:30:00Z</td>",
+		"&lt;script&gt;.go",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Flush() output missing %q", want)
+		}
+	}
+	if strings.Contains(got, "<script>.go") {
+		t.Errorf("Flush() output contains an unescaped path")
+	}
+}
+
+func TestFlushHTMLEmpty(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Format: FormatHTML})
+
+	if err := output.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+	if got := stdout.String(); !strings.Contains(got, "<p>0 matches</p>") || !strings.Contains(got, "</html>") {
+		t.Errorf("Flush() output = %q, want an empty report", got)
+	}
+}
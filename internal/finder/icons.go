@@ -0,0 +1,79 @@
+package finder
+
+import (
+	"path"
+	"strings"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// Nerd Font icons (https://www.nerdfonts.com/) for file types, like eza and
+// lsd show. Files are matched by name, then by extension, then by type.
+const (
+	iconDirectory  = "\uf07b" // nf-fa-folder
+	iconSymlink    = "\uf0c1" // nf-fa-link
+	iconSubmodule  = "\uf1d3" // nf-fa-git
+	iconExecutable = "\uf489" // nf-oct-terminal
+	iconFile       = "\uf15b" // nf-fa-file
+)
+
+// nameIcons are icons for well-known file names.
+var nameIcons = map[string]string{
+	".gitattributes": "\uf1d3", // nf-fa-git
+	".gitignore":     "\uf1d3", // nf-fa-git
+	".gitmodules":    "\uf1d3", // nf-fa-git
+	"Dockerfile":     "\uf308", // nf-linux-docker
+	"LICENSE":        "\uf02d", // nf-fa-book
+	"go.mod":         "\ue627", // nf-seti-go
+	"go.sum":         "\ue627", // nf-seti-go
+}
+
+// extensionIcons are icons for lowercase file extensions.
+var extensionIcons = map[string]string{
+	".c":    "\ue61e", // nf-custom-c
+	".cpp":  "\ue61d", // nf-custom-cpp
+	".css":  "\ue749", // nf-dev-css3
+	".gif":  "\uf1c5", // nf-fa-file_image_o
+	".go":   "\ue627", // nf-seti-go
+	".h":    "\uf0fd", // nf-fa-h_square
+	".html": "\uf13b", // nf-fa-html5
+	".java": "\ue738", // nf-dev-java
+	".jpeg": "\uf1c5", // nf-fa-file_image_o
+	".jpg":  "\uf1c5", // nf-fa-file_image_o
+	".js":   "\ue74e", // nf-dev-javascript
+	".json": "\ue60b", // nf-seti-json
+	".lock": "\uf023", // nf-fa-lock
+	".md":   "\uf48a", // nf-oct-markdown
+	".png":  "\uf1c5", // nf-fa-file_image_o
+	".py":   "\ue606", // nf-seti-python
+	".rb":   "\ue739", // nf-dev-ruby
+	".rs":   "\ue7a8", // nf-dev-rust
+	".sh":   "\uf489", // nf-oct-terminal
+	".svg":  "\uf1c5", // nf-fa-file_image_o
+	".ts":   "\ue628", // nf-seti-typescript
+	".txt":  "\uf15c", // nf-fa-file_text
+}
+
+// fileIcon returns the icon for a tree entry.
+func fileIcon(entry github.TreeEntry) string {
+	switch github.ParseFileType(entry.Mode) {
+	case github.FileTypeDirectory:
+		return iconDirectory
+	case github.FileTypeSymlink:
+		return iconSymlink
+	case github.FileTypeSubmodule:
+		return iconSubmodule
+	}
+
+	name := path.Base(entry.Path)
+	if icon, ok := nameIcons[name]; ok {
+		return icon
+	}
+	if icon, ok := extensionIcons[strings.ToLower(path.Ext(name))]; ok {
+		return icon
+	}
+	if github.ParseFileType(entry.Mode) == github.FileTypeExecutable {
+		return iconExecutable
+	}
+	return iconFile
+}
@@ -0,0 +1,62 @@
+package finder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestFileIcon(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry github.TreeEntry
+		want  string
+	}{
+		{name: "directory", entry: github.TreeEntry{Path: "docs", Mode: "040000"}, want: iconDirectory},
+		{name: "symlink", entry: github.TreeEntry{Path: "main.go", Mode: "120000"}, want: iconSymlink},
+		{name: "submodule", entry: github.TreeEntry{Path: "vendor/lib", Mode: "160000"}, want: iconSubmodule},
+		{name: "file name", entry: github.TreeEntry{Path: "build/Dockerfile", Mode: "100644"}, want: nameIcons["Dockerfile"]},
+		{name: "extension", entry: github.TreeEntry{Path: "cmd/main.go", Mode: "100644"}, want: extensionIcons[".go"]},
+		{name: "uppercase extension", entry: github.TreeEntry{Path: "README.MD", Mode: "100644"}, want: extensionIcons[".md"]},
+		{name: "executable", entry: github.TreeEntry{Path: "script/bootstrap", Mode: "100755"}, want: iconExecutable},
+		{name: "executable with extension", entry: github.TreeEntry{Path: "run.py", Mode: "100755"}, want: extensionIcons[".py"]},
+		{name: "other file", entry: github.TreeEntry{Path: "data.bin", Mode: "100644"}, want: iconFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileIcon(tt.entry); got != tt.want {
+				t.Errorf("fileIcon(%q) = %q, want %q", tt.entry.Path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIconsOutput(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", FullName: "cli/cli"}
+	r := result{repo: repo, entry: github.TreeEntry{Path: "cmd/main.go", Mode: "100644"}}
+	goIcon := extensionIcons[".go"]
+
+	tests := []struct {
+		name string
+		opts OutputOptions
+		want string
+	}{
+		{name: "text", opts: OutputOptions{Icons: true}, want: goIcon + " cli/cli:cmd/main.go\n"},
+		{name: "grouped", opts: OutputOptions{Icons: true, Group: true}, want: "cli/cli\n  " + goIcon + " cmd/main.go\n"},
+		{name: "tree", opts: OutputOptions{Icons: true, Tree: true}, want: "cli/cli\n└── " + goIcon + " cmd/main.go\n"},
+		{name: "disabled", want: "cli/cli:cmd/main.go\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, tt.opts)
+			output.writeAll([]result{r})
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("writeAll() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package finder
+
+import (
+	"path"
+	"strings"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// defaultLSColors are the file type colors used for keys LS_COLORS doesn't
+// set. They match the defaults of GNU dircolors.
+var defaultLSColors = map[string]string{
+	"di": "01;34",
+	"ln": "01;36",
+	"ex": "01;32",
+}
+
+// fileColors colors file names by type and extension, like ls(1).
+type fileColors struct {
+	types      map[string]string // LS_COLORS type keys, such as "di"
+	extensions map[string]string // name suffixes, such as ".go"
+}
+
+// parseLSColors parses the value of LS_COLORS, a colon-separated list of
+// key=SGR entries such as "di=01;34:*.go=33", where "*" entries match the
+// end of a file name. Type keys other than fi, di, ln, and ex are ignored
+// since they don't apply to git trees, as are entries whose value isn't an
+// SGR sequence.
+func parseLSColors(s string) *fileColors {
+	c := &fileColors{
+		types:      make(map[string]string, len(defaultLSColors)),
+		extensions: make(map[string]string),
+	}
+	for key, sgr := range defaultLSColors {
+		c.types[key] = sgr
+	}
+
+	for entry := range strings.SplitSeq(s, ":") {
+		key, sgr, ok := strings.Cut(entry, "=")
+		if !ok || !isSGR(sgr) {
+			continue
+		}
+		if ext, ok := strings.CutPrefix(key, "*"); ok {
+			c.extensions[ext] = sgr
+			continue
+		}
+		switch key {
+		case "fi", "di", "ln", "ex":
+			c.types[key] = sgr
+		}
+	}
+
+	return c
+}
+
+// isSGR reports whether s is a non-empty list of SGR parameters, such as
+// "01;34" or "38;5;208".
+func isSGR(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789;") == ""
+}
+
+// style returns the SGR parameters for entry's name, or "" to leave it
+// uncolored. Submodules are colored like directories, and only regular
+// files are colored by extension.
+func (c *fileColors) style(entry github.TreeEntry) string {
+	switch github.ParseFileType(entry.Mode) {
+	case github.FileTypeDirectory, github.FileTypeSubmodule:
+		return c.types["di"]
+	case github.FileTypeSymlink:
+		return c.types["ln"]
+	case github.FileTypeExecutable:
+		return c.types["ex"]
+	}
+
+	name := path.Base(entry.Path)
+	for i := range len(name) {
+		// The longest matching suffix wins, so ".tar.gz" beats ".gz".
+		if sgr, ok := c.extensions[name[i:]]; ok {
+			return sgr
+		}
+	}
+	return c.types["fi"]
+}
+
+// colorFunc returns a function that wraps text in the SGR sequence, or nil
+// if sgr is empty.
+func colorFunc(sgr string) func(string) string {
+	if sgr == "" {
+		return nil
+	}
+	return func(s string) string {
+		if s == "" {
+			return s
+		}
+		return "\x1b[" + sgr + "m" + s + "\x1b[0m"
+	}
+}
@@ -0,0 +1,81 @@
+package finder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jparise/gh-find/internal/github"
+	"github.com/mgutz/ansi"
+)
+
+func TestFileColorsStyle(t *testing.T) {
+	tests := []struct {
+		name     string
+		lsColors string
+		entry    github.TreeEntry
+		want     string
+	}{
+		{name: "default directory", entry: github.TreeEntry{Path: "docs", Mode: "040000"}, want: "01;34"},
+		{name: "default symlink", entry: github.TreeEntry{Path: "link", Mode: "120000"}, want: "01;36"},
+		{name: "default executable", entry: github.TreeEntry{Path: "run.sh", Mode: "100755"}, want: "01;32"},
+		{name: "submodule like directory", entry: github.TreeEntry{Path: "vendor/lib", Mode: "160000"}, want: "01;34"},
+		{name: "default file", entry: github.TreeEntry{Path: "main.go", Mode: "100644"}, want: ""},
+		{name: "overridden type", lsColors: "di=00;33", entry: github.TreeEntry{Path: "docs", Mode: "040000"}, want: "00;33"},
+		{name: "regular file", lsColors: "fi=37", entry: github.TreeEntry{Path: "main.go", Mode: "100644"}, want: "37"},
+		{name: "extension", lsColors: "fi=37:*.go=38;5;81", entry: github.TreeEntry{Path: "cmd/main.go", Mode: "100644"}, want: "38;5;81"},
+		{name: "longest suffix", lsColors: "*.gz=31:*.tar.gz=01;31", entry: github.TreeEntry{Path: "a.tar.gz", Mode: "100644"}, want: "01;31"},
+		{name: "name suffix", lsColors: "*README=04", entry: github.TreeEntry{Path: "README", Mode: "100644"}, want: "04"},
+		{name: "executable ignores extension", lsColors: "*.sh=33", entry: github.TreeEntry{Path: "run.sh", Mode: "100755"}, want: "01;32"},
+		{name: "invalid value ignored", lsColors: "ex=bold", entry: github.TreeEntry{Path: "run.sh", Mode: "100755"}, want: "01;32"},
+		{name: "unsupported key ignored", lsColors: "so=01;35:rs=0", entry: github.TreeEntry{Path: "main.go", Mode: "100644"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLSColors(tt.lsColors).style(tt.entry); got != tt.want {
+				t.Errorf("style(%q) = %q, want %q", tt.entry.Path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileTypeColorOutput(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli"}
+	prefix := ansi.ColorFunc("cyan")("cli") + "/" + ansi.ColorFunc("green+b")("cli") + ":"
+	white := ansi.ColorFunc("white")
+
+	tests := []struct {
+		name     string
+		lsColors string
+		entry    github.TreeEntry
+		want     string
+	}{
+		{
+			name:  "executable",
+			entry: github.TreeEntry{Path: "script/build.sh", Mode: "100755"},
+			want:  prefix + white("script/") + "\x1b[01;32mbuild.sh\x1b[0m\n",
+		},
+		{
+			name:  "regular file",
+			entry: github.TreeEntry{Path: "script/README", Mode: "100644"},
+			want:  prefix + white("script/README") + "\n",
+		},
+		{
+			name:     "LS_COLORS extension",
+			lsColors: "*.go=33",
+			entry:    github.TreeEntry{Path: "main.go", Mode: "100644"},
+			want:     prefix + "\x1b[33mmain.go\x1b[0m\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Colorize: true, LSColors: tt.lsColors})
+			output.write(result{repo: repo, entry: tt.entry})
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("write() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package finder
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/bmatcuk/doublestar/v4"
 	"github.com/jparise/gh-find/internal/github"
+	"github.com/jparise/gh-find/internal/gitignore"
 )
 
 // RepoSpec represents a parsed repository specification.
@@ -11,22 +15,136 @@ type RepoSpec struct {
 	Owner string // Repository owner (user or organization)
 	Repo  string // Repository name (empty means expand all repos for owner)
 	Ref   string // Branch/tag/SHA (empty means use default branch from API)
+	Team  string // Slug of an Owner team whose repos to expand instead of all of Owner's
+	Query string // Repository search query to expand instead of an owner
+	Path  string // Directory to search instead of each repository's whole tree
+
+	// Starred expands the repositories Owner has starred instead, or the
+	// authenticated user has if Owner is empty.
+	Starred bool
+
+	// Me expands the authenticated user's repositories, including private
+	// ones, instead of an owner's.
+	Me bool
+}
+
+// IsGlob reports whether the spec's repository name is a glob pattern, such
+// as "terraform-*", which expands against the owner's repositories.
+func (s RepoSpec) IsGlob() bool {
+	// GitHub repository names can't contain any of these characters.
+	return strings.ContainsAny(s.Repo, `*?[{\`)
+}
+
+// Matches reports whether repo is one of the repositories the spec names:
+// any of the owner's repositories if it doesn't name one, or those whose
+// names match its glob. Like GitHub names, matching ignores case.
+func (s RepoSpec) Matches(repo github.Repository) bool {
+	if s.Repo == "" {
+		return true
+	}
+	return doublestar.MatchUnvalidated(strings.ToLower(s.Repo), strings.ToLower(repo.Name))
+}
+
+// String returns the spec in its "owner", "owner/repo", "owner/repo/path",
+// "owner/repo@ref", "owner/@team", "@me", or "@starred" form, "@starred:user"
+// for a user's stars, or "search:query" for a search query.
+func (s RepoSpec) String() string {
+	if s.Query != "" {
+		return "search:" + s.Query
+	}
+	if s.Team != "" {
+		return s.Owner + "/@" + s.Team
+	}
+	if s.Me {
+		return "@me"
+	}
+	if s.Starred {
+		if s.Owner == "" {
+			return "@starred"
+		}
+		return "@starred:" + s.Owner
+	}
+
+	str := s.Owner
+	if s.Repo != "" {
+		str += "/" + s.Repo
+	}
+	if s.Path != "" {
+		str += "/" + s.Path
+	}
+	if s.Ref != "" {
+		str += "@" + s.Ref
+	}
+	return str
 }
 
 // Options contains all search parameters.
 type Options struct {
-	Pattern       string
-	RepoSpecs     []RepoSpec
-	RepoTypes     github.RepoTypes  // Repository types to include
-	FileTypes     []github.FileType // File types to include (OR matching)
-	IgnoreCase    bool
-	FullPath      bool
-	Extensions    []string
-	Excludes      []string   // Exclude patterns
-	MinSize       int64      // Minimum file size in bytes (0 = no minimum)
-	MaxSize       int64      // Maximum file size in bytes (0 = no maximum)
-	ChangedAfter  *time.Time // Files changed after this time (nil = no filter)
-	ChangedBefore *time.Time // Files changed before this time (nil = no filter)
-	ClientOpts    github.ClientOptions
-	Jobs          int // Maximum concurrent API requests
+	Patterns        []string           // Glob patterns, any of which an entry may match
+	Regexp          *regexp.Regexp     // Match paths against this instead of Patterns, if set
+	ExcludeRegexps  []*regexp.Regexp   // Exclude paths matching these instead of Excludes, if Regexp is set
+	FixedStrings    bool               // Match Patterns and Excludes as literal substrings rather than globs
+	Ignore          *gitignore.Matcher // Exclude paths it ignores, like a .gitignore (nil = no filter)
+	RepoSpecs       []RepoSpec
+	Affiliation     []string          // Relationships to the authenticated user of repositories @me expands to
+	ExcludeRepos    []string          // Glob patterns of expanded repositories to skip
+	RepoTopics      []string          // Topics expanded repositories must all have
+	RepoLanguages   []string          // Primary languages of expanded repositories to include (empty = all)
+	RepoMinStars    int               // Minimum star count of expanded repositories (0 = no minimum)
+	RepoPushedSince *time.Time        // Skip expanded repositories not pushed to since this time (nil = no limit)
+	RepoVisibility  github.Visibility // Visibility of expanded repositories to include (empty = all)
+	RepoMaxSize     int64             // Maximum size of expanded repositories in bytes (0 = no limit)
+	RepoSort        RepoSort          // Order to search each owner's repositories in
+	SkipTemplates   bool              // Skip expanded template repositories
+	Ref             string            // Ref to search instead of each repository's default branch (empty = default branch)
+	Branches        string            // Glob of branches to search instead of the default branch (empty = default branch)
+	Tags            string            // Glob of tags to search instead of the default branch (empty = default branch)
+	AsOf            *time.Time        // Search each repository as of the last commit before this time (nil = latest)
+	RepoTypes       github.RepoTypes  // Repository types to include
+	FileTypes       []github.FileType // File types to include (OR matching)
+	ContentTypes    []ContentType     // Content classes to include, OR matched with FileTypes
+	SniffContent    bool              // Classify files ContentTypes can't by extension by fetching their first few KB
+	Modes           []string          // Exact git modes to include, such as "100755" (OR matching)
+	BlobSHAs        []string          // Only include files whose blob SHA starts with one of these (empty = no filter)
+	SymlinkTargets  []string          // Only include symlinks whose target matches one of these globs (empty = no filter)
+	IgnoreCase      bool
+	FullPath        bool
+	Extensions      []string
+	Paths           []string   // Only match entries beneath these directories (empty = whole tree)
+	Excludes        []string   // Exclude patterns
+	Prune           []string   // Glob patterns of directories to skip along with everything beneath them
+	MinSize         int64      // Minimum file size in bytes (0 = no minimum)
+	MaxSize         int64      // Maximum file size in bytes (0 = no maximum)
+	Empty           bool       // Only match zero-byte files
+	CaseCollisions  bool       // Only match paths that differ only by case from another path in the repository
+	WindowsUnsafe   bool       // Only match paths that can't be checked out on Windows
+	MinDepth        int        // Minimum path depth, where top-level entries are 1 (0 = no minimum)
+	MaxDepth        int        // Maximum path depth, where top-level entries are 1 (0 = no maximum)
+	ChangedAfter    *time.Time // Files changed after this time (nil = no filter)
+	ChangedBefore   *time.Time // Files changed before this time (nil = no filter)
+	Authors         []string   // Globs of the login or name of the author of each file's last commit (empty = no filter)
+	CreatedAfter    *time.Time // Files first committed after this time (nil = no filter)
+	CreatedBefore   *time.Time // Files first committed before this time (nil = no filter)
+	MinCommits      int        // Minimum number of commits in each file's history (0 = no minimum)
+	MaxCommits      int        // Maximum number of commits in each file's history (0 = no maximum)
+	Commits         []string   // Only include files changed by these commits (empty = no filter)
+	NoGenerated     bool       // Exclude files marked linguist-generated or linguist-vendored
+	LFS             bool       // Only include files tracked by Git LFS
+	NoLFS           bool       // Exclude files tracked by Git LFS
+	CommunityHealth bool       // Include community health files inherited from the owner's .github repository
+	ShowCommit      bool       // Fetch the last commit to change each matched file
+	CommitDates     bool       // Fetch each matched file's last commit without showing it
+	Preview         int        // Fetch the first N bytes of each matched file (0 = disabled)
+	Permalink       bool       // Link to files at the commit each ref resolves to
+	MaxPerRepo      int        // Keep at most N matches from each repository (0 = no limit)
+	ClientOpts      github.ClientOptions
+	Jobs            int    // Maximum concurrent API requests
+	NoDedup         bool   // Search every expanded repository, even if it was already seen
+	MaxRepos        int    // Maximum number of repositories to search, counting each once across refs (0 = no limit)
+	ListRepos       bool   // Write the repositories that would be searched instead of searching them
+	Ordered         bool   // Write each repository's results in input order, not completion order
+	IgnoreMissing   bool   // Silently skip repositories and owners that don't exist
+	PostTo          string // URL to POST batches of JSON matches to (empty = disabled)
+	Stats           bool   // Print a summary of the run to stderr
+	Quiet           bool   // Suppress hints about the search options
 }
@@ -1,20 +1,155 @@
 package finder
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/cli/go-gh/v2/pkg/jq"
 	"github.com/jparise/gh-find/internal/github"
 	"github.com/mgutz/ansi"
 )
 
+// Format selects how matches are written to stdout.
+type Format string
+
+const (
+	// FormatText writes one colorized owner/repo:path line per match.
+	FormatText Format = "text"
+	// FormatJSON writes all matches as a single JSON array once the search completes.
+	FormatJSON Format = "json"
+	// FormatNDJSON writes each match as a JSON object on its own line as soon
+	// as it is found.
+	FormatNDJSON Format = "ndjson"
+	// FormatCSV writes a header row followed by one comma-separated row per match.
+	FormatCSV Format = "csv"
+	// FormatTSV writes a header row followed by one tab-separated row per match.
+	FormatTSV Format = "tsv"
+	// FormatHTML writes a standalone HTML report once the search completes.
+	FormatHTML Format = "html"
+	// FormatURLs writes the raw content URL of each file as it is found.
+	FormatURLs Format = "urls"
+	// FormatTotal writes only the total number of matches once the search completes.
+	FormatTotal Format = "total"
+	// FormatQuiet writes nothing; matches are only counted.
+	FormatQuiet Format = "quiet"
+	// FormatCount writes the number of matches in each repository as it is
+	// searched, followed by the total once the search completes.
+	FormatCount Format = "count"
+)
+
+// OutputOptions configures how Output renders matches.
+type OutputOptions struct {
+	Format     Format // Output format (empty = FormatText)
+	Colorize   bool   // Colorize text output
+	Hyperlinks bool   // Wrap text output in OSC 8 hyperlinks
+	JSONIndent bool   // Pretty-print JSON output
+	JQ         string // jq expression applied to JSON output
+	WithID     bool   // Include a stable ID in JSON records
+	ShowCommit bool   // Include the URL of the last commit to change each file
+	ShowMtime  bool   // Include the date of the last commit to change each file
+	ShowAuthor bool   // Include the author of the last commit to change each file
+	Sort       SortKey
+	Reverse    bool // Reverse the sort order
+	MaxResults int  // Maximum number of matches to write (0 = no limit)
+
+	// Group writes text output as a heading per repository followed by its
+	// indented matches. Each group is streamed while holding the output
+	// lock unless BufferGroups is set, in which case it is formatted first
+	// and written at once.
+	Group        bool
+	BufferGroups bool
+
+	// Tree writes text output as a tree of each repository's matches below
+	// its heading, like tree(1).
+	Tree bool
+
+	// Duplicates writes only the matches whose content is identical to
+	// another match's, grouped by blob SHA, once the search completes.
+	Duplicates bool
+	// SameName writes only the matches whose basename is shared with
+	// another match, grouped by name, once the search completes.
+	SameName bool
+
+	// LSColors is the value of LS_COLORS, used to color file names in
+	// colorized text output by type and extension. Directories, symlinks,
+	// and executables have default colors if it doesn't set them.
+	LSColors string
+
+	// Highlight marks the parts of each path in colorized text output that
+	// matched the pattern's literal text.
+	Highlight *Highlighter
+
+	// Printf replaces the text output format of each match.
+	Printf *Printf
+	// HumanReadable writes sizes in long output, printf output, and JSON
+	// records with binary unit suffixes, such as 1.4M.
+	HumanReadable bool
+	// NoRepoPrefix omits the owner/repo: prefix from each line of text
+	// output, leaving the bare path.
+	NoRepoPrefix bool
+	// Icons prefixes each match in text output with a Nerd Font icon for
+	// its file type.
+	Icons bool
+	// Long prefixes each line of text output with the file's mode, type,
+	// size, and short blob SHA, like ls -l.
+	Long bool
+	// Print0 terminates each line of text output with a NUL byte instead of
+	// a newline.
+	Print0 bool
+
+	// PathReplacements rewrite displayed paths, in order. URLs and IDs
+	// always use the original path.
+	PathReplacements []PathReplacement
+}
+
 // Output handles all output formatting with optional color and hyperlink support.
 type Output struct {
-	mu         sync.Mutex
-	stdout     io.Writer
-	stderr     io.Writer
-	hyperlinks bool
+	mu           sync.Mutex
+	stdout       io.Writer
+	stderr       io.Writer
+	format       Format
+	hyperlinks   bool
+	jsonIndent   bool
+	jq           string
+	withID       bool
+	showCommit   bool
+	showMtime    bool
+	showAuthor   bool
+	replace      []PathReplacement
+	highlight    *Highlighter
+	colors       *fileColors // nil unless colorized
+	printf       *Printf
+	terminator   string // written after each line of text output
+	long         bool
+	icons        bool
+	noRepoPrefix bool
+	human        bool
+	sortKey      SortKey
+	reverse      bool
+	maxResults   int
+	group        bool
+	bufferGroups bool
+	tree         bool
+	duplicates   duplicateKey
+	groups       int            // groups written
+	pending      []result       // buffered matches when sorting or finding duplicates
+	preview      func([]result) // adds previews to buffered matches before they're written
+	records      []Record       // buffered matches for FormatJSON
+	table        *csv.Writer    // FormatCSV and FormatTSV writer, once the header is written
+	htmlRows     []htmlRow      // buffered matches for FormatHTML
+	total        int            // match count for FormatTotal and FormatCount
+	matched      int            // matches received
+	written      int            // matches written (at most maxResults)
+	stopped      bool           // the search stopped early because of done
 
 	cyan   func(string) string
 	green  func(string) string
@@ -23,24 +158,73 @@ type Output struct {
 	red    func(string) string
 }
 
-// NewOutput creates a new Output with optional color and hyperlink support.
-func NewOutput(stdout, stderr io.Writer, colorize, hyperlinks bool) *Output {
+// NewOutput creates a new Output with the given options.
+func NewOutput(stdout, stderr io.Writer, opts OutputOptions) *Output {
 	color := func(name string) func(string) string {
-		if colorize {
+		if opts.Colorize {
 			return ansi.ColorFunc(name)
 		}
 		return ansi.ColorFunc("")
 	}
 
+	format := opts.Format
+	if format == "" {
+		format = FormatText
+	}
+
+	terminator := "\n"
+	if opts.Print0 {
+		terminator = "\x00"
+	}
+
+	highlight := opts.Highlight
+	var colors *fileColors
+	if opts.Colorize {
+		colors = parseLSColors(opts.LSColors)
+	} else {
+		highlight = nil
+	}
+
+	duplicates := duplicateNone
+	switch {
+	case opts.Duplicates:
+		duplicates = duplicateContent
+	case opts.SameName:
+		duplicates = duplicateName
+	}
+
 	return &Output{
-		stdout:     stdout,
-		stderr:     stderr,
-		hyperlinks: hyperlinks,
-		cyan:       color("cyan"),
-		green:      color("green+b"),
-		white:      color("white"),
-		yellow:     color("yellow"),
-		red:        color("red+b"),
+		stdout:       stdout,
+		stderr:       stderr,
+		format:       format,
+		hyperlinks:   opts.Hyperlinks,
+		jsonIndent:   opts.JSONIndent,
+		jq:           opts.JQ,
+		withID:       opts.WithID,
+		showCommit:   opts.ShowCommit,
+		showMtime:    opts.ShowMtime,
+		showAuthor:   opts.ShowAuthor,
+		replace:      opts.PathReplacements,
+		highlight:    highlight,
+		colors:       colors,
+		printf:       opts.Printf,
+		terminator:   terminator,
+		long:         opts.Long,
+		icons:        opts.Icons,
+		noRepoPrefix: opts.NoRepoPrefix,
+		human:        opts.HumanReadable,
+		sortKey:      opts.Sort,
+		reverse:      opts.Reverse,
+		maxResults:   opts.MaxResults,
+		group:        opts.Group,
+		bufferGroups: opts.BufferGroups,
+		tree:         opts.Tree,
+		duplicates:   duplicates,
+		cyan:         color("cyan"),
+		green:        color("green+b"),
+		white:        color("white"),
+		yellow:       color("yellow"),
+		red:          color("red+b"),
 	}
 }
 
@@ -48,26 +232,551 @@ func makeHyperlink(url, text string) string {
 	return fmt.Sprintf("\033]8;;%s\033\\%s\033]8;;\033\\", url, text)
 }
 
-// Match writes a file match in the format: owner/repo:path or owner/repo@ref:path.
-func (o *Output) Match(repo github.Repository, path string) {
+// result is a matched file along with any details gathered about it.
+type result struct {
+	repo    github.Repository
+	entry   github.TreeEntry
+	source  *github.Repository     // .github repository a health file was inherited from, or nil
+	commit  *github.FileCommitInfo // last commit to change the file, or nil if not fetched
+	preview string                 // leading content of the file, if fetched
+}
+
+// blobRepo returns the repository that actually contains the file.
+func (r result) blobRepo() github.Repository {
+	if r.source != nil {
+		return *r.source
+	}
+	return r.repo
+}
+
+// Match writes a file match. Text output uses the format owner/repo:path or
+// owner/repo@ref:path. JSON output is buffered until Flush is called, while
+// NDJSON output is written immediately.
+func (o *Output) Match(repo github.Repository, entry github.TreeEntry) {
+	o.write(result{repo: repo, entry: entry})
+}
+
+// write writes a result, or buffers it until Flush if results are sorted or
+// grouped into duplicates.
+func (o *Output) write(r result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.matched++
+	if o.sortKey != SortNone || o.duplicates != duplicateNone {
+		o.pending = append(o.pending, r)
+		return
+	}
+	o.render(r)
+}
+
+// writeAll writes a repository's results. Grouped text output writes them
+// under a single heading, without interleaving other repositories' results.
+// Sorted groups are sorted within each repository, so groups still stream
+// rather than waiting for the whole search.
+func (o *Output) writeAll(results []result) {
+	if !o.grouped() {
+		for _, r := range results {
+			o.write(r)
+		}
+		return
+	}
+
+	if len(results) == 0 {
+		return
+	}
+
+	if o.sortKey != SortNone {
+		results = slices.Clone(results)
+		sortResults(results, o.sortKey, o.reverse)
+	}
+
+	switch {
+	case o.tree:
+		o.writeTreeGroup(results)
+	case o.bufferGroups:
+		o.writeBufferedGroup(results)
+	default:
+		o.writeStreamingGroup(results)
+	}
+}
+
+// writeRepoCount writes the number of matches in a repository, like grep -c,
+// if the output is FormatCount. Repositories without matches are included.
+func (o *Output) writeRepoCount(repo github.Repository, n int) {
+	if o.format != FormatCount {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fmt.Fprintf(o.stdout, "%s:%d\n", o.formatRepo(repo), n)
+}
+
+// writeRepo writes a repository that would be searched, with its ref and
+// size, for --list-repos.
+func (o *Output) writeRepo(repo github.Repository) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fmt.Fprintf(o.stdout, "%s/%s\t%s\t%s\n",
+		o.cyan(repo.Owner), o.green(repo.Name), repo.Ref, o.formatSize(int64(repo.Size)*1024))
+}
+
+// grouped reports whether matches are written in a group per repository.
+func (o *Output) grouped() bool {
+	return (o.group || o.tree) && o.format == FormatText
+}
+
+// writeStreamingGroup writes each line of a group directly to stdout while
+// holding the output lock for the whole group.
+func (o *Output) writeStreamingGroup(results []result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.matched += len(results)
+	n := o.reserve(len(results))
+	if n == 0 {
+		return
+	}
+
+	fmt.Fprint(o.stdout, o.groupHeading(results[0].repo))
+	for _, r := range results[:n] {
+		fmt.Fprintln(o.stdout, o.formatText(r, true))
+	}
+}
+
+// writeBufferedGroup formats a whole group before taking the output lock, and
+// then writes it at once.
+func (o *Output) writeBufferedGroup(results []result) {
+	lines := make([]string, len(results))
+	for i, r := range results {
+		lines[i] = o.formatText(r, true)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.matched += len(results)
+	n := o.reserve(len(results))
+	if n == 0 {
+		return
+	}
+
+	var buf strings.Builder
+	buf.WriteString(o.groupHeading(results[0].repo))
+	for _, line := range lines[:n] {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	io.WriteString(o.stdout, buf.String())
+}
+
+// groupHeading returns the heading line for a repository's group, preceded
+// by a blank line if it isn't the first group. The caller must hold o.mu.
+func (o *Output) groupHeading(repo github.Repository) string {
+	heading := o.formatRepo(repo) + "\n"
+	if o.groups > 0 {
+		heading = "\n" + heading
+	}
+	o.groups++
+	return heading
+}
+
+// done reports whether no more matches can be written, so the search can
+// stop early: the result limit was reached, or a quiet search found a match.
+// Sorted output and duplicates need every match, so they're never done.
+func (o *Output) done() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch {
+	case o.sortKey != SortNone, o.duplicates != duplicateNone:
+		return false
+	case o.format == FormatQuiet:
+		return o.matched > 0
+	default:
+		return o.maxResults > 0 && o.written >= o.maxResults
+	}
+}
+
+// writable returns how many of n results written now would be rendered
+// rather than dropped by the result limit. Results that are buffered until
+// Flush aren't rendered yet, so none of them are.
+func (o *Output) writable(n int) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch {
+	case o.duplicates != duplicateNone, o.sortKey != SortNone && !o.grouped():
+		return 0
+	case o.maxResults > 0:
+		return max(0, min(n, o.maxResults-o.written))
+	default:
+		return n
+	}
+}
+
+// previewPending adds previews to the buffered matches that Flush will
+// write, once they're in the order it writes them, if previews were
+// requested. The lock isn't held while previewing, since fetching a preview
+// can warn, so this must only be called once the search is done.
+func (o *Output) previewPending() {
+	if o.preview == nil {
+		return
+	}
+
+	o.mu.Lock()
+	if o.duplicates != duplicateNone {
+		// Grouping the groups' members again gives the same groups.
+		sortResults(o.pending, o.duplicateSortKey(), o.reverse)
+		o.pending = slices.Concat(duplicateGroups(o.pending, o.duplicates)...)
+	} else {
+		sortResults(o.pending, o.sortKey, o.reverse)
+	}
+	pending := o.pending
+	if o.maxResults > 0 {
+		pending = pending[:max(0, min(len(pending), o.maxResults-o.written))]
+	}
+	o.mu.Unlock()
+
+	if len(pending) > 0 {
+		o.preview(pending)
+	}
+}
+
+// markStopped records that the search stopped early because the output was
+// done, so Flush reports it.
+func (o *Output) markStopped() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.stopped = true
+}
+
+// reserve claims up to n of the remaining result slots and returns how many
+// were claimed. The caller must hold o.mu.
+func (o *Output) reserve(n int) int {
+	if o.maxResults > 0 {
+		n = min(n, o.maxResults-o.written)
+	}
+	o.written += n
+	return n
+}
+
+// render writes a single result in the configured format, up to the result
+// limit. The caller must hold o.mu.
+func (o *Output) render(r result) {
+	if o.reserve(1) == 0 {
+		return
+	}
+
+	switch o.format {
+	case FormatJSON:
+		o.records = append(o.records, o.record(r))
+	case FormatNDJSON:
+		if err := json.NewEncoder(o.stdout).Encode(o.record(r)); err != nil {
+			fmt.Fprintf(o.stderr, o.yellow("Warning: ")+"failed to encode %s: %v\n", r.entry.Path, err)
+		}
+	case FormatCSV, FormatTSV:
+		o.writeRow(r)
+	case FormatHTML:
+		o.htmlRows = append(o.htmlRows, o.htmlRow(r))
+	case FormatURLs:
+		// Only blobs have contents to link to: directories and submodules
+		// are skipped.
+		if hasSize(r.entry) {
+			io.WriteString(o.stdout, rawURL(r.blobRepo(), r.entry.Path)+"\n")
+		}
+	case FormatTotal, FormatCount:
+		o.total++
+	case FormatQuiet:
+	case FormatText:
+		if o.printf != nil {
+			io.WriteString(o.stdout, o.printf.expand(r, replacePath(r.entry.Path, o.replace), o.formatSize))
+			return
+		}
+		io.WriteString(o.stdout, o.formatText(r, false)+o.terminator)
+	}
+}
+
+// formatRepo formats a repository as owner/repo, or owner/repo@ref if the
+// ref was given explicitly.
+func (o *Output) formatRepo(repo github.Repository) string {
 	repoName := repo.Name
 	if repo.ExplicitRef {
 		repoName += "@" + repo.Ref
 	}
+	return o.cyan(repo.Owner) + "/" + o.green(repoName)
+}
+
+// formatText formats a result as a line of text output. Grouped lines are
+// indented and omit the repository, which is written in the group heading.
+// Inherited health files are annotated with their source, and links point to
+// the file there.
+func (o *Output) formatText(r result, grouped bool) string {
+	formatted := o.formatPath(r.entry)
+	if !grouped && !o.noRepoPrefix {
+		formatted = o.formatRepo(r.repo) + ":" + formatted
+	}
+
+	formatted = o.annotate(r, formatted)
+
+	if o.icons {
+		formatted = fileIcon(r.entry) + " " + formatted
+	}
+
+	if o.long {
+		formatted = o.formatLong(r.entry) + " " + formatted
+	}
 
-	formatted := fmt.Sprintf("%s/%s:%s",
-		o.cyan(repo.Owner),
-		o.green(repoName),
-		o.white(path))
+	if grouped {
+		formatted = "  " + formatted
+	}
 
+	if r.preview != "" {
+		indent := "    "
+		if grouped {
+			indent += "  "
+		}
+		for line := range strings.SplitSeq(r.preview, "\n") {
+			formatted += "\n" + indent + line
+		}
+	}
+
+	return formatted
+}
+
+// formatPath colors an entry's path for text output. The file name is colored
+// by its type, and the parts that matched the pattern's literal text are
+// highlighted. Paths rewritten by replacements aren't highlighted because the
+// matched parts no longer line up.
+func (o *Output) formatPath(entry github.TreeEntry) string {
+	display := replacePath(entry.Path, o.replace)
+	nameStart := strings.LastIndexByte(display, '/') + 1
+	nameColor := o.nameColor(entry)
+	plain := func(start, end int) string {
+		if nameColor == nil {
+			return o.white(display[start:end])
+		}
+		split := min(max(start, nameStart), end)
+		return o.white(display[start:split]) + nameColor(display[split:end])
+	}
+
+	if o.highlight == nil || display != entry.Path {
+		return plain(0, len(display))
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, span := range o.highlight.spans(display) {
+		b.WriteString(plain(last, span[0]))
+		b.WriteString(o.red(display[span[0]:span[1]]))
+		last = span[1]
+	}
+	b.WriteString(plain(last, len(display)))
+	return b.String()
+}
+
+// nameColor returns the function that colors entry's file name, or nil if
+// it's colored like the rest of the path.
+func (o *Output) nameColor(entry github.TreeEntry) func(string) string {
+	if o.colors == nil {
+		return nil
+	}
+	return colorFunc(o.colors.style(entry))
+}
+
+// annotate links text to the file if hyperlinks are enabled, and appends the
+// source of an inherited file and the date, author, and URL of the file's
+// last commit if requested.
+func (o *Output) annotate(r result, text string) string {
+	blobRepo := r.blobRepo()
 	if o.hyperlinks {
-		url := fmt.Sprintf("%s/blob/%s/%s", repo.URL, repo.Ref, path)
-		formatted = makeHyperlink(url, formatted)
+		url := fmt.Sprintf("%s/blob/%s/%s", blobRepo.URL, blobRepo.Revision(), r.entry.Path)
+		text = makeHyperlink(url, text)
+	}
+
+	if r.source != nil {
+		text += o.yellow(" (inherited from " + r.source.FullName + ")")
+	}
+
+	if r.commit != nil {
+		if o.showMtime {
+			text += " " + r.commit.CommittedDate.UTC().Format(time.DateOnly)
+		}
+		if o.showAuthor && r.commit.Author != "" {
+			text += " " + r.commit.Author
+		}
+		if o.showCommit {
+			text += " " + commitURL(blobRepo, r.commit.OID)
+		}
+	}
+
+	return text
+}
+
+// shortSHALength is the number of characters of a blob SHA shown by Long.
+const shortSHALength = 7
+
+// formatLong formats the mode, type, size, and short SHA columns of long
+// output. The columns have fixed widths so they line up without buffering.
+// Directories and submodules have no size.
+func (o *Output) formatLong(entry github.TreeEntry) string {
+	size := "-"
+	if hasSize(entry) {
+		size = o.formatSize(entry.Size)
+	}
+	sha := entry.SHA[:min(len(entry.SHA), shortSHALength)]
+	return fmt.Sprintf("%-6s %-10s %9s %-*s", entry.Mode, github.ParseFileType(entry.Mode), size, shortSHALength, sha)
+}
+
+// formatSize formats a size in bytes, or with a binary unit suffix if sizes
+// are human-readable.
+func (o *Output) formatSize(size int64) string {
+	if o.human {
+		return humanSize(size)
 	}
+	return strconv.FormatInt(size, 10)
+}
+
+// humanSize formats a size like ls -h, using the binary unit suffixes that
+// --min-size and --max-size accept: 512, 12K, 1.4M. Sizes under 10 units
+// keep one decimal place.
+func humanSize(size int64) string {
+	const units = "KMGTP"
+	if size < 1024 {
+		return strconv.FormatInt(size, 10)
+	}
+
+	v := float64(size)
+	i := -1
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+
+	if v < 9.95 {
+		return strconv.FormatFloat(v, 'f', 1, 64) + units[i:i+1]
+	}
+	if math.Round(v) >= 1024 && i < len(units)-1 {
+		return "1.0" + units[i+1:i+2]
+	}
+	return strconv.FormatFloat(math.Round(v), 'f', 0, 64) + units[i:i+1]
+}
+
+// record returns the machine-readable representation of a match, including
+// its ID if the output was configured to include one.
+func (o *Output) record(r result) Record {
+	var record Record
+	if r.source != nil {
+		record = NewInheritedRecord(r.repo, *r.source, r.entry)
+	} else {
+		record = NewRecord(r.repo, r.entry)
+	}
+	if o.withID {
+		record.ID = RecordID(record.Owner, record.Repo, record.Ref, record.Path)
+	}
+	if r.commit != nil {
+		if o.showCommit {
+			record.CommitURL = commitURL(r.blobRepo(), r.commit.OID)
+		}
+		if o.showMtime {
+			record.Mtime = r.commit.CommittedDate.UTC().Format(time.RFC3339)
+		}
+		if o.showAuthor {
+			record.Author = r.commit.Author
+		}
+	}
+	record.Preview = r.preview
+	if o.human && hasSize(r.entry) {
+		record.SizeHuman = humanSize(r.entry.Size)
+	}
+	record.Path = replacePath(record.Path, o.replace)
+	return record
+}
+
+// Flush writes any buffered matches. It should be called once after all
+// matches have been written.
+func (o *Output) Flush() error {
+	o.previewPending()
 
 	o.mu.Lock()
-	fmt.Fprintln(o.stdout, formatted)
-	o.mu.Unlock()
+	defer o.mu.Unlock()
+
+	if o.duplicates != duplicateNone {
+		o.flushDuplicates()
+	} else if len(o.pending) > 0 {
+		sortResults(o.pending, o.sortKey, o.reverse)
+		for _, r := range o.pending {
+			o.render(r)
+		}
+		o.pending = nil
+	}
+
+	if o.stopped && o.maxResults > 0 {
+		fmt.Fprintf(o.stderr, "... (showing first %d matches; stopped searching at the limit)\n", o.written)
+	} else if o.written < o.matched {
+		qualifier := "first"
+		if o.sortKey != SortNone && !o.grouped() {
+			qualifier = "top"
+		}
+		fmt.Fprintf(o.stderr, "... (showing %s %d of %d matches)\n", qualifier, o.written, o.matched)
+	}
+
+	switch o.format {
+	case FormatJSON:
+		return o.flushJSON()
+	case FormatTotal:
+		_, err := fmt.Fprintln(o.stdout, o.total)
+		return err
+	case FormatCount:
+		_, err := fmt.Fprintf(o.stdout, "total:%d\n", o.total)
+		return err
+	case FormatCSV, FormatTSV:
+		return o.flushTable()
+	case FormatHTML:
+		return o.flushHTML()
+	case FormatNDJSON, FormatQuiet, FormatText:
+	}
+
+	return nil
+}
+
+func (o *Output) flushJSON() error {
+	records := o.records
+	if records == nil {
+		records = []Record{} // encode as [] rather than null
+	}
+
+	var data []byte
+	var err error
+	if o.jsonIndent && o.jq == "" {
+		data, err = json.MarshalIndent(records, "", "  ")
+	} else {
+		data, err = json.Marshal(records)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+
+	o.records = nil
+	if o.jq != "" {
+		return o.evaluateJQ(data)
+	}
+	_, err = fmt.Fprintln(o.stdout, string(data))
+	return err
+}
+
+// evaluateJQ writes the result of applying the jq expression to data. Like
+// gh's --jq, scalar results are written as raw values rather than JSON.
+func (o *Output) evaluateJQ(data []byte) error {
+	var indent string
+	if o.jsonIndent {
+		indent = "  "
+	}
+	if err := jq.EvaluateFormatted(bytes.NewReader(data), o.stdout, o.jq, indent, false); err != nil {
+		return fmt.Errorf("failed to evaluate --jq expression: %w", err)
+	}
+	return nil
 }
 
 // Warningf writes a formatted warning message to stderr.
@@ -2,9 +2,12 @@ package finder
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/jparise/gh-find/internal/github"
 )
@@ -37,7 +40,7 @@ func TestNewOutput(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
 
-			output := NewOutput(stdout, stderr, tt.colorize, tt.hyperlinks)
+			output := NewOutput(stdout, stderr, OutputOptions{Colorize: tt.colorize, Hyperlinks: tt.hyperlinks})
 			colorFuncs := []struct {
 				name string
 				fn   func(string) string
@@ -114,6 +117,19 @@ func TestMatch(t *testing.T) {
 			want:       "golang/go:src/cmd/go/main.go",
 			wantURL:    "https://github.com/golang/go/blob/master/src/cmd/go/main.go",
 		},
+		{
+			name: "enterprise server hyperlinks",
+			repo: github.Repository{
+				Owner: "team",
+				Name:  "app",
+				Ref:   "main",
+				URL:   "https://ghe.example.com/team/app",
+			},
+			path:       "main.go",
+			hyperlinks: true,
+			want:       "team/app:main.go",
+			wantURL:    "https://ghe.example.com/team/app/blob/main/main.go",
+		},
 		{
 			name: "explicit ref shown in output",
 			repo: github.Repository{
@@ -134,9 +150,9 @@ func TestMatch(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
 
-			output := NewOutput(stdout, stderr, false, tt.hyperlinks)
+			output := NewOutput(stdout, stderr, OutputOptions{Hyperlinks: tt.hyperlinks})
 
-			output.Match(tt.repo, tt.path)
+			output.Match(tt.repo, github.TreeEntry{Path: tt.path})
 			got := stdout.String()
 
 			if !strings.Contains(got, tt.want) {
@@ -154,6 +170,739 @@ func TestMatch(t *testing.T) {
 	}
 }
 
+func TestFlushJSON(t *testing.T) {
+	repo := github.Repository{
+		Owner: "cli",
+		Name:  "cli",
+		Ref:   "trunk",
+		URL:   "https://github.com/cli/cli",
+	}
+
+	tests := []struct {
+		name       string
+		entries    []github.TreeEntry
+		jsonIndent bool
+		want       string
+	}{
+		{
+			name:       "no matches compact",
+			jsonIndent: false,
+			want:       "[]\n",
+		},
+		{
+			name:       "no matches pretty",
+			jsonIndent: true,
+			want:       "[]\n",
+		},
+		{
+			name:       "compact",
+			entries:    []github.TreeEntry{{Path: "main.go", Mode: "100644", Size: 42}},
+			jsonIndent: false,
+			want:       `[{"owner":"cli","repo":"cli","ref":"trunk","path":"main.go","size":42,"mode":"100644","type":"file","url":"https://github.com/cli/cli/blob/trunk/main.go"}]` + "\n",
+		},
+		{
+			name:       "pretty",
+			entries:    []github.TreeEntry{{Path: "main.go", Mode: "100644", Size: 42}},
+			jsonIndent: true,
+			want: `[
+  {
+    "owner": "cli",
+    "repo": "cli",
+    "ref": "trunk",
+    "path": "main.go",
+    "size": 42,
+    "mode": "100644",
+    "type": "file",
+    "url": "https://github.com/cli/cli/blob/trunk/main.go"
+  }
+]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			output := NewOutput(stdout, stderr, OutputOptions{Format: FormatJSON, JSONIndent: tt.jsonIndent})
+
+			for _, entry := range tt.entries {
+				output.Match(repo, entry)
+			}
+			if stdout.Len() != 0 {
+				t.Errorf("Match() wrote before Flush(): %q", stdout.String())
+			}
+
+			if err := output.Flush(); err != nil {
+				t.Fatalf("Flush() unexpected error: %v", err)
+			}
+
+			got := stdout.String()
+			if got != tt.want {
+				t.Errorf("Flush() output = %q, want %q", got, tt.want)
+			}
+			if !json.Valid([]byte(got)) {
+				t.Errorf("Flush() output is not valid JSON: %q", got)
+			}
+		})
+	}
+}
+
+func TestFlushJQ(t *testing.T) {
+	repo := github.Repository{
+		Owner: "cli",
+		Name:  "cli",
+		Ref:   "trunk",
+		URL:   "https://github.com/cli/cli",
+	}
+
+	tests := []struct {
+		name       string
+		expr       string
+		jsonIndent bool
+		want       string
+		wantErr    bool
+	}{
+		{name: "raw strings", expr: ".[].path", want: "main.go\nREADME.md\n"},
+		{name: "numbers", expr: "map(.size) | add", want: "50\n"},
+		{name: "compact objects", expr: ".[0] | {path, size}", want: `{"path":"main.go","size":42}` + "\n"},
+		{
+			name:       "pretty objects",
+			expr:       ".[0] | {path}",
+			jsonIndent: true,
+			want:       "{\n  \"path\": \"main.go\"\n}\n",
+		},
+		{name: "runtime error", expr: ".[0].path | error", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{
+				Format:     FormatJSON,
+				JSONIndent: tt.jsonIndent,
+				JQ:         tt.expr,
+			})
+
+			output.Match(repo, github.TreeEntry{Path: "main.go", Mode: "100644", Size: 42})
+			output.Match(repo, github.TreeEntry{Path: "README.md", Mode: "100644", Size: 8})
+
+			err := output.Flush()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Flush() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Flush() unexpected error: %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("Flush() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoRepoPrefix(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", FullName: "cli/cli", URL: "https://github.com/cli/cli"}
+	r := result{repo: repo, entry: github.TreeEntry{Path: "cmd/main.go"}}
+
+	tests := []struct {
+		name string
+		opts OutputOptions
+		want string
+	}{
+		{name: "prefixed", want: "cli/cli:cmd/main.go\n"},
+		{name: "bare path", opts: OutputOptions{NoRepoPrefix: true}, want: "cmd/main.go\n"},
+		{name: "grouped", opts: OutputOptions{NoRepoPrefix: true, Group: true}, want: "cli/cli\n  cmd/main.go\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, tt.opts)
+			output.writeAll([]result{r})
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("writeAll() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatURLs(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk", URL: "https://github.com/cli/cli"}
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Format: FormatURLs})
+
+	output.writeAll([]result{
+		{repo: repo, entry: github.TreeEntry{Path: "main.go", Mode: "100644"}},
+		{repo: repo, entry: github.TreeEntry{Path: "docs", Mode: "040000"}},
+		{repo: repo, entry: github.TreeEntry{Path: "script/build.sh", Mode: "100755"}},
+	})
+	if err := output.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	want := "https://raw.githubusercontent.com/cli/cli/trunk/main.go\n" +
+		"https://raw.githubusercontent.com/cli/cli/trunk/script/build.sh\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	repo := github.Repository{
+		Owner: "cli",
+		Name:  "cli",
+		Ref:   "trunk",
+		URL:   "https://github.com/cli/cli",
+	}
+
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Format: FormatNDJSON})
+
+	output.Match(repo, github.TreeEntry{Path: "main.go", Mode: "100644", Size: 42})
+	want := `{"owner":"cli","repo":"cli","ref":"trunk","path":"main.go","size":42,"mode":"100644","type":"file","url":"https://github.com/cli/cli/blob/trunk/main.go"}` + "\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("Match() output = %q, want %q", got, want)
+	}
+
+	output.Match(repo, github.TreeEntry{Path: "docs", Mode: "040000"})
+	if err := output.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), stdout.String())
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("line is not valid JSON: %q", line)
+		}
+	}
+}
+
+func TestFlushTable(t *testing.T) {
+	repo := github.Repository{
+		Owner: "cli",
+		Name:  "cli",
+		Ref:   "trunk",
+		URL:   "https://github.com/cli/cli",
+	}
+	date := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		format  Format
+		results []result
+		want    string
+	}{
+		{
+			name:   "csv no matches",
+			format: FormatCSV,
+			want:   "repo,ref,path,size,type,mtime,url\n",
+		},
+		{
+			name:   "csv",
+			format: FormatCSV,
+			results: []result{
+				{repo: repo, entry: github.TreeEntry{Path: "main.go", Mode: "100644", Size: 42}},
+				{
+					repo:   repo,
+					entry:  github.TreeEntry{Path: `docs/"a, b".md`, Mode: "100644", Size: 7},
+					commit: &github.FileCommitInfo{CommittedDate: date},
+				},
+			},
+			want: "repo,ref,path,size,type,mtime,url\n" +
+				"cli/cli,trunk,main.go,42,file,,https://github.com/cli/cli/blob/trunk/main.go\n" +
+				`cli/cli,trunk,"docs/""a, b"".md",7,file,2024-01-15T10:30:00Z,"https://github.com/cli/cli/blob/trunk/docs/""a, b"".md"` + "\n",
+		},
+		{
+			name:   "tsv",
+			format: FormatTSV,
+			results: []result{
+				{repo: repo, entry: github.TreeEntry{Path: "a, b.go", Mode: "100755", Size: 1}},
+				{repo: repo, entry: github.TreeEntry{Path: "tab\there", Mode: "100644", Size: 2}},
+			},
+			want: "repo\tref\tpath\tsize\ttype\tmtime\turl\n" +
+				"cli/cli\ttrunk\ta, b.go\t1\texecutable\t\thttps://github.com/cli/cli/blob/trunk/a, b.go\n" +
+				"cli/cli\ttrunk\t\"tab\there\"\t2\tfile\t\t\"https://github.com/cli/cli/blob/trunk/tab\there\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Format: tt.format})
+
+			output.writeAll(tt.results)
+			if err := output.Flush(); err != nil {
+				t.Fatalf("Flush() unexpected error: %v", err)
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("Flush() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlushTotal(t *testing.T) {
+	tests := []struct {
+		name    string
+		matches int
+		want    string
+	}{
+		{name: "no matches", matches: 0, want: "0\n"},
+		{name: "several matches", matches: 3, want: "3\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			output := NewOutput(stdout, stderr, OutputOptions{Format: FormatTotal})
+
+			repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk"}
+			for range tt.matches {
+				output.Match(repo, github.TreeEntry{Path: "main.go"})
+			}
+			output.Warningf("still reported")
+
+			if stdout.Len() != 0 {
+				t.Errorf("Match() wrote before Flush(): %q", stdout.String())
+			}
+			if err := output.Flush(); err != nil {
+				t.Fatalf("Flush() unexpected error: %v", err)
+			}
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("Flush() output = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(stderr.String(), "still reported") {
+				t.Errorf("warnings not written to stderr: %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRecordWithID(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk"}
+	entry := github.TreeEntry{Path: "main.go"}
+
+	without := NewOutput(&bytes.Buffer{}, &bytes.Buffer{}, OutputOptions{Format: FormatJSON})
+	if id := without.record(result{repo: repo, entry: entry}).ID; id != "" {
+		t.Errorf("record() ID = %q without WithID, want empty", id)
+	}
+
+	with := NewOutput(&bytes.Buffer{}, &bytes.Buffer{}, OutputOptions{Format: FormatJSON, WithID: true})
+	first := with.record(result{repo: repo, entry: entry}).ID
+	second := with.record(result{repo: repo, entry: entry}).ID
+	if first == "" || first != second {
+		t.Errorf("record() IDs = %q, %q, want equal and non-empty", first, second)
+	}
+}
+
+func TestMatchPrint0(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Print0: true})
+
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk"}
+	output.Match(repo, github.TreeEntry{Path: "new\nline.go"})
+	output.Match(repo, github.TreeEntry{Path: "main.go"})
+
+	want := "cli/cli:new\nline.go\x00cli/cli:main.go\x00"
+	if got := stdout.String(); got != want {
+		t.Errorf("Match() output = %q, want %q", got, want)
+	}
+}
+
+func TestMatchLong(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk"}
+
+	tests := []struct {
+		name  string
+		entry github.TreeEntry
+		want  string
+	}{
+		{
+			name:  "file",
+			entry: github.TreeEntry{Path: "main.go", Mode: "100644", Size: 1234, SHA: "8b4c2a1f0e9d"},
+			want:  "100644 file            1234 8b4c2a1 cli/cli:main.go\n",
+		},
+		{
+			name:  "executable",
+			entry: github.TreeEntry{Path: "run.sh", Mode: "100755", Size: 42, SHA: "0a1b2c3d4e5f"},
+			want:  "100755 executable        42 0a1b2c3 cli/cli:run.sh\n",
+		},
+		{
+			name:  "directory",
+			entry: github.TreeEntry{Path: "docs", Mode: "040000", SHA: "fedcba987654"},
+			want:  "040000 directory          - fedcba9 cli/cli:docs\n",
+		},
+		{
+			name:  "missing SHA",
+			entry: github.TreeEntry{Path: "main.go", Mode: "100644", Size: 1},
+			want:  "100644 file               1         cli/cli:main.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Long: true})
+
+			output.Match(repo, tt.entry)
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("Match() output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{size: 0, want: "0"},
+		{size: 1023, want: "1023"},
+		{size: 1024, want: "1.0K"},
+		{size: 1536, want: "1.5K"},
+		{size: 10137, want: "9.9K"},
+		{size: 10200, want: "10K"},
+		{size: 12 * 1024, want: "12K"},
+		{size: 1024*1024 - 1, want: "1.0M"},
+		{size: 1468006, want: "1.4M"},
+		{size: 5 << 30, want: "5.0G"},
+		{size: 2048 << 40, want: "2.0P"},
+		{size: 4096 << 50, want: "4096P"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := humanSize(tt.size); got != tt.want {
+				t.Errorf("humanSize(%d) = %q, want %q", tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHumanReadable(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk"}
+	entry := github.TreeEntry{Path: "main.go", Mode: "100644", Size: 1468006, SHA: "8b4c2a1f"}
+
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Long: true, HumanReadable: true})
+	output.Match(repo, entry)
+	if want := "100644 file            1.4M 8b4c2a1 cli/cli:main.go\n"; stdout.String() != want {
+		t.Errorf("Match() output = %q, want %q", stdout.String(), want)
+	}
+
+	record := output.record(result{repo: repo, entry: entry})
+	if record.Size != entry.Size || record.SizeHuman != "1.4M" {
+		t.Errorf("record() size = %d, %q, want %d, %q", record.Size, record.SizeHuman, entry.Size, "1.4M")
+	}
+
+	dir := output.record(result{repo: repo, entry: github.TreeEntry{Path: "docs", Mode: "040000"}})
+	if dir.SizeHuman != "" {
+		t.Errorf("record() directory SizeHuman = %q, want empty", dir.SizeHuman)
+	}
+}
+
+func TestMatchPathReplacements(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk", URL: "https://github.com/cli/cli"}
+	entry := github.TreeEntry{Path: "pkg/cmd/root.go"}
+
+	replacement, err := ParsePathReplacement("^pkg/=")
+	if err != nil {
+		t.Fatalf("ParsePathReplacement() error = %v", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{
+		Hyperlinks:       true,
+		WithID:           true,
+		PathReplacements: []PathReplacement{replacement},
+	})
+
+	output.Match(repo, entry)
+	got := stdout.String()
+	if !strings.Contains(got, "cli/cli:cmd/root.go") {
+		t.Errorf("Match() output = %q, want the rewritten path", got)
+	}
+	if !strings.Contains(got, "https://github.com/cli/cli/blob/trunk/pkg/cmd/root.go") {
+		t.Errorf("Match() output = %q, want the URL to use the original path", got)
+	}
+
+	record := output.record(result{repo: repo, entry: entry})
+	if record.Path != "cmd/root.go" {
+		t.Errorf("record() Path = %q, want %q", record.Path, "cmd/root.go")
+	}
+	if want := RecordID("cli", "cli", "trunk", "pkg/cmd/root.go"); record.ID != want {
+		t.Errorf("record() ID = %q, want %q from the original path", record.ID, want)
+	}
+}
+
+func TestShowCommit(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk", URL: "https://github.com/cli/cli"}
+	entry := github.TreeEntry{Path: "main.go"}
+	commit := &github.FileCommitInfo{Path: "main.go", OID: "8b4c2a1f0e"}
+	const want = "https://github.com/cli/cli/commit/8b4c2a1f0e"
+
+	tests := []struct {
+		name       string
+		showCommit bool
+		commit     *github.FileCommitInfo
+		wantURL    string
+	}{
+		{name: "shown", showCommit: true, commit: commit, wantURL: want},
+		{name: "not requested", commit: commit},
+		{name: "not fetched", showCommit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{ShowCommit: tt.showCommit})
+			r := result{repo: repo, entry: entry, commit: tt.commit}
+
+			output.write(r)
+			wantText := "cli/cli:main.go"
+			if tt.wantURL != "" {
+				wantText += " " + tt.wantURL
+			}
+			if got := strings.TrimSpace(stdout.String()); got != wantText {
+				t.Errorf("write() output = %q, want %q", got, wantText)
+			}
+
+			if got := output.record(r).CommitURL; got != tt.wantURL {
+				t.Errorf("record() CommitURL = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestShowMtimeAndAuthor(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk", URL: "https://github.com/cli/cli"}
+	entry := github.TreeEntry{Path: "main.go"}
+	commit := &github.FileCommitInfo{
+		Path:          "main.go",
+		OID:           "8b4c2a1f0e",
+		CommittedDate: time.Date(2024, 1, 15, 22, 30, 0, 0, time.FixedZone("PST", -8*60*60)),
+		Author:        "jparise",
+	}
+
+	tests := []struct {
+		name       string
+		opts       OutputOptions
+		commit     *github.FileCommitInfo
+		wantText   string
+		wantMtime  string
+		wantAuthor string
+	}{
+		{
+			name:       "both",
+			opts:       OutputOptions{ShowMtime: true, ShowAuthor: true},
+			commit:     commit,
+			wantText:   "cli/cli:main.go 2024-01-16 jparise",
+			wantMtime:  "2024-01-16T06:30:00Z",
+			wantAuthor: "jparise",
+		},
+		{
+			name:      "mtime only",
+			opts:      OutputOptions{ShowMtime: true},
+			commit:    commit,
+			wantText:  "cli/cli:main.go 2024-01-16",
+			wantMtime: "2024-01-16T06:30:00Z",
+		},
+		{
+			name:       "with commit",
+			opts:       OutputOptions{ShowAuthor: true, ShowCommit: true},
+			commit:     commit,
+			wantText:   "cli/cli:main.go jparise https://github.com/cli/cli/commit/8b4c2a1f0e",
+			wantAuthor: "jparise",
+		},
+		{
+			name:     "not fetched",
+			opts:     OutputOptions{ShowMtime: true, ShowAuthor: true},
+			wantText: "cli/cli:main.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, tt.opts)
+			r := result{repo: repo, entry: entry, commit: tt.commit}
+
+			output.write(r)
+			if got := strings.TrimSpace(stdout.String()); got != tt.wantText {
+				t.Errorf("write() output = %q, want %q", got, tt.wantText)
+			}
+
+			record := output.record(r)
+			if record.Mtime != tt.wantMtime || record.Author != tt.wantAuthor {
+				t.Errorf("record() Mtime, Author = %q, %q, want %q, %q", record.Mtime, record.Author, tt.wantMtime, tt.wantAuthor)
+			}
+		})
+	}
+}
+
+func TestMaxResults(t *testing.T) {
+	repo := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a"}
+	sizes := []int64{100, 400, 200, 300}
+
+	tests := []struct {
+		name       string
+		sort       SortKey
+		maxResults int
+		want       string
+		wantNotice string
+	}{
+		{
+			name: "no limit",
+			want: "octo/a:f0\nocto/a:f1\nocto/a:f2\nocto/a:f3\n",
+		},
+		{
+			name:       "first matches",
+			maxResults: 2,
+			want:       "octo/a:f0\nocto/a:f1\n",
+			wantNotice: "... (showing first 2 of 4 matches)\n",
+		},
+		{
+			name:       "top matches after sorting",
+			sort:       SortSize,
+			maxResults: 2,
+			want:       "octo/a:f1\nocto/a:f3\n",
+			wantNotice: "... (showing top 2 of 4 matches)\n",
+		},
+		{
+			name:       "limit not reached",
+			sort:       SortSize,
+			maxResults: 10,
+			want:       "octo/a:f1\nocto/a:f3\nocto/a:f2\nocto/a:f0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			output := NewOutput(stdout, stderr, OutputOptions{Sort: tt.sort, MaxResults: tt.maxResults})
+
+			for i, size := range sizes {
+				output.Match(repo, github.TreeEntry{Path: fmt.Sprintf("f%d", i), Size: size})
+			}
+			if err := output.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if got := stderr.String(); got != tt.wantNotice {
+				t.Errorf("notice = %q, want %q", got, tt.wantNotice)
+			}
+		})
+	}
+}
+
+func TestWriteGroup(t *testing.T) {
+	a := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a"}
+	b := github.Repository{Owner: "octo", Name: "b", FullName: "octo/b", Ref: "dev", ExplicitRef: true}
+
+	for _, buffer := range []bool{false, true} {
+		t.Run(fmt.Sprintf("buffer=%v", buffer), func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Group: true, BufferGroups: buffer, MaxResults: 3})
+
+			output.writeAll([]result{{repo: a, entry: github.TreeEntry{Path: "main.go"}}, {repo: a, entry: github.TreeEntry{Path: "cmd/root.go"}}})
+			output.writeAll(nil)
+			output.writeAll([]result{{repo: b, entry: github.TreeEntry{Path: "util.go"}}, {repo: b, entry: github.TreeEntry{Path: "extra.go"}}})
+			output.writeAll([]result{{repo: a, entry: github.TreeEntry{Path: "dropped.go"}}})
+
+			want := "octo/a\n  main.go\n  cmd/root.go\n\nocto/b@dev\n  util.go\n"
+			if got := stdout.String(); got != want {
+				t.Errorf("writeAll() output = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteGroupSorted(t *testing.T) {
+	a := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a"}
+	b := github.Repository{Owner: "octo", Name: "b", FullName: "octo/b"}
+
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	output := NewOutput(stdout, stderr, OutputOptions{Group: true, Sort: SortPath, Reverse: true, MaxResults: 3})
+
+	results := []result{{repo: b, entry: github.TreeEntry{Path: "b.go"}}, {repo: b, entry: github.TreeEntry{Path: "c.go"}}}
+	output.writeAll(results)
+	output.writeAll([]result{{repo: a, entry: github.TreeEntry{Path: "a.go"}}, {repo: a, entry: github.TreeEntry{Path: "z.go"}}})
+
+	// Groups are written as they arrive, each sorted on its own.
+	want := "octo/b\n  c.go\n  b.go\n\nocto/a\n  z.go\n"
+	if got := stdout.String(); got != want {
+		t.Errorf("writeAll() output = %q, want %q", got, want)
+	}
+	if results[0].entry.Path != "b.go" {
+		t.Errorf("writeAll() reordered the caller's results")
+	}
+
+	if err := output.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+	if want := "... (showing first 3 of 4 matches)\n"; stderr.String() != want {
+		t.Errorf("Flush() stderr = %q, want %q", stderr.String(), want)
+	}
+}
+
+func TestWriteGroupConcurrency(t *testing.T) {
+	const repos, files = 20, 50
+
+	for _, buffer := range []bool{false, true} {
+		t.Run(fmt.Sprintf("buffer=%v", buffer), func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{Group: true, BufferGroups: buffer})
+
+			var wg sync.WaitGroup
+			for i := range repos {
+				wg.Go(func() {
+					name := fmt.Sprintf("repo%d", i)
+					repo := github.Repository{Owner: "octo", Name: name, FullName: "octo/" + name}
+					results := make([]result, files)
+					for j := range results {
+						results[j] = result{repo: repo, entry: github.TreeEntry{Path: fmt.Sprintf("%s-%d.go", name, j)}}
+					}
+					output.writeAll(results)
+				})
+			}
+			wg.Wait()
+
+			// Every group must be contiguous: a heading followed by only its own files.
+			groups := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n\n")
+			if len(groups) != repos {
+				t.Fatalf("got %d groups, want %d", len(groups), repos)
+			}
+			for _, group := range groups {
+				lines := strings.Split(group, "\n")
+				name := strings.TrimPrefix(lines[0], "octo/")
+				if len(lines) != files+1 {
+					t.Errorf("group %s has %d lines, want %d", name, len(lines), files+1)
+				}
+				for _, line := range lines[1:] {
+					if !strings.HasPrefix(line, "  "+name+"-") {
+						t.Errorf("group %s contains %q", name, line)
+					}
+				}
+			}
+		})
+	}
+}
+
 func TestWarningf(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -178,7 +927,7 @@ func TestWarningf(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
-			output := NewOutput(stdout, stderr, false, false)
+			output := NewOutput(stdout, stderr, OutputOptions{})
 
 			output.Warningf(tt.format, tt.args...)
 			got := stderr.String()
@@ -218,7 +967,7 @@ func TestInfof(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			stdout := &bytes.Buffer{}
 			stderr := &bytes.Buffer{}
-			output := NewOutput(stdout, stderr, false, false)
+			output := NewOutput(stdout, stderr, OutputOptions{})
 
 			output.Infof(tt.format, tt.args...)
 			got := stderr.String()
@@ -237,7 +986,7 @@ func TestInfof(t *testing.T) {
 func TestOutputThreadSafety(t *testing.T) {
 	stdout := &bytes.Buffer{}
 	stderr := &bytes.Buffer{}
-	output := NewOutput(stdout, stderr, false, false)
+	output := NewOutput(stdout, stderr, OutputOptions{})
 
 	repo := github.Repository{
 		Owner: "owner",
@@ -256,7 +1005,7 @@ func TestOutputThreadSafety(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			for range numCalls {
-				output.Match(repo, "file.go")
+				output.Match(repo, github.TreeEntry{Path: "file.go"})
 			}
 		}()
 		go func() {
@@ -0,0 +1,136 @@
+package finder
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// posixClasses maps POSIX character class names to equivalent bracket
+// expression contents understood by doublestar. Characters that are special
+// inside a bracket expression (including a leading '!') are escaped.
+var posixClasses = map[string]string{
+	"alnum":  `a-zA-Z0-9`,
+	"alpha":  `a-zA-Z`,
+	"blank":  " \t",
+	"cntrl":  "\x00-\x1f\x7f",
+	"digit":  `0-9`,
+	"graph":  `\!-~`,
+	"lower":  `a-z`,
+	"print":  ` -~`,
+	"punct":  `\!-/:-@\[-\` + "`" + `{-~`,
+	"space":  " \t\n\v\f\r",
+	"upper":  `A-Z`,
+	"word":   `a-zA-Z0-9_`,
+	"xdigit": `0-9A-Fa-f`,
+}
+
+// expandPOSIXClasses rewrites POSIX character classes such as [[:digit:]]
+// into explicit ranges, since doublestar doesn't support them natively.
+// Classes are only recognized inside bracket expressions, and an unknown
+// class name is an error. A ']' at the start of a bracket expression, as in
+// []a] or [!]a], is a literal as in POSIX, so it's escaped for doublestar.
+func expandPOSIXClasses(pattern string) (string, error) {
+	if !strings.Contains(pattern, "[") {
+		return pattern, nil
+	}
+
+	var buf strings.Builder
+	buf.Grow(len(pattern))
+
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			buf.WriteByte(c)
+			buf.WriteByte(pattern[i+1])
+			i++
+		case !inClass && c == '[':
+			inClass = true
+			buf.WriteByte(c)
+			if i+1 < len(pattern) && (pattern[i+1] == '!' || pattern[i+1] == '^') {
+				buf.WriteByte(pattern[i+1])
+				i++
+			}
+			if i+1 < len(pattern) && pattern[i+1] == ']' {
+				buf.WriteString(`\]`)
+				i++
+			}
+		case inClass && c == ']':
+			inClass = false
+			buf.WriteByte(c)
+		case inClass && strings.HasPrefix(pattern[i:], "[:"):
+			end := strings.Index(pattern[i+2:], ":]")
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class in pattern %q", pattern)
+			}
+			name := pattern[i+2 : i+2+end]
+			expansion, ok := posixClasses[name]
+			if !ok {
+				return "", fmt.Errorf("unknown character class [:%s:] in pattern %q", name, pattern)
+			}
+			buf.WriteString(expansion)
+			i += 2 + end + 1
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// CompileRegexp compiles a regular expression pattern for --regex, matching
+// case-insensitively if ignoreCase is set.
+func CompileRegexp(expr string, ignoreCase bool) (*regexp.Regexp, error) {
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// patternsConflict reports whether every one of patterns has an
+// extensionConflict, in which case no file can match.
+func patternsConflict(patterns, extensions []string, ignoreCase bool) bool {
+	return len(patterns) > 0 && !slices.ContainsFunc(patterns, func(pattern string) bool {
+		return !extensionConflict(pattern, extensions, ignoreCase)
+	})
+}
+
+// describePatterns formats patterns for messages, such as `pattern "*.go"`
+// or `patterns "*.yml", "*.yaml"`.
+func describePatterns(patterns []string) string {
+	quoted := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		quoted[i] = strconv.Quote(pattern)
+	}
+	if len(patterns) == 1 {
+		return "pattern " + quoted[0]
+	}
+	return "patterns " + strings.Join(quoted, ", ")
+}
+
+// extensionConflict reports whether pattern names a literal extension that
+// isn't one of extensions, in which case no file can match both filters. It
+// is deliberately conservative: patterns whose extension contains any glob
+// syntax (e.g. "*.{go,md}" or "*.t?t") are never considered conflicting.
+func extensionConflict(pattern string, extensions []string, ignoreCase bool) bool {
+	if len(extensions) == 0 {
+		return false
+	}
+
+	ext := path.Ext(pattern)
+	if ext == "" || ext == "." || strings.ContainsAny(ext, `*?[]{}\/`) {
+		return false
+	}
+
+	if ignoreCase {
+		return !slices.ContainsFunc(extensions, func(e string) bool {
+			return strings.EqualFold(e, ext)
+		})
+	}
+	return !slices.Contains(extensions, ext)
+}
@@ -0,0 +1,170 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+func TestExpandPOSIXClasses(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+		wantErr bool
+	}{
+		{name: "no classes", pattern: "*.go", want: "*.go"},
+		{name: "digit", pattern: "file[[:digit:]].txt", want: "file[0-9].txt"},
+		{name: "alpha", pattern: "[[:alpha:]]*", want: "[a-zA-Z]*"},
+		{name: "mixed with range", pattern: "[[:upper:]_0-9]", want: "[A-Z_0-9]"},
+		{name: "negated", pattern: "[^[:space:]]", want: "[^ \t\n\v\f\r]"},
+		{name: "multiple classes", pattern: "[[:lower:]][[:digit:]]", want: "[a-z][0-9]"},
+		{name: "outside bracket expression", pattern: "[:digit:]", want: "[:digit:]"},
+		{name: "escaped bracket", pattern: `\[[:digit:]]`, want: `\[[:digit:]]`},
+		{name: "leading bracket", pattern: "[]a]", want: `[\]a]`},
+		{name: "negated leading bracket", pattern: "[!]a]", want: `[!\]a]`},
+		{name: "leading bracket with class", pattern: "[][:digit:]]", want: `[\]0-9]`},
+		{name: "unknown class", pattern: "[[:bogus:]]", wantErr: true},
+		{name: "unterminated class", pattern: "[[:digit", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandPOSIXClasses(tt.pattern)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expandPOSIXClasses(%q) expected error, got nil", tt.pattern)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("expandPOSIXClasses(%q) unexpected error: %v", tt.pattern, err)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("expandPOSIXClasses(%q) = %q, want %q", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPOSIXClassesMatch(t *testing.T) {
+	tests := []struct {
+		class string
+		match string
+		miss  string
+	}{
+		{class: "alnum", match: "q", miss: "-"},
+		{class: "blank", match: "\t", miss: "x"},
+		{class: "punct", match: "`", miss: "a"},
+		{class: "punct", match: `\`, miss: "0"},
+		{class: "punct", match: "]", miss: " "},
+		{class: "xdigit", match: "F", miss: "g"},
+		{class: "graph", match: "!", miss: " "},
+		{class: "word", match: "_", miss: "."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.class, func(t *testing.T) {
+			pattern, err := expandPOSIXClasses("[[:" + tt.class + ":]]")
+			if err != nil {
+				t.Fatalf("expandPOSIXClasses() error = %v", err)
+			}
+
+			if ok, err := doublestar.Match(pattern, tt.match); err != nil || !ok {
+				t.Errorf("Match(%q, %q) = %v, %v; want true", pattern, tt.match, ok, err)
+			}
+			if ok, err := doublestar.Match(pattern, tt.miss); err != nil || ok {
+				t.Errorf("Match(%q, %q) = %v, %v; want false", pattern, tt.miss, ok, err)
+			}
+		})
+	}
+}
+
+func TestLeadingBracketMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   string
+		miss    string
+	}{
+		{pattern: "[]a]", match: "]", miss: "b"},
+		{pattern: "[!]a]", match: "b", miss: "]"},
+		{pattern: "[^]]", match: "a", miss: "]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			pattern, err := expandPOSIXClasses(tt.pattern)
+			if err != nil {
+				t.Fatalf("expandPOSIXClasses() error = %v", err)
+			}
+
+			if ok, err := doublestar.Match(pattern, tt.match); err != nil || !ok {
+				t.Errorf("Match(%q, %q) = %v, %v; want true", pattern, tt.match, ok, err)
+			}
+			if ok, err := doublestar.Match(pattern, tt.miss); err != nil || ok {
+				t.Errorf("Match(%q, %q) = %v, %v; want false", pattern, tt.miss, ok, err)
+			}
+		})
+	}
+}
+
+func TestExtensionConflict(t *testing.T) {
+	tests := []struct {
+		name       string
+		pattern    string
+		extensions []string
+		ignoreCase bool
+		want       bool
+	}{
+		{name: "no extensions", pattern: "*.md", want: false},
+		{name: "different extension", pattern: "*.md", extensions: []string{".go"}, want: true},
+		{name: "same extension", pattern: "*.go", extensions: []string{".go"}, want: false},
+		{name: "one of several", pattern: "*.go", extensions: []string{".md", ".go"}, want: false},
+		{name: "literal name", pattern: "README.md", extensions: []string{".txt"}, want: true},
+		{name: "full path", pattern: "docs/**/*.md", extensions: []string{".go"}, want: true},
+		{name: "case mismatch", pattern: "*.MD", extensions: []string{".md"}, want: true},
+		{name: "case mismatch ignored", pattern: "*.MD", extensions: []string{".md"}, ignoreCase: true, want: false},
+		{name: "no extension in pattern", pattern: "Makefile", extensions: []string{".go"}, want: false},
+		{name: "star", pattern: "*", extensions: []string{".go"}, want: false},
+		{name: "wildcard extension", pattern: "*.*", extensions: []string{".go"}, want: false},
+		{name: "alternatives", pattern: "*.{go,md}", extensions: []string{".go"}, want: false},
+		{name: "character class", pattern: "*.[ch]", extensions: []string{".c"}, want: false},
+		{name: "single character", pattern: "*.g?", extensions: []string{".go"}, want: false},
+		{name: "dot directory", pattern: ".github/*", extensions: []string{".yml"}, want: false},
+		{name: "trailing dot", pattern: "file.", extensions: []string{".go"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extensionConflict(tt.pattern, tt.extensions, tt.ignoreCase); got != tt.want {
+				t.Errorf("extensionConflict(%q, %v, %v) = %v, want %v",
+					tt.pattern, tt.extensions, tt.ignoreCase, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternsConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     bool
+	}{
+		{name: "one conflicting", patterns: []string{"*.md"}, want: true},
+		{name: "all conflicting", patterns: []string{"*.md", "*.txt"}, want: true},
+		{name: "one matching", patterns: []string{"*.md", "*.go"}, want: false},
+		{name: "none", patterns: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := patternsConflict(tt.patterns, []string{".go"}, false); got != tt.want {
+				t.Errorf("patternsConflict(%q) = %v, want %v", tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// binarySniffLen is how much of a file is checked for NUL bytes when deciding
+// whether it is binary, the same heuristic Git uses.
+const binarySniffLen = 8000
+
+// isBinary reports whether data looks like the contents of a binary file.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), binarySniffLen)], 0) >= 0
+}
+
+// truncateUTF8 returns at most the first n bytes of data without splitting a
+// multi-byte UTF-8 sequence.
+func truncateUTF8(data []byte, n int) []byte {
+	if len(data) <= n {
+		return data
+	}
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
+	}
+	return data[:n]
+}
+
+// previewFetchLen returns how much of a file to fetch for an n-byte preview:
+// enough to finish a multi-byte UTF-8 sequence at the end, and at least
+// binarySniffLen so binary files are detected the same way as elsewhere.
+func previewFetchLen(n int) int {
+	return max(n+utf8.UTFMax, binarySniffLen)
+}
+
+// addPreviews fetches the first n bytes of each result's file. Only the
+// leading bytes are fetched, by blob SHA, which always matches the tree that
+// was searched. Only regular files are previewed, and binary files are
+// skipped. Files whose content can't be fetched are reported and left
+// without a preview.
+func (f *Finder) addPreviews(ctx context.Context, results []result, n int) error {
+	for i := range results {
+		r := &results[i]
+		switch github.ParseFileType(r.entry.Mode) {
+		case github.FileTypeFile, github.FileTypeExecutable:
+		case github.FileTypeDirectory, github.FileTypeSymlink, github.FileTypeSubmodule:
+			continue
+		}
+
+		data, err := f.client.GetBlobPrefix(ctx, r.repo, r.entry.SHA, previewFetchLen(n))
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			f.output.Warningf("%s: %s: %v", r.repo.FullName, r.entry.Path, err)
+			continue
+		}
+		if isBinary(data) {
+			continue
+		}
+
+		r.preview = strings.TrimRight(string(truncateUTF8(data, n)), "\n")
+	}
+	return nil
+}
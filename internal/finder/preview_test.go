@@ -0,0 +1,55 @@
+package finder
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		n    int
+		want string
+	}{
+		{name: "shorter than limit", data: "hello", n: 10, want: "hello"},
+		{name: "exact limit", data: "hello", n: 5, want: "hello"},
+		{name: "ascii", data: "hello world", n: 5, want: "hello"},
+		{name: "rune boundary", data: "héllo", n: 3, want: "hé"},
+		{name: "inside two-byte rune", data: "héllo", n: 2, want: "h"},
+		{name: "inside four-byte rune", data: "a😀b", n: 3, want: "a"},
+		{name: "after four-byte rune", data: "a😀b", n: 5, want: "a😀"},
+		{name: "zero", data: "hello", n: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(truncateUTF8([]byte(tt.data), tt.n)); got != tt.want {
+				t.Errorf("truncateUTF8(%q, %d) = %q, want %q", tt.data, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{name: "empty", data: nil, want: false},
+		{name: "text", data: []byte("package main\n"), want: false},
+		{name: "utf-8", data: []byte("héllo wörld"), want: false},
+		{name: "nul byte", data: []byte("\x89PNG\r\n\x1a\n\x00\x00"), want: true},
+		{name: "nul within sniff length", data: append([]byte("text"), make([]byte, binarySniffLen)...), want: true},
+		{name: "nul after sniff length", data: append(bytes.Repeat([]byte("a"), binarySniffLen), 0), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinary(tt.data); got != tt.want {
+				t.Errorf("isBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
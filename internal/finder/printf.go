@@ -0,0 +1,167 @@
+package finder
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// Printf is a parsed --printf format. Like find(1), no newline is added after
+// each match unless the format ends with \n.
+type Printf struct {
+	format   string
+	segments []printfSegment
+}
+
+// printfSegment is either literal text or a single directive.
+type printfSegment struct {
+	literal   string
+	directive byte // 0 for literal text
+}
+
+// printfDirectives are the directive characters that may follow a %, other
+// than %T@ and %%.
+const printfDirectives = "pfhsmyHiu"
+
+// printfEscapes maps the supported backslash escapes to their characters.
+var printfEscapes = map[byte]string{
+	'n':  "\n",
+	't':  "\t",
+	'0':  "\x00",
+	'\\': "\\",
+}
+
+// ParsePrintf parses a find(1)-style format. The supported directives are %p,
+// %f, %h, %s, %m, %y, %H, %i, %u, %T@, and %%, and the supported escapes are
+// \n, \t, \0, and \\. Like find's inode number, %i is the blob SHA that
+// identifies the file's contents, and %u is the last commit's author rather
+// than the file's owner.
+func ParsePrintf(format string) (*Printf, error) {
+	p := &Printf{format: format}
+	var literal strings.Builder
+
+	flush := func() {
+		if literal.Len() > 0 {
+			p.segments = append(p.segments, printfSegment{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for i := 0; i < len(format); i++ {
+		switch c := format[i]; c {
+		case '\\':
+			if i+1 == len(format) {
+				return nil, fmt.Errorf("invalid format %q: trailing backslash", format)
+			}
+			i++
+			escape, ok := printfEscapes[format[i]]
+			if !ok {
+				return nil, fmt.Errorf("invalid format %q: unknown escape \\%c", format, format[i])
+			}
+			literal.WriteString(escape)
+		case '%':
+			if i+1 == len(format) {
+				return nil, fmt.Errorf("invalid format %q: trailing %%", format)
+			}
+			i++
+			d := format[i]
+			switch {
+			case d == '%':
+				literal.WriteByte('%')
+				continue
+			case d == 'T':
+				if i+1 == len(format) || format[i+1] != '@' {
+					return nil, fmt.Errorf("invalid format %q: only %%T@ is supported", format)
+				}
+				i++
+				d = '@'
+			case strings.IndexByte(printfDirectives, d) < 0:
+				return nil, fmt.Errorf("invalid format %q: unknown directive %%%c", format, d)
+			}
+			flush()
+			p.segments = append(p.segments, printfSegment{directive: d})
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+
+	return p, nil
+}
+
+// String returns the original format.
+func (p *Printf) String() string {
+	return p.format
+}
+
+// NeedsCommitDates reports whether the format uses the last commit's time or
+// author.
+func (p *Printf) NeedsCommitDates() bool {
+	for _, seg := range p.segments {
+		if seg.directive == '@' || seg.directive == 'u' {
+			return true
+		}
+	}
+	return false
+}
+
+// expand expands the directives for a result. displayPath is the path after
+// any replacements, and formatSize formats %s. The last commit's time and
+// author are empty if they weren't fetched.
+func (p *Printf) expand(r result, displayPath string, formatSize func(int64) string) string {
+	var b strings.Builder
+	for _, seg := range p.segments {
+		switch seg.directive {
+		case 0:
+			b.WriteString(seg.literal)
+		case 'p':
+			b.WriteString(displayPath)
+		case 'f':
+			b.WriteString(path.Base(displayPath))
+		case 'h':
+			b.WriteString(path.Dir(displayPath))
+		case 's':
+			b.WriteString(formatSize(r.entry.Size))
+		case 'm':
+			b.WriteString(r.entry.Mode)
+		case 'y':
+			b.WriteString(typeLetter(github.ParseFileType(r.entry.Mode)))
+		case 'H':
+			b.WriteString(r.repo.Owner + "/" + r.repo.Name)
+			if r.repo.ExplicitRef {
+				b.WriteString("@" + r.repo.Ref)
+			}
+		case 'i':
+			b.WriteString(r.entry.SHA)
+		case 'u':
+			if r.commit != nil {
+				b.WriteString(r.commit.Author)
+			}
+		case '@':
+			if r.commit != nil {
+				b.WriteString(strconv.FormatInt(r.commit.CommittedDate.Unix(), 10))
+			}
+		}
+	}
+	return b.String()
+}
+
+// typeLetter returns the single-letter form of t accepted by --type.
+func typeLetter(t github.FileType) string {
+	switch t {
+	case github.FileTypeFile:
+		return "f"
+	case github.FileTypeExecutable:
+		return "x"
+	case github.FileTypeDirectory:
+		return "d"
+	case github.FileTypeSymlink:
+		return "l"
+	case github.FileTypeSubmodule:
+		return "s"
+	}
+	return ""
+}
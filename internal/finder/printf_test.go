@@ -0,0 +1,144 @@
+package finder
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestParsePrintf(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{format: "%p\n"},
+		{format: "%H:%p %s %m %y %T@\\n"},
+		{format: "100%%\\0"},
+		{format: "plain text"},
+		{format: ""},
+		{format: "%z", wantErr: true},
+		{format: "%Tk", wantErr: true},
+		{format: "%T", wantErr: true},
+		{format: "%@", wantErr: true},
+		{format: "%", wantErr: true},
+		{format: "\\q", wantErr: true},
+		{format: "\\", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p, err := ParsePrintf(tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrintf(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+			}
+			if err == nil && p.String() != tt.format {
+				t.Errorf("String() = %q, want %q", p.String(), tt.format)
+			}
+		})
+	}
+}
+
+func TestPrintfExpand(t *testing.T) {
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk"}
+	entry := github.TreeEntry{Path: "pkg/cmd/main.go", Mode: "100755", Size: 42}
+	date := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		format string
+		r      result
+		want   string
+	}{
+		{name: "path", format: "%p\\n", r: result{repo: repo, entry: entry}, want: "pkg/cmd/main.go\n"},
+		{name: "name and dir", format: "%h %f", r: result{repo: repo, entry: entry}, want: "pkg/cmd main.go"},
+		{name: "root dir", format: "%h", r: result{repo: repo, entry: github.TreeEntry{Path: "go.mod"}}, want: "."},
+		{name: "size mode type", format: "%s\\t%m\\t%y", r: result{repo: repo, entry: entry}, want: "42\t100755\tx"},
+		{name: "repo", format: "%H", r: result{repo: repo, entry: entry}, want: "cli/cli"},
+		{name: "blob SHA", format: "%i", r: result{repo: repo, entry: github.TreeEntry{Path: "go.mod", SHA: "8b4c2a1f"}}, want: "8b4c2a1f"},
+		{
+			name:   "repo with explicit ref",
+			format: "%H",
+			r:      result{repo: github.Repository{Owner: "cli", Name: "cli", Ref: "v2", ExplicitRef: true}, entry: entry},
+			want:   "cli/cli@v2",
+		},
+		{
+			name:   "mtime",
+			format: "%T@",
+			r:      result{repo: repo, entry: entry, commit: &github.FileCommitInfo{CommittedDate: date}},
+			want:   "1705314600",
+		},
+		{
+			name:   "author",
+			format: "%u",
+			r:      result{repo: repo, entry: entry, commit: &github.FileCommitInfo{Author: "jparise"}},
+			want:   "jparise",
+		},
+		{name: "mtime not fetched", format: "[%T@]", r: result{repo: repo, entry: entry}, want: "[]"},
+		{name: "author not fetched", format: "[%u]", r: result{repo: repo, entry: entry}, want: "[]"},
+		{name: "escapes", format: "%%p\\\\\\0", r: result{repo: repo, entry: entry}, want: "%p\\\x00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParsePrintf(tt.format)
+			if err != nil {
+				t.Fatalf("ParsePrintf(%q) error = %v", tt.format, err)
+			}
+			if got := p.expand(tt.r, tt.r.entry.Path, humanSize); got != tt.want {
+				t.Errorf("expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintfNeedsCommitDates(t *testing.T) {
+	tests := []struct {
+		format string
+		want   bool
+	}{
+		{format: "%p\\n", want: false},
+		{format: "%T@ %p\\n", want: true},
+		{format: "%u %p\\n", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			p, err := ParsePrintf(tt.format)
+			if err != nil {
+				t.Fatalf("ParsePrintf(%q) error = %v", tt.format, err)
+			}
+			if got := p.NeedsCommitDates(); got != tt.want {
+				t.Errorf("NeedsCommitDates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPrintf(t *testing.T) {
+	p, err := ParsePrintf("%H %p\\0")
+	if err != nil {
+		t.Fatalf("ParsePrintf() error = %v", err)
+	}
+	replacement, err := ParsePathReplacement("^src/=")
+	if err != nil {
+		t.Fatalf("ParsePathReplacement() error = %v", err)
+	}
+
+	stdout := &bytes.Buffer{}
+	output := NewOutput(stdout, &bytes.Buffer{}, OutputOptions{
+		Colorize:         true,
+		Printf:           p,
+		PathReplacements: []PathReplacement{replacement},
+	})
+
+	repo := github.Repository{Owner: "cli", Name: "cli", Ref: "trunk"}
+	output.Match(repo, github.TreeEntry{Path: "src/main.go"})
+	output.Match(repo, github.TreeEntry{Path: "README.md"})
+
+	want := "cli/cli main.go\x00cli/cli README.md\x00"
+	if got := stdout.String(); got != want {
+		t.Errorf("Match() output = %q, want %q", got, want)
+	}
+}
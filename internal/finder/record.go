@@ -0,0 +1,94 @@
+package finder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// Record is the machine-readable representation of a single match.
+type Record struct {
+	ID            string          `json:"id,omitempty"`
+	Owner         string          `json:"owner"`
+	Repo          string          `json:"repo"`
+	Ref           string          `json:"ref"`
+	Path          string          `json:"path"`
+	Size          int64           `json:"size"`
+	SizeHuman     string          `json:"size_human,omitempty"`
+	Mode          string          `json:"mode"`
+	Type          github.FileType `json:"type"`
+	SHA           string          `json:"sha,omitempty"`
+	URL           string          `json:"url"`
+	CommitURL     string          `json:"commit_url,omitempty"`
+	Mtime         string          `json:"mtime,omitempty"`
+	Author        string          `json:"author,omitempty"`
+	Preview       string          `json:"preview,omitempty"`
+	InheritedFrom string          `json:"inherited_from,omitempty"`
+}
+
+// NewRecord builds a Record for a tree entry within a repository.
+func NewRecord(repo github.Repository, entry github.TreeEntry) Record {
+	return Record{
+		Owner: repo.Owner,
+		Repo:  repo.Name,
+		Ref:   repo.Ref,
+		Path:  entry.Path,
+		Size:  entry.Size,
+		Mode:  entry.Mode,
+		Type:  github.ParseFileType(entry.Mode),
+		SHA:   entry.SHA,
+		URL:   fmt.Sprintf("%s/blob/%s/%s", repo.URL, repo.Revision(), entry.Path),
+	}
+}
+
+// NewInheritedRecord builds a Record for a community health file that repo
+// inherits from source. The ref, URL, and file details describe the file in
+// source.
+func NewInheritedRecord(repo, source github.Repository, entry github.TreeEntry) Record {
+	record := NewRecord(source, entry)
+	record.Owner = repo.Owner
+	record.Repo = repo.Name
+	record.InheritedFrom = source.FullName
+	return record
+}
+
+// commitURL returns the web URL of a commit in repo.
+func commitURL(repo github.Repository, oid string) string {
+	return fmt.Sprintf("%s/commit/%s", repo.URL, oid)
+}
+
+// rawURL returns the URL of a file's raw contents in repo. GitHub serves
+// them from raw.githubusercontent.com, and GitHub Enterprise Server from the
+// repository's raw endpoint. The ref and path are escaped so the URL can be
+// passed directly to tools like curl.
+func rawURL(repo github.Repository, filePath string) string {
+	escaped := escapePath(repo.Revision()) + "/" + escapePath(filePath)
+	if u, err := url.Parse(repo.URL); err == nil && u.Host == "github.com" {
+		return "https://raw.githubusercontent.com/" + repo.Owner + "/" + repo.Name + "/" + escaped
+	}
+	return repo.URL + "/raw/" + escaped
+}
+
+// escapePath escapes each segment of a slash-separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// recordIDLength is the number of hex characters kept from the ID hash.
+const recordIDLength = 16
+
+// RecordID returns a short, stable identifier for a match, derived from a
+// SHA-256 hash of "owner/repo@ref:path". The same match always produces the
+// same ID, so it can be used to deduplicate and join results across runs.
+func RecordID(owner, repo, ref, path string) string {
+	sum := sha256.Sum256([]byte(owner + "/" + repo + "@" + ref + ":" + path))
+	return hex.EncodeToString(sum[:])[:recordIDLength]
+}
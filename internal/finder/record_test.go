@@ -0,0 +1,127 @@
+package finder
+
+import (
+	"testing"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestNewRecord(t *testing.T) {
+	repo := github.Repository{
+		Owner: "cli",
+		Name:  "cli",
+		Ref:   "trunk",
+		URL:   "https://github.com/cli/cli",
+	}
+	entry := github.TreeEntry{Path: "script/build.sh", Mode: "100755", Size: 512, SHA: "0a1b2c3d4e5f"}
+
+	want := Record{
+		Owner: "cli",
+		Repo:  "cli",
+		Ref:   "trunk",
+		Path:  "script/build.sh",
+		Size:  512,
+		Mode:  "100755",
+		Type:  github.FileTypeExecutable,
+		SHA:   "0a1b2c3d4e5f",
+		URL:   "https://github.com/cli/cli/blob/trunk/script/build.sh",
+	}
+
+	if got := NewRecord(repo, entry); got != want {
+		t.Errorf("NewRecord() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecordID(t *testing.T) {
+	// The ID must never change across runs or releases, so pin a known value.
+	const want = "4504a3ef690f2b4c"
+	if got := RecordID("cli", "cli", "trunk", "main.go"); got != want {
+		t.Errorf("RecordID() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		name                   string
+		owner, repo, ref, path string
+	}{
+		{name: "different owner", owner: "octo", repo: "cli", ref: "trunk", path: "main.go"},
+		{name: "different repo", owner: "cli", repo: "go-gh", ref: "trunk", path: "main.go"},
+		{name: "different ref", owner: "cli", repo: "cli", ref: "v2.40.0", path: "main.go"},
+		{name: "different path", owner: "cli", repo: "cli", ref: "trunk", path: "cmd/main.go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RecordID(tt.owner, tt.repo, tt.ref, tt.path)
+			if got == want {
+				t.Errorf("RecordID(%q, %q, %q, %q) collides with base ID", tt.owner, tt.repo, tt.ref, tt.path)
+			}
+			if len(got) != recordIDLength {
+				t.Errorf("RecordID() length = %d, want %d", len(got), recordIDLength)
+			}
+		})
+	}
+}
+
+func TestCommitURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "github.com",
+			url:  "https://github.com/cli/cli",
+			want: "https://github.com/cli/cli/commit/8b4c2a1f0e",
+		},
+		{
+			name: "enterprise host",
+			url:  "https://github.example.com/cli/cli",
+			want: "https://github.example.com/cli/cli/commit/8b4c2a1f0e",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := github.Repository{Owner: "cli", Name: "cli", URL: tt.url}
+			if got := commitURL(repo, "8b4c2a1f0e"); got != tt.want {
+				t.Errorf("commitURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawURL(t *testing.T) {
+	tests := []struct {
+		name string
+		repo github.Repository
+		path string
+		want string
+	}{
+		{
+			name: "github.com",
+			repo: github.Repository{Owner: "cli", Name: "cli", Ref: "trunk", URL: "https://github.com/cli/cli"},
+			path: "pkg/cmd/root.go",
+			want: "https://raw.githubusercontent.com/cli/cli/trunk/pkg/cmd/root.go",
+		},
+		{
+			name: "escaped",
+			repo: github.Repository{Owner: "cli", Name: "cli", Ref: "feature/a#b", URL: "https://github.com/cli/cli"},
+			path: "docs/release notes?.md",
+			want: "https://raw.githubusercontent.com/cli/cli/feature/a%23b/docs/release%20notes%3F.md",
+		},
+		{
+			name: "enterprise server",
+			repo: github.Repository{Owner: "team", Name: "app", Ref: "main", URL: "https://ghe.example.com/team/app"},
+			path: "main.go",
+			want: "https://ghe.example.com/team/app/raw/main/main.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rawURL(tt.repo, tt.path); got != tt.want {
+				t.Errorf("rawURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package finder
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PathReplacement rewrites the displayed path of a match.
+type PathReplacement struct {
+	Pattern     *regexp.Regexp
+	Replacement string // May refer to submatches as $1 or ${name}
+}
+
+// ParsePathReplacement parses an "old=new" replacement, where old is a
+// regular expression. Literal paths work as-is, although '.' and other
+// metacharacters must be escaped to match only themselves.
+func ParsePathReplacement(s string) (PathReplacement, error) {
+	old, replacement, ok := strings.Cut(s, "=")
+	if !ok {
+		return PathReplacement{}, fmt.Errorf("invalid path replacement %q: expected old=new", s)
+	}
+	if old == "" {
+		return PathReplacement{}, fmt.Errorf("invalid path replacement %q: pattern is empty", s)
+	}
+
+	re, err := regexp.Compile(old)
+	if err != nil {
+		return PathReplacement{}, fmt.Errorf("invalid path replacement %q: %w", s, err)
+	}
+
+	return PathReplacement{Pattern: re, Replacement: replacement}, nil
+}
+
+// String returns the replacement in its "old=new" form.
+func (r PathReplacement) String() string {
+	return r.Pattern.String() + "=" + r.Replacement
+}
+
+// replacePath applies each replacement to path in order.
+func replacePath(path string, replacements []PathReplacement) string {
+	for _, r := range replacements {
+		path = r.Pattern.ReplaceAllString(path, r.Replacement)
+	}
+	return path
+}
@@ -0,0 +1,86 @@
+package finder
+
+import "testing"
+
+func TestParsePathReplacement(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "src/=", want: "src/="},
+		{input: "^lib/(.*)$=pkg/$1", want: "^lib/(.*)$=pkg/$1"},
+		{input: "a=b=c", want: "a=b=c"},
+		{input: "no-equals", wantErr: true},
+		{input: "=new", wantErr: true},
+		{input: "([a-z=x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePathReplacement(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePathReplacement(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("ParsePathReplacement(%q) = %q, want %q", tt.input, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestReplacePath(t *testing.T) {
+	tests := []struct {
+		name         string
+		replacements []string
+		path         string
+		want         string
+	}{
+		{
+			name: "no replacements",
+			path: "src/main.go",
+			want: "src/main.go",
+		},
+		{
+			name:         "literal",
+			replacements: []string{"src/="},
+			path:         "src/main.go",
+			want:         "main.go",
+		},
+		{
+			name:         "regex with submatch",
+			replacements: []string{`^packages/([^/]+)/src/=$1/`},
+			path:         "packages/core/src/index.js",
+			want:         "core/index.js",
+		},
+		{
+			name:         "applied in order",
+			replacements: []string{"^lib/=src/", "^src/=pkg/"},
+			path:         "lib/util.go",
+			want:         "pkg/util.go",
+		},
+		{
+			name:         "no match",
+			replacements: []string{"^vendor/="},
+			path:         "src/vendor/x.go",
+			want:         "src/vendor/x.go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var replacements []PathReplacement
+			for _, s := range tt.replacements {
+				r, err := ParsePathReplacement(s)
+				if err != nil {
+					t.Fatalf("ParsePathReplacement(%q) error = %v", s, err)
+				}
+				replacements = append(replacements, r)
+			}
+
+			if got := replacePath(tt.path, replacements); got != tt.want {
+				t.Errorf("replacePath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
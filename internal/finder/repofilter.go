@@ -0,0 +1,61 @@
+package finder
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// skipExpanded reports whether a repository found by expanding an owner
+// should be skipped because of the repository filters in opts. Repositories
+// that are named explicitly are never filtered.
+func skipExpanded(repo github.Repository, opts *Options) bool {
+	return excludedRepo(repo, opts.ExcludeRepos) ||
+		!hasTopics(repo, opts.RepoTopics) ||
+		!hasLanguage(repo, opts.RepoLanguages) ||
+		repo.Stars < opts.RepoMinStars ||
+		(opts.RepoPushedSince != nil && repo.PushedAt.Before(*opts.RepoPushedSince)) ||
+		(opts.RepoVisibility != "" && repo.Visibility != opts.RepoVisibility) ||
+		(opts.RepoMaxSize > 0 && int64(repo.Size)*1024 > opts.RepoMaxSize) ||
+		(opts.SkipTemplates && repo.IsTemplate)
+}
+
+// excludedRepo reports whether repo matches any of the exclude patterns.
+// Patterns containing a "/" are matched against the repository's full name,
+// and others against its name. Like GitHub names, matching ignores case.
+func excludedRepo(repo github.Repository, patterns []string) bool {
+	for _, pattern := range patterns {
+		name := repo.Name
+		if strings.Contains(pattern, "/") {
+			name = repo.FullName
+		}
+		if doublestar.MatchUnvalidated(strings.ToLower(pattern), strings.ToLower(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTopics reports whether repo has every one of topics. GitHub topics are
+// always lowercase, so they're compared ignoring case.
+func hasTopics(repo github.Repository, topics []string) bool {
+	for _, topic := range topics {
+		if !slices.Contains(repo.Topics, strings.ToLower(topic)) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLanguage reports whether repo's primary language is one of languages,
+// ignoring case. Any repository matches if languages is empty.
+func hasLanguage(repo github.Repository, languages []string) bool {
+	if len(languages) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(languages, func(language string) bool {
+		return strings.EqualFold(language, repo.Language)
+	})
+}
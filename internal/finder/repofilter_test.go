@@ -0,0 +1,126 @@
+package finder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestExcludedRepo(t *testing.T) {
+	repo := github.Repository{Owner: "octo", Name: "Infra-Archive", FullName: "octo/Infra-Archive"}
+
+	tests := []struct {
+		patterns []string
+		want     bool
+	}{
+		{nil, false},
+		{[]string{"*-archive"}, true},
+		{[]string{"sandbox-*", "infra-*"}, true},
+		{[]string{"octo/*"}, true},
+		{[]string{"other/*"}, false},
+		{[]string{"infra"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := excludedRepo(repo, tt.patterns); got != tt.want {
+			t.Errorf("excludedRepo(%v) = %v, want %v", tt.patterns, got, tt.want)
+		}
+	}
+}
+
+func TestHasTopics(t *testing.T) {
+	repo := github.Repository{Name: "infra", Topics: []string{"terraform", "aws"}}
+
+	tests := []struct {
+		topics []string
+		want   bool
+	}{
+		{nil, true},
+		{[]string{"terraform"}, true},
+		{[]string{"Terraform", "aws"}, true},
+		{[]string{"terraform", "gcp"}, false},
+		{[]string{"kubernetes"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := hasTopics(repo, tt.topics); got != tt.want {
+			t.Errorf("hasTopics(%v) = %v, want %v", tt.topics, got, tt.want)
+		}
+	}
+}
+
+func TestHasLanguage(t *testing.T) {
+	tests := []struct {
+		language  string
+		languages []string
+		want      bool
+	}{
+		{language: "Go", want: true},
+		{language: "", want: true},
+		{language: "Go", languages: []string{"go", "python"}, want: true},
+		{language: "Python", languages: []string{"go", "python"}, want: true},
+		{language: "Rust", languages: []string{"go", "python"}, want: false},
+		{language: "", languages: []string{"go"}, want: false},
+	}
+
+	for _, tt := range tests {
+		repo := github.Repository{Name: "app", Language: tt.language}
+		if got := hasLanguage(repo, tt.languages); got != tt.want {
+			t.Errorf("hasLanguage(%q, %v) = %v, want %v", tt.language, tt.languages, got, tt.want)
+		}
+	}
+}
+
+func TestSkipExpanded(t *testing.T) {
+	repo := github.Repository{
+		Name:       "infra",
+		FullName:   "octo/infra",
+		Topics:     []string{"terraform"},
+		Language:   "HCL",
+		Stars:      42,
+		Size:       1024,
+		PushedAt:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		Visibility: github.VisibilityPublic,
+	}
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		opts Options
+		want bool
+	}{
+		{name: "no filters", want: false},
+		{name: "excluded", opts: Options{ExcludeRepos: []string{"infra"}}, want: true},
+		{name: "missing topic", opts: Options{RepoTopics: []string{"aws"}}, want: true},
+		{name: "other language", opts: Options{RepoLanguages: []string{"go"}}, want: true},
+		{name: "enough stars", opts: Options{RepoMinStars: 42}, want: false},
+		{name: "too few stars", opts: Options{RepoMinStars: 43}, want: true},
+		{name: "same visibility", opts: Options{RepoVisibility: github.VisibilityPublic}, want: false},
+		{name: "other visibility", opts: Options{RepoVisibility: github.VisibilityPrivate}, want: true},
+		{name: "small enough", opts: Options{RepoMaxSize: 1 << 20}, want: false},
+		{name: "too large", opts: Options{RepoMaxSize: 1 << 19}, want: true},
+		{name: "not a template", opts: Options{SkipTemplates: true}, want: false},
+		{name: "pushed since", opts: Options{RepoPushedSince: &before}, want: false},
+		{name: "not pushed since", opts: Options{RepoPushedSince: &after}, want: true},
+		{
+			name: "all filters pass",
+			opts: Options{RepoTopics: []string{"terraform"}, RepoLanguages: []string{"hcl"}, RepoMinStars: 10},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := skipExpanded(repo, &tt.opts); got != tt.want {
+				t.Errorf("skipExpanded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	template := repo
+	template.IsTemplate = true
+	if !skipExpanded(template, &Options{SkipTemplates: true}) {
+		t.Errorf("skipExpanded() = false for a template with SkipTemplates set, want true")
+	}
+}
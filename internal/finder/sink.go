@@ -0,0 +1,165 @@
+package finder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// sinkBatchSize is the number of matches sent per POST request.
+	sinkBatchSize = 100
+	// sinkMaxAttempts is the number of times a batch is sent before giving up.
+	sinkMaxAttempts = 3
+)
+
+// RunInfo describes the search run and is included with every batch.
+type RunInfo struct {
+	StartedAt time.Time `json:"started_at"`
+	Patterns  []string  `json:"patterns"`
+	Repos     []string  `json:"repos"`
+}
+
+// sinkPayload is the JSON body of each POST request.
+type sinkPayload struct {
+	Run     RunInfo  `json:"run"`
+	Batch   int      `json:"batch"`
+	Matches []Record `json:"matches"`
+}
+
+// Sink batches matches and POSTs them as JSON to an HTTP endpoint.
+type Sink struct {
+	url    string
+	client *http.Client
+	run    RunInfo
+
+	mu      sync.Mutex // guards pending
+	pending []Record
+
+	sendMu  sync.Mutex // serializes requests so batches arrive in order
+	batches int
+	backoff time.Duration
+}
+
+// NewSink creates a Sink that posts batches of matches to url.
+func NewSink(url string, run RunInfo) *Sink {
+	return &Sink{
+		url:     url,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		run:     run,
+		backoff: time.Second,
+	}
+}
+
+// Add queues a match, sending a batch once enough matches have accumulated.
+func (s *Sink) Add(ctx context.Context, record Record) error {
+	s.mu.Lock()
+	s.pending = append(s.pending, record)
+	var batch []Record
+	if len(s.pending) >= sinkBatchSize {
+		batch = s.pending
+		s.pending = nil
+	}
+	s.mu.Unlock()
+
+	if batch == nil {
+		return nil
+	}
+	return s.send(ctx, batch)
+}
+
+// Flush sends any queued matches.
+func (s *Sink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return s.send(ctx, batch)
+}
+
+func (s *Sink) send(ctx context.Context, batch []Record) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	s.batches++
+	body, err := json.Marshal(sinkPayload{
+		Run:     s.run,
+		Batch:   s.batches,
+		Matches: batch,
+	})
+	if err != nil {
+		return err
+	}
+
+	backoff := s.backoff
+	for attempt := 1; ; attempt++ {
+		err = s.post(ctx, body)
+		if err == nil || !retryable(err) || attempt == sinkMaxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to post %d matches to %s: %w", len(batch), s.url, err)
+	}
+	return nil
+}
+
+func (s *Sink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &statusError{code: resp.StatusCode, status: resp.Status}
+	}
+	return nil
+}
+
+// statusError is returned by post when the endpoint responds with a status
+// other than 2xx.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return "unexpected status " + e.status
+}
+
+// retryable reports whether a failed post may succeed if it's sent again:
+// network errors, server errors, and rate limiting are retried, but other
+// responses, such as 400 Bad Request, would only fail again.
+func retryable(err error) bool {
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) {
+		return true
+	}
+	return statusErr.code >= 500 || statusErr.code == http.StatusTooManyRequests
+}
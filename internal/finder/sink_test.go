@@ -0,0 +1,175 @@
+package finder
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sinkServer records the payloads POSTed to it, failing the first failures
+// requests with status (500 Internal Server Error if unset).
+type sinkServer struct {
+	mu       sync.Mutex
+	payloads []sinkPayload
+	requests int
+	failures int
+	status   int
+}
+
+func (s *sinkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	if s.requests <= s.failures {
+		w.WriteHeader(cmp.Or(s.status, http.StatusInternalServerError))
+		return
+	}
+
+	var payload sinkPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.payloads = append(s.payloads, payload)
+}
+
+func TestSink(t *testing.T) {
+	tests := []struct {
+		name         string
+		count        int
+		failures     int
+		status       int
+		wantBatches  []int
+		wantRequests int
+		wantErr      bool
+	}{
+		{
+			name:         "no matches sends nothing",
+			count:        0,
+			wantBatches:  nil,
+			wantRequests: 0,
+		},
+		{
+			name:         "partial batch sent on flush",
+			count:        5,
+			wantBatches:  []int{5},
+			wantRequests: 1,
+		},
+		{
+			name:         "multiple batches",
+			count:        sinkBatchSize*2 + 1,
+			wantBatches:  []int{sinkBatchSize, sinkBatchSize, 1},
+			wantRequests: 3,
+		},
+		{
+			name:         "retries after failure",
+			count:        1,
+			failures:     1,
+			wantBatches:  []int{1},
+			wantRequests: 2,
+		},
+		{
+			name:         "retries when rate limited",
+			count:        1,
+			failures:     1,
+			status:       http.StatusTooManyRequests,
+			wantBatches:  []int{1},
+			wantRequests: 2,
+		},
+		{
+			name:         "client error is not retried",
+			count:        1,
+			failures:     1,
+			status:       http.StatusBadRequest,
+			wantBatches:  nil,
+			wantRequests: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "gives up after max attempts",
+			count:        1,
+			failures:     sinkMaxAttempts,
+			wantBatches:  nil,
+			wantRequests: sinkMaxAttempts,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &sinkServer{failures: tt.failures, status: tt.status}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+
+			run := RunInfo{
+				StartedAt: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+				Patterns:  []string{"*.go"},
+				Repos:     []string{"cli/cli"},
+			}
+			sink := NewSink(ts.URL, run)
+			sink.backoff = time.Millisecond
+
+			ctx := context.Background()
+			var err error
+			for i := range tt.count {
+				if err = sink.Add(ctx, Record{Path: fmt.Sprintf("file%d.go", i)}); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = sink.Flush(ctx)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sink error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if srv.requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", srv.requests, tt.wantRequests)
+			}
+
+			if len(srv.payloads) != len(tt.wantBatches) {
+				t.Fatalf("batches = %d, want %d", len(srv.payloads), len(tt.wantBatches))
+			}
+			for i, payload := range srv.payloads {
+				if payload.Batch != i+1 {
+					t.Errorf("payload[%d].Batch = %d, want %d", i, payload.Batch, i+1)
+				}
+				if len(payload.Matches) != tt.wantBatches[i] {
+					t.Errorf("payload[%d] has %d matches, want %d", i, len(payload.Matches), tt.wantBatches[i])
+				}
+				if !slices.Equal(payload.Run.Patterns, run.Patterns) || !payload.Run.StartedAt.Equal(run.StartedAt) {
+					t.Errorf("payload[%d].Run = %+v, want %+v", i, payload.Run, run)
+				}
+			}
+		})
+	}
+}
+
+func TestSink_ContextCanceled(t *testing.T) {
+	srv := &sinkServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	sink := NewSink(ts.URL, RunInfo{})
+	if err := sink.Add(ctx, Record{Path: "main.go"}); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if err := sink.Flush(ctx); err == nil {
+		t.Error("expected error with canceled context")
+	}
+	if srv.requests != 0 {
+		t.Errorf("requests = %d, want 0", srv.requests)
+	}
+}
@@ -0,0 +1,111 @@
+package finder
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// SortKey selects the order in which matches are written.
+type SortKey string
+
+const (
+	// SortNone writes matches as soon as they're found.
+	SortNone SortKey = ""
+	// SortPath orders matches by path, then repository.
+	SortPath SortKey = "path"
+	// SortSize orders matches from largest to smallest.
+	SortSize SortKey = "size"
+	// SortMtime orders matches from most to least recently changed. Matches
+	// without a known commit date come last, even when reversed.
+	SortMtime SortKey = "mtime"
+	// SortRepo orders matches by repository, then path.
+	SortRepo SortKey = "repo"
+)
+
+// RepoSort selects the order in which an owner's repositories are searched.
+type RepoSort string
+
+const (
+	// RepoSortNone searches repositories in the order GitHub lists them.
+	RepoSortNone RepoSort = ""
+	// RepoSortPushed searches the most recently pushed repositories first.
+	RepoSortPushed RepoSort = "pushed"
+	// RepoSortStars searches the most starred repositories first.
+	RepoSortStars RepoSort = "stars"
+	// RepoSortName searches repositories in name order.
+	RepoSortName RepoSort = "name"
+)
+
+// ValidRepoSorts is the list of valid --repo-sort values.
+var ValidRepoSorts = []string{
+	string(RepoSortPushed),
+	string(RepoSortStars),
+	string(RepoSortName),
+}
+
+// ValidSortKeys is the list of valid --sort values.
+var ValidSortKeys = []string{
+	string(SortPath),
+	string(SortSize),
+	string(SortMtime),
+	string(SortRepo),
+}
+
+// sortResults sorts results in place by key, reversing the order if reverse
+// is set, except that SortMtime keeps undated matches last. Ties are broken
+// by repository and then path so the order is deterministic.
+func sortResults(results []result, key SortKey, reverse bool) {
+	byRepoPath := func(a, b result) int {
+		return cmp.Or(
+			strings.Compare(strings.ToLower(a.repo.FullName), strings.ToLower(b.repo.FullName)),
+			strings.Compare(a.repo.Ref, b.repo.Ref),
+			strings.Compare(a.entry.Path, b.entry.Path),
+		)
+	}
+
+	switch key {
+	case SortNone:
+		return
+	case SortPath:
+		slices.SortStableFunc(results, func(a, b result) int {
+			return cmp.Or(strings.Compare(a.entry.Path, b.entry.Path), byRepoPath(a, b))
+		})
+	case SortSize:
+		slices.SortStableFunc(results, func(a, b result) int {
+			return cmp.Or(cmp.Compare(b.entry.Size, a.entry.Size), byRepoPath(a, b))
+		})
+	case SortMtime:
+		// Reverse here rather than below so that matches without a commit
+		// date still come last.
+		slices.SortStableFunc(results, func(a, b result) int {
+			c := cmp.Or(compareCommitDates(b, a), byRepoPath(a, b))
+			if reverse && (a.commit == nil) == (b.commit == nil) {
+				return -c
+			}
+			return c
+		})
+		return
+	case SortRepo:
+		slices.SortStableFunc(results, byRepoPath)
+	}
+
+	if reverse {
+		slices.Reverse(results)
+	}
+}
+
+// compareCommitDates compares the last commit dates of two results. A result
+// without a known date sorts before one with a date.
+func compareCommitDates(a, b result) int {
+	switch {
+	case a.commit == nil && b.commit == nil:
+		return 0
+	case a.commit == nil:
+		return -1
+	case b.commit == nil:
+		return 1
+	default:
+		return a.commit.CommittedDate.Compare(b.commit.CommittedDate)
+	}
+}
@@ -0,0 +1,55 @@
+package finder
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestSortResults(t *testing.T) {
+	a := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a"}
+	b := github.Repository{Owner: "octo", Name: "b", FullName: "octo/b"}
+
+	older := &github.FileCommitInfo{CommittedDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	newer := &github.FileCommitInfo{CommittedDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)}
+
+	results := []result{
+		{repo: b, entry: github.TreeEntry{Path: "main.go", Size: 100}, commit: older},
+		{repo: a, entry: github.TreeEntry{Path: "util.go", Size: 300}, commit: newer},
+		{repo: a, entry: github.TreeEntry{Path: "main.go", Size: 100}},
+		{repo: b, entry: github.TreeEntry{Path: "big.go", Size: 900}, commit: older},
+	}
+
+	tests := []struct {
+		name    string
+		key     SortKey
+		reverse bool
+		want    []string
+	}{
+		{name: "none", key: SortNone, want: []string{"octo/b:main.go", "octo/a:util.go", "octo/a:main.go", "octo/b:big.go"}},
+		{name: "path", key: SortPath, want: []string{"octo/b:big.go", "octo/a:main.go", "octo/b:main.go", "octo/a:util.go"}},
+		{name: "size", key: SortSize, want: []string{"octo/b:big.go", "octo/a:util.go", "octo/a:main.go", "octo/b:main.go"}},
+		{name: "mtime", key: SortMtime, want: []string{"octo/a:util.go", "octo/b:big.go", "octo/b:main.go", "octo/a:main.go"}},
+		{name: "repo", key: SortRepo, want: []string{"octo/a:main.go", "octo/a:util.go", "octo/b:big.go", "octo/b:main.go"}},
+		{name: "reverse size", key: SortSize, reverse: true, want: []string{"octo/b:main.go", "octo/a:main.go", "octo/a:util.go", "octo/b:big.go"}},
+		{name: "reverse mtime", key: SortMtime, reverse: true, want: []string{"octo/b:main.go", "octo/b:big.go", "octo/a:util.go", "octo/a:main.go"}},
+		{name: "reverse none", key: SortNone, reverse: true, want: []string{"octo/b:main.go", "octo/a:util.go", "octo/a:main.go", "octo/b:big.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := slices.Clone(results)
+			sortResults(sorted, tt.key, tt.reverse)
+
+			got := make([]string, len(sorted))
+			for i, r := range sorted {
+				got[i] = r.repo.FullName + ":" + r.entry.Path
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sortResults(%q, %v) = %v, want %v", tt.key, tt.reverse, got, tt.want)
+			}
+		})
+	}
+}
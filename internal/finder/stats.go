@@ -0,0 +1,44 @@
+package finder
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// runStats collects the numbers reported by --stats. It is safe for
+// concurrent use.
+type runStats struct {
+	start     time.Time
+	searched  atomic.Int64 // repositories searched
+	failed    atomic.Int64 // repositories that couldn't be searched
+	missing   atomic.Int64 // repositories that couldn't be fetched to search
+	truncated atomic.Int64 // repositories whose trees were truncated
+	matches   atomic.Int64 // matches found, including any not written
+	bytes     atomic.Int64 // total size of the matched files
+}
+
+// addResults counts a repository's matches and their total size.
+func (s *runStats) addResults(results []result) {
+	var size int64
+	for _, r := range results {
+		if hasSize(r.entry) {
+			size += r.entry.Size
+		}
+	}
+	s.matches.Add(int64(len(results)))
+	s.bytes.Add(size)
+}
+
+// format returns the summary written to stderr. requests is the number of
+// API requests made during the run.
+func (s *runStats) format(requests int64, elapsed time.Duration) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Repositories: %d searched, %d failed, %d truncated\n",
+		s.searched.Load(), s.failed.Load()+s.missing.Load(), s.truncated.Load())
+	fmt.Fprintf(&b, "Matches:      %d (%d bytes)\n", s.matches.Load(), s.bytes.Load())
+	fmt.Fprintf(&b, "API requests: %d\n", requests)
+	fmt.Fprintf(&b, "Elapsed:      %s", elapsed.Round(time.Millisecond))
+	return b.String()
+}
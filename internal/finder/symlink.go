@@ -0,0 +1,43 @@
+package finder
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// filterBySymlinkTarget keeps the symlinks whose target matches any of
+// patterns. A symlink's blob holds its target, so each one is fetched.
+// Symlinks whose target can't be fetched are reported and skipped.
+func (f *Finder) filterBySymlinkTarget(ctx context.Context, repo github.Repository, entries []github.TreeEntry, patterns []string) ([]github.TreeEntry, error) {
+	if len(patterns) == 0 {
+		return entries, nil
+	}
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		if github.ParseFileType(entry.Mode) != github.FileTypeSymlink {
+			continue
+		}
+
+		data, err := f.client.GetBlob(ctx, repo, entry.SHA)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			f.output.Warningf("%s: %s: %v", repo.FullName, entry.Path, err)
+			continue
+		}
+
+		target := string(data)
+		if slices.ContainsFunc(patterns, func(pattern string) bool {
+			return doublestar.MatchUnvalidated(pattern, target)
+		}) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered, nil
+}
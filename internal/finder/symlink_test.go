@@ -0,0 +1,52 @@
+package finder
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jparise/gh-find/internal/github"
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestFilterBySymlinkTarget(t *testing.T) {
+	f, _, stderr := testFinder(t)
+
+	repo := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a", Ref: "main"}
+	entries := []github.TreeEntry{
+		{Path: "bin/tool", Mode: "120000", SHA: "aaa"},
+		{Path: "docs/link", Mode: "120000", SHA: "bbb"},
+		{Path: "broken", Mode: "120000", SHA: "ccc"},
+		{Path: "main.go", Mode: "100644", SHA: "ddd"},
+	}
+
+	// base64("/usr/local/bin/tool") and base64("../README.md")
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/blobs/aaa").
+		Reply(200).
+		JSON(`{"encoding": "base64", "content": "L3Vzci9sb2NhbC9iaW4vdG9vbA=="}`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/blobs/bbb").
+		Reply(200).
+		JSON(`{"encoding": "base64", "content": "Li4vUkVBRE1FLm1k"}`)
+	gock.New("https://api.github.com").
+		Get("/repos/octo/a/git/blobs/ccc").
+		Reply(404).
+		JSON(`{"message": "Not Found"}`)
+
+	got, err := f.filterBySymlinkTarget(context.Background(), repo, entries, []string{"/usr/local/**"})
+	if err != nil {
+		t.Fatalf("filterBySymlinkTarget() error = %v", err)
+	}
+
+	if paths := treePaths(got); !slices.Equal(paths, []string{"bin/tool"}) {
+		t.Errorf("filterBySymlinkTarget() = %v, want [bin/tool]", paths)
+	}
+	if !strings.Contains(stderr.String(), "broken") {
+		t.Errorf("stderr = %q, want a warning about broken", stderr.String())
+	}
+	if !gock.IsDone() {
+		t.Errorf("not all mocks were called: %v", gock.Pending())
+	}
+}
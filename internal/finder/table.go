@@ -0,0 +1,60 @@
+package finder
+
+import (
+	"encoding/csv"
+	"strconv"
+	"time"
+)
+
+// tableHeader is the header row of CSV and TSV output.
+var tableHeader = []string{"repo", "ref", "path", "size", "type", "mtime", "url"}
+
+// writeRow writes a result as a CSV or TSV row, preceded by the header if
+// this is the first row. Rows are flushed as they're written so that output
+// streams like text output does. The caller must hold o.mu.
+func (o *Output) writeRow(r result) {
+	o.writeTableHeader()
+	o.table.Write(tableRow(o.record(r), r))
+	o.table.Flush()
+}
+
+// writeTableHeader writes the header row if it hasn't been written yet. TSV
+// fields are quoted as needed using the same rules as CSV. The caller must
+// hold o.mu.
+func (o *Output) writeTableHeader() {
+	if o.table != nil {
+		return
+	}
+	o.table = csv.NewWriter(o.stdout)
+	if o.format == FormatTSV {
+		o.table.Comma = '\t'
+	}
+	o.table.Write(tableHeader)
+}
+
+// flushTable writes the header if there were no matches and flushes any
+// buffered rows. The caller must hold o.mu.
+func (o *Output) flushTable() error {
+	o.writeTableHeader()
+	o.table.Flush()
+	return o.table.Error()
+}
+
+// tableRow returns the fields of a CSV or TSV row. The modification time is
+// the date of the last commit to change the file, which is only known when
+// commit dates were fetched.
+func tableRow(record Record, r result) []string {
+	var mtime string
+	if r.commit != nil {
+		mtime = r.commit.CommittedDate.UTC().Format(time.RFC3339)
+	}
+	return []string{
+		record.Owner + "/" + record.Repo,
+		record.Ref,
+		record.Path,
+		strconv.FormatInt(record.Size, 10),
+		string(record.Type),
+		mtime,
+		record.URL,
+	}
+}
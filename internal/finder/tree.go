@@ -0,0 +1,120 @@
+package finder
+
+import (
+	"io"
+	"slices"
+	"strings"
+)
+
+// treeNode is a path component in a tree of matches. A node is a match if
+// it has a result; other nodes are directories leading to matches.
+type treeNode struct {
+	name     string
+	result   *result
+	children map[string]*treeNode
+}
+
+// child returns the child with the given name, creating it if necessary.
+func (n *treeNode) child(name string) *treeNode {
+	if n.children == nil {
+		n.children = make(map[string]*treeNode)
+	}
+	c, ok := n.children[name]
+	if !ok {
+		c = &treeNode{name: name}
+		n.children[name] = c
+	}
+	return c
+}
+
+// buildTree builds a tree of results keyed by their displayed paths.
+func buildTree(results []result, replace []PathReplacement) *treeNode {
+	root := &treeNode{}
+	for i := range results {
+		node := root
+		for name := range strings.SplitSeq(replacePath(results[i].entry.Path, replace), "/") {
+			node = node.child(name)
+		}
+		node.result = &results[i]
+	}
+	root.collapse()
+	return root
+}
+
+// collapse merges each directory that isn't a match and has a single child
+// with that child, so a/b/c.go is shown on one line rather than three.
+func (n *treeNode) collapse() {
+	for key, c := range n.children {
+		for c.result == nil && len(c.children) == 1 {
+			for _, only := range c.children {
+				only.name = c.name + "/" + only.name
+				c = only
+			}
+		}
+		n.children[key] = c
+		c.collapse()
+	}
+}
+
+// writeTreeGroup writes a repository's results as a tree below a heading,
+// like tree(1). Matches are listed in path order, and directories that only
+// lead to a single match are collapsed into its line.
+func (o *Output) writeTreeGroup(results []result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.matched += len(results)
+	n := o.reserve(len(results))
+	if n == 0 {
+		return
+	}
+
+	var buf strings.Builder
+	buf.WriteString(o.groupHeading(results[0].repo))
+	o.formatTree(&buf, buildTree(results[:n], o.replace), "")
+	io.WriteString(o.stdout, buf.String())
+}
+
+// formatTree writes the children of node, each preceded by prefix and a
+// branch connector. Matches are annotated like text output.
+func (o *Output) formatTree(buf *strings.Builder, node *treeNode, prefix string) {
+	names := make([]string, 0, len(node.children))
+	for name := range node.children {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for i, name := range names {
+		c := node.children[name]
+		connector, indent := "├── ", "│   "
+		if i == len(names)-1 {
+			connector, indent = "└── ", "    "
+		}
+
+		// Nodes without a result are directories leading to matches.
+		var color func(string) string
+		if c.result != nil {
+			color = o.nameColor(c.result.entry)
+		} else if o.colors != nil {
+			color = colorFunc(o.colors.types["di"])
+		}
+		if color == nil {
+			color = o.white
+		}
+
+		label := color(c.name)
+		if c.result != nil {
+			label = o.annotate(*c.result, label)
+		}
+		if o.icons {
+			icon := iconDirectory
+			if c.result != nil {
+				icon = fileIcon(c.result.entry)
+			}
+			label = icon + " " + label
+		}
+		buf.WriteString(prefix + connector + label + "\n")
+
+		o.formatTree(buf, c, prefix+indent)
+	}
+}
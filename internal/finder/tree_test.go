@@ -0,0 +1,105 @@
+package finder
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestWriteTreeGroup(t *testing.T) {
+	a := github.Repository{Owner: "octo", Name: "a", FullName: "octo/a"}
+	b := github.Repository{Owner: "octo", Name: "b", FullName: "octo/b"}
+
+	paths := func(repo github.Repository, paths ...string) []result {
+		results := make([]result, len(paths))
+		for i, p := range paths {
+			results[i] = result{repo: repo, entry: github.TreeEntry{Path: p}}
+		}
+		return results
+	}
+
+	tests := []struct {
+		name    string
+		opts    OutputOptions
+		results [][]result
+		want    string
+	}{
+		{
+			name: "nested",
+			results: [][]result{paths(a,
+				"main.go",
+				"cmd/root.go",
+				"cmd/root_test.go",
+				"internal/finder/finder.go",
+				"internal/finder/output.go",
+				"internal/github/client.go",
+			)},
+			want: "octo/a\n" +
+				"├── cmd\n" +
+				"│   ├── root.go\n" +
+				"│   └── root_test.go\n" +
+				"├── internal\n" +
+				"│   ├── finder\n" +
+				"│   │   ├── finder.go\n" +
+				"│   │   └── output.go\n" +
+				"│   └── github/client.go\n" +
+				"└── main.go\n",
+		},
+		{
+			name:    "collapsed prefix",
+			results: [][]result{paths(a, "docs/guide/intro.md", "docs/guide/setup.md")},
+			want:    "octo/a\n└── docs/guide\n    ├── intro.md\n    └── setup.md\n",
+		},
+		{
+			name:    "matched directory is not collapsed",
+			results: [][]result{paths(a, "docs", "docs/guide/intro.md")},
+			want:    "octo/a\n└── docs\n    └── guide/intro.md\n",
+		},
+		{
+			name:    "multiple repositories",
+			results: [][]result{paths(a, "a.go"), nil, paths(b, "b.go")},
+			want:    "octo/a\n└── a.go\n\nocto/b\n└── b.go\n",
+		},
+		{
+			name:    "max results",
+			opts:    OutputOptions{MaxResults: 2},
+			results: [][]result{paths(a, "z.go", "y.go", "x.go"), paths(b, "b.go")},
+			want:    "octo/a\n├── y.go\n└── z.go\n",
+		},
+		{
+			name: "path replacements",
+			opts: OutputOptions{PathReplacements: []PathReplacement{
+				mustParsePathReplacement(t, "^src/="),
+			}},
+			results: [][]result{paths(a, "src/main.go", "src/util.go")},
+			want:    "octo/a\n├── main.go\n└── util.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			opts := tt.opts
+			opts.Tree = true
+			output := NewOutput(stdout, &bytes.Buffer{}, opts)
+
+			for _, results := range tt.results {
+				output.writeAll(results)
+			}
+
+			if got := stdout.String(); got != tt.want {
+				t.Errorf("writeAll() output =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustParsePathReplacement(t *testing.T, s string) PathReplacement {
+	t.Helper()
+	r, err := ParsePathReplacement(s)
+	if err != nil {
+		t.Fatalf("ParsePathReplacement(%q) error = %v", s, err)
+	}
+	return r
+}
@@ -0,0 +1,64 @@
+package finder
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+// windowsMaxPath is the longest path Windows supports without long path
+// support enabled (MAX_PATH).
+const windowsMaxPath = 260
+
+// windowsInvalidChars are the characters Windows doesn't allow in names.
+const windowsInvalidChars = `:*?"<>|`
+
+// windowsReservedNames are the device names Windows reserves, with or
+// without an extension and regardless of case.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// windowsUnsafe reports whether p can't be checked out on Windows: it's
+// longer than MAX_PATH, or one of its components contains an invalid or
+// control character, is a reserved device name, or ends in a dot or space.
+func windowsUnsafe(p string) bool {
+	if utf8.RuneCountInString(p) > windowsMaxPath {
+		return true
+	}
+
+	for name := range strings.SplitSeq(p, "/") {
+		if strings.ContainsAny(name, windowsInvalidChars) || strings.ContainsFunc(name, func(r rune) bool { return r < 0x20 }) {
+			return true
+		}
+		if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+			return true
+		}
+		// Windows ignores everything from the first dot, so "nul.tar.gz" is
+		// reserved too.
+		base, _, _ := strings.Cut(name, ".")
+		if windowsReservedNames[strings.ToUpper(base)] {
+			return true
+		}
+	}
+	return false
+}
+
+func filterByWindowsUnsafe(entries []github.TreeEntry, enabled bool) []github.TreeEntry {
+	if !enabled {
+		return entries
+	}
+
+	var filtered []github.TreeEntry
+	for _, entry := range entries {
+		if windowsUnsafe(entry.Path) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
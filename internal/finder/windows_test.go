@@ -0,0 +1,63 @@
+package finder
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jparise/gh-find/internal/github"
+)
+
+func TestWindowsUnsafe(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "src/main.go", want: false},
+		{path: "docs/console.md", want: false},
+		{path: ".github/workflows/ci.yml", want: false},
+		{path: "notes/2024-01-01T10:00.md", want: true},
+		{path: "what?.txt", want: true},
+		{path: `say "hi".txt`, want: true},
+		{path: "a<b>c", want: true},
+		{path: "pipe|name", want: true},
+		{path: "glob*", want: true},
+		{path: "bell\a", want: true},
+		{path: "con", want: true},
+		{path: "drivers/NUL.txt", want: true},
+		{path: "Aux.tar.gz/readme", want: true},
+		{path: "com1", want: true},
+		{path: "com10", want: false},
+		{path: "trailing./file", want: true},
+		{path: "trailing ", want: true},
+		{path: strings.Repeat("a/", 130), want: false},
+		{path: strings.Repeat("a/", 130) + "b", want: true},
+		{path: strings.Repeat("é", 260), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := windowsUnsafe(tt.path); got != tt.want {
+				t.Errorf("windowsUnsafe(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterByWindowsUnsafe(t *testing.T) {
+	entries := []github.TreeEntry{
+		{Path: "main.go"},
+		{Path: "aux.go"},
+		{Path: "notes/a:b.md"},
+	}
+
+	got := filterByWindowsUnsafe(entries, true)
+	want := []string{"aux.go", "notes/a:b.md"}
+	if !slices.Equal(treePaths(got), want) {
+		t.Errorf("got %v, want %v", treePaths(got), want)
+	}
+
+	if got := filterByWindowsUnsafe(entries, false); len(got) != len(entries) {
+		t.Errorf("filterByWindowsUnsafe(false) kept %d entries, want %d", len(got), len(entries))
+	}
+}
@@ -0,0 +1,120 @@
+// Package gitattributes parses the subset of .gitattributes syntax needed to
+// look up attribute values for repository paths.
+//
+// Supported syntax:
+//   - Blank lines and lines starting with "#" are ignored
+//   - Each line is a pattern followed by whitespace-separated attributes
+//   - Attributes may be set ("attr"), unset ("-attr"), unspecified ("!attr"),
+//     or given a value ("attr=value")
+//   - Patterns without a slash match the basename at any depth; patterns with
+//     a slash match the full path relative to the repository root
+//   - Glob syntax, including "**", follows gitignore-style matching
+//
+// Not supported: quoted patterns, macro definitions ("[attr]name"), and
+// patterns ending in "/" (which git also ignores in attribute files).
+package gitattributes
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// State values returned by Lookup for attributes without an explicit value.
+const (
+	// Set means the attribute was listed without a value ("attr").
+	Set = "true"
+	// Unset means the attribute was negated ("-attr").
+	Unset = "false"
+)
+
+type rule struct {
+	pattern  string
+	basename bool              // match only the basename
+	attrs    map[string]string // value, or "" for unspecified ("!attr")
+}
+
+// Attributes holds the parsed rules of a .gitattributes file.
+type Attributes struct {
+	rules []rule
+}
+
+// Parse parses the contents of a .gitattributes file. Malformed lines are
+// skipped rather than reported, matching git's lenient behavior.
+func Parse(data []byte) *Attributes {
+	attrs := &Attributes{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		pattern := fields[0]
+		if strings.HasPrefix(pattern, "[attr]") || strings.HasPrefix(pattern, `"`) || strings.HasSuffix(pattern, "/") {
+			continue
+		}
+
+		r := rule{
+			pattern:  strings.TrimPrefix(pattern, "/"),
+			basename: !strings.Contains(pattern, "/"),
+			attrs:    make(map[string]string, len(fields)-1),
+		}
+
+		for _, field := range fields[1:] {
+			switch {
+			case strings.HasPrefix(field, "-"):
+				r.attrs[field[1:]] = Unset
+			case strings.HasPrefix(field, "!"):
+				r.attrs[field[1:]] = ""
+			default:
+				name, value, hasValue := strings.Cut(field, "=")
+				if !hasValue {
+					value = Set
+				}
+				r.attrs[name] = value
+			}
+		}
+
+		attrs.rules = append(attrs.rules, r)
+	}
+
+	return attrs
+}
+
+// Lookup returns the value of attr for the given path. Later rules take
+// precedence over earlier ones. The second return value is false if no rule
+// specifies the attribute.
+func (a *Attributes) Lookup(p, attr string) (string, bool) {
+	for i := len(a.rules) - 1; i >= 0; i-- {
+		r := a.rules[i]
+		value, ok := r.attrs[attr]
+		if !ok || !r.matches(p) {
+			continue
+		}
+		if value == "" {
+			return "", false // explicitly unspecified
+		}
+		return value, true
+	}
+	return "", false
+}
+
+// IsSet reports whether attr is set (or set to "true") for the given path.
+func (a *Attributes) IsSet(p, attr string) bool {
+	value, ok := a.Lookup(p, attr)
+	return ok && value == Set
+}
+
+func (r rule) matches(p string) bool {
+	if r.basename {
+		p = path.Base(p)
+	}
+	matched, err := doublestar.Match(r.pattern, p)
+	return err == nil && matched
+}
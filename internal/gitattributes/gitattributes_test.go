@@ -0,0 +1,76 @@
+package gitattributes
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	data := []byte(`# Generated and vendored code
+*.min.js          linguist-generated
+/dist/**          linguist-generated=true
+vendor/**         linguist-vendored
+vendor/keep/**    -linguist-vendored
+docs/*.md         linguist-documentation
+*.pb.go           linguist-generated !linguist-vendored
+"quoted path"     linguist-generated
+[attr]mymacro     linguist-generated
+build/            linguist-generated
+`)
+
+	attrs := Parse(data)
+
+	tests := []struct {
+		name      string
+		path      string
+		attr      string
+		wantValue string
+		wantOK    bool
+	}{
+		{name: "basename pattern at root", path: "app.min.js", attr: "linguist-generated", wantValue: Set, wantOK: true},
+		{name: "basename pattern nested", path: "web/static/app.min.js", attr: "linguist-generated", wantValue: Set, wantOK: true},
+		{name: "explicit true value", path: "dist/bundle.js", attr: "linguist-generated", wantValue: "true", wantOK: true},
+		{name: "anchored pattern not nested", path: "web/dist/bundle.js", attr: "linguist-generated", wantOK: false},
+		{name: "doublestar directory", path: "vendor/github.com/pkg/errors/errors.go", attr: "linguist-vendored", wantValue: Set, wantOK: true},
+		{name: "later rule unsets", path: "vendor/keep/file.go", attr: "linguist-vendored", wantValue: Unset, wantOK: true},
+		{name: "single star does not cross directories", path: "docs/api/ref.md", attr: "linguist-documentation", wantOK: false},
+		{name: "unspecified attribute", path: "vendor/gen.pb.go", attr: "linguist-vendored", wantOK: false},
+		{name: "other attribute on same line", path: "api/gen.pb.go", attr: "linguist-generated", wantValue: Set, wantOK: true},
+		{name: "no matching rule", path: "main.go", attr: "linguist-generated", wantOK: false},
+		{name: "trailing slash pattern ignored", path: "build/out.js", attr: "linguist-generated", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := attrs.Lookup(tt.path, tt.attr)
+			if ok != tt.wantOK || value != tt.wantValue {
+				t.Errorf("Lookup(%q, %q) = %q, %v; want %q, %v", tt.path, tt.attr, value, ok, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsSet(t *testing.T) {
+	attrs := Parse([]byte("*.js linguist-generated\nlib/*.js linguist-generated=false\n"))
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "app.js", want: true},
+		{path: "lib/util.js", want: false},
+		{path: "main.go", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := attrs.IsSet(tt.path, "linguist-generated"); got != tt.want {
+				t.Errorf("IsSet(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	attrs := Parse(nil)
+	if attrs.IsSet("main.go", "linguist-generated") {
+		t.Error("empty attributes reported an attribute as set")
+	}
+}
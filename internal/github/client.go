@@ -3,10 +3,18 @@ package github
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
 )
 
 // OwnerType represents the type of account owner (User or Organization).
@@ -21,29 +29,61 @@ const (
 	pageSize = 100
 )
 
+// ErrEmptyRepository is returned by GetRepo for a repository with no commits.
+var ErrEmptyRepository = errors.New("repository is empty (no commits yet)")
+
 // ClientOptions configures the GitHub API client.
 type ClientOptions struct {
 	AuthToken    string
+	Host         string // API host (empty = gh's default host)
 	CacheDir     string
 	CacheTTL     time.Duration
 	DisableCache bool
+	CACertFile   string // PEM bundle of additional trusted CAs for Host
+	Insecure     bool   // Skip TLS certificate verification for Host
 }
 
 // Client wraps the go-gh REST and GraphQL clients.
 type Client struct {
-	rest    *api.RESTClient
-	graphql *api.GraphQLClient
+	rest       *api.RESTClient
+	raw        *api.RESTClient // uncached, for reading part of a blob's raw content
+	graphql    *api.GraphQLClient
+	requests   *countingTransport
+	retryDelay time.Duration // initial backoff between retries
 }
 
 // NewClient creates a new GitHub API client with the given options.
 func NewClient(opts ClientOptions) (*Client, error) {
 	apiOpts := api.ClientOptions{
 		AuthToken:   opts.AuthToken,
+		Host:        opts.Host,
 		CacheDir:    opts.CacheDir,
 		CacheTTL:    opts.CacheTTL,
 		EnableCache: !opts.DisableCache,
 	}
 
+	requests := &countingTransport{}
+	apiOpts.Transport = requests
+
+	if opts.CACertFile != "" || opts.Insecure {
+		custom, err := newTLSTransport(opts.CACertFile, opts.Insecure)
+		if err != nil {
+			return nil, err
+		}
+
+		host := opts.Host
+		if host == "" {
+			host, _ = auth.DefaultHost()
+		}
+
+		// Only requests to the configured host use the custom TLS settings.
+		requests.base = &hostTransport{
+			host:     auth.NormalizeHostname(host),
+			custom:   custom,
+			fallback: http.DefaultTransport,
+		}
+	}
+
 	rest, err := api.NewRESTClient(apiOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
@@ -54,12 +94,38 @@ func NewClient(opts ClientOptions) (*Client, error) {
 		return nil, fmt.Errorf("failed to create GitHub GraphQL client: %w", err)
 	}
 
+	// The cache reads every response in full to store it, so raw content is
+	// fetched without it, letting a prefix be read without the rest.
+	rawOpts := apiOpts
+	rawOpts.EnableCache = false
+	rawOpts.Headers = map[string]string{"Accept": "application/vnd.github.raw+json"}
+	rawOpts.Transport = &rangeTransport{base: requests}
+	raw, err := api.NewRESTClient(rawOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+
 	return &Client{
-		rest:    rest,
-		graphql: graphql,
+		rest:       rest,
+		raw:        raw,
+		graphql:    graphql,
+		requests:   requests,
+		retryDelay: time.Second,
 	}, nil
 }
 
+// Requests returns the number of API requests the client has sent. Responses
+// served from the local cache aren't counted.
+func (c *Client) Requests() int64 {
+	return c.requests.count.Load()
+}
+
+// IsNotFound reports whether err is the result of a 404 Not Found response.
+func IsNotFound(err error) bool {
+	var httpErr *api.HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
 // GetOwnerType determines if a name is a "User" or "Organization".
 func (c *Client) GetOwnerType(ctx context.Context, name string) (OwnerType, error) {
 	var result struct {
@@ -75,19 +141,19 @@ func (c *Client) GetOwnerType(ctx context.Context, name string) (OwnerType, erro
 	return result.Type, nil
 }
 
-// ListRepos returns all repositories for a user or organization with pagination.
-// It detects whether the name is a user or org and uses the appropriate endpoint.
-func (c *Client) ListRepos(ctx context.Context, name string, types RepoTypes) ([]Repository, error) {
+// WalkRepos calls fn with each page of repositories for a user or organization
+// as it is fetched, allowing callers to start work before pagination finishes.
+// Pages are filtered by type before fn is called, and empty pages are skipped.
+// Walking stops early if fn returns an error. Repositories are listed in the
+// API's default order unless sort is set to one of the API's sort values,
+// such as "pushed" (most recently pushed first) or "full_name".
+func (c *Client) WalkRepos(ctx context.Context, name string, types RepoTypes, sort string, fn func([]Repository) error) error {
 	// Detect if this is a user or organization
 	accountType, err := c.GetOwnerType(ctx, name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var allRepos []Repository
-	page := 1
-	perPage := pageSize
-
 	// Determine the base endpoint based on account type
 	var baseEndpoint string
 	if accountType == OwnerTypeOrganization {
@@ -96,23 +162,73 @@ func (c *Client) ListRepos(ctx context.Context, name string, types RepoTypes) ([
 		baseEndpoint = fmt.Sprintf("users/%s/repos", name)
 	}
 
-	typeParam := mapRepoTypes(types, accountType)
+	params := url.Values{"type": {mapRepoTypes(types, accountType)}}
+	if sort != "" {
+		params.Set("sort", sort)
+	}
+	return c.walkRepoPages(ctx, name, baseEndpoint, params, types, fn)
+}
+
+// WalkTeamRepos calls fn with each page of repositories an organization's
+// team has access to, like WalkRepos.
+func (c *Client) WalkTeamRepos(ctx context.Context, org, team string, types RepoTypes, fn func([]Repository) error) error {
+	endpoint := fmt.Sprintf("orgs/%s/teams/%s/repos", org, team)
+	return c.walkRepoPages(ctx, org+"/@"+team, endpoint, url.Values{}, types, fn)
+}
+
+// ValidAffiliations is the list of valid affiliations for WalkUserRepos.
+var ValidAffiliations = []string{"owner", "collaborator", "organization_member"}
+
+// WalkUserRepos calls fn with each page of the authenticated user's
+// repositories, like WalkRepos, including private repositories. affiliation
+// selects which repositories to list by the user's relationship to them (see
+// ValidAffiliations); the API lists all of them if it's empty.
+func (c *Client) WalkUserRepos(ctx context.Context, affiliation []string, types RepoTypes, fn func([]Repository) error) error {
+	// The type parameter can't be combined with affiliation, so repository
+	// types are only filtered client-side.
+	params := url.Values{}
+	if len(affiliation) > 0 {
+		params.Set("affiliation", strings.Join(affiliation, ","))
+	}
+	return c.walkRepoPages(ctx, "@me", "user/repos", params, types, fn)
+}
+
+// WalkStarredRepos calls fn with each page of repositories a user has
+// starred, like WalkRepos. An empty user means the authenticated user.
+func (c *Client) WalkStarredRepos(ctx context.Context, user string, types RepoTypes, fn func([]Repository) error) error {
+	if user == "" {
+		return c.walkRepoPages(ctx, "@starred", "user/starred", url.Values{}, types, fn)
+	}
+	endpoint := fmt.Sprintf("users/%s/starred", user)
+	return c.walkRepoPages(ctx, user+"'s stars", endpoint, url.Values{}, types, fn)
+}
+
+// walkRepoPages calls fn with each page of repositories listed by endpoint,
+// filtered by type. name identifies the list in errors.
+func (c *Client) walkRepoPages(ctx context.Context, name, baseEndpoint string, params url.Values, types RepoTypes, fn func([]Repository) error) error {
+	page := 1
+	perPage := pageSize
 
 	for {
-		endpoint := fmt.Sprintf("%s?type=%s&per_page=%d&page=%d",
-			baseEndpoint, typeParam, perPage, page)
+		params.Set("per_page", strconv.Itoa(perPage))
+		params.Set("page", strconv.Itoa(page))
+		endpoint := baseEndpoint + "?" + params.Encode()
 
 		var repos []Repository
 		err := c.rest.DoWithContext(ctx, "GET", endpoint, nil, &repos)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list repos for %s: %w", name, err)
+			return fmt.Errorf("failed to list repos for %s: %w", name, err)
 		}
 
 		if len(repos) == 0 {
 			break
 		}
 
-		allRepos = append(allRepos, repos...)
+		if filtered := filterRepos(repos, types); len(filtered) > 0 {
+			if err := fn(filtered); err != nil {
+				return err
+			}
+		}
 
 		// Check if there are more pages
 		if len(repos) < perPage {
@@ -121,11 +237,15 @@ func (c *Client) ListRepos(ctx context.Context, name string, types RepoTypes) ([
 		page++
 	}
 
-	// Apply client-side filtering for repo types to cover the cases that
-	// aren't natively supported by the GitHub API.
-	filtered := make([]Repository, 0, len(allRepos))
-	for _, repo := range allRepos {
-		if repo.Size == 0 || repo.Ref == "" {
+	return nil
+}
+
+// filterRepos applies client-side filtering for repo types to cover the cases
+// that aren't natively supported by the GitHub API.
+func filterRepos(repos []Repository, types RepoTypes) []Repository {
+	filtered := make([]Repository, 0, len(repos))
+	for _, repo := range repos {
+		if (repo.Empty() && !types.Empty) || repo.Ref == "" {
 			continue
 		}
 
@@ -147,7 +267,7 @@ func (c *Client) ListRepos(ctx context.Context, name string, types RepoTypes) ([
 		}
 	}
 
-	return filtered, nil
+	return filtered
 }
 
 // repoTypeAPIParams maps repository types to their GitHub API type parameter
@@ -201,7 +321,8 @@ func mapRepoTypes(types RepoTypes, ownerType OwnerType) string {
 	return "all"
 }
 
-// GetRepo fetches a single repository.
+// GetRepo fetches a single repository. If the repository is empty, it is
+// returned along with ErrEmptyRepository.
 func (c *Client) GetRepo(ctx context.Context, owner, repo string) (Repository, error) {
 	var result Repository
 
@@ -210,28 +331,118 @@ func (c *Client) GetRepo(ctx context.Context, owner, repo string) (Repository, e
 	if err != nil {
 		return Repository{}, fmt.Errorf("failed to get repo %s/%s: %w", owner, repo, err)
 	}
-	if result.Size == 0 {
-		return Repository{}, fmt.Errorf("repository is empty (no commits yet)")
-	}
 	if result.Ref == "" {
 		return Repository{}, fmt.Errorf("repository has no default branch")
 	}
+	if result.Empty() {
+		return result, ErrEmptyRepository
+	}
 
 	return result, nil
 }
 
-// GetTree fetches the Git tree for a repository recursively.
+// GetTree fetches the Git tree for a repository recursively. If the
+// repository has a Subtree, only that directory's tree is fetched, but entry
+// paths are still relative to the root of the repository.
 func (c *Client) GetTree(ctx context.Context, repo Repository) (*TreeResponse, error) {
 	var tree TreeResponse
 
-	// Fetch the tree for the specified ref (branch/tag/SHA) with recursive flag
+	// Fetch the tree for the specified ref (branch/tag/SHA), or the commit
+	// it was resolved to, with recursive flag. A "rev:path" expression names
+	// the tree of a directory at that revision.
+	rev := repo.Revision()
+	if repo.Subtree != "" {
+		rev += ":" + repo.Subtree
+	}
 	endpoint := fmt.Sprintf("repos/%s/%s/git/trees/%s?recursive=1",
-		repo.Owner, repo.Name, repo.Ref)
+		repo.Owner, repo.Name, rev)
 
 	err := c.rest.DoWithContext(ctx, "GET", endpoint, nil, &tree)
 	if err != nil {
+		if repo.Subtree != "" {
+			return nil, fmt.Errorf("failed to get tree for %s@%s:%s: %w", repo.FullName, repo.Ref, repo.Subtree, err)
+		}
 		return nil, fmt.Errorf("failed to get tree for %s@%s: %w", repo.FullName, repo.Ref, err)
 	}
 
+	if repo.Subtree != "" {
+		for i := range tree.Tree {
+			tree.Tree[i].Path = repo.Subtree + "/" + tree.Tree[i].Path
+		}
+	}
+
 	return &tree, nil
 }
+
+// GetFileContent fetches the contents of a file at the repository's
+// revision, so it matches the tree that was searched.
+func (c *Client) GetFileContent(ctx context.Context, repo Repository, path string) ([]byte, error) {
+	var result struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	endpoint := fmt.Sprintf("repos/%s/%s/contents/%s?ref=%s",
+		repo.Owner, repo.Name, strings.Join(segments, "/"), url.QueryEscape(repo.Revision()))
+	err := c.rest.DoWithContext(ctx, "GET", endpoint, nil, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s for %s@%s: %w", path, repo.FullName, repo.Ref, err)
+	}
+
+	return decodeContent(result.Encoding, result.Content, path)
+}
+
+// GetBlob fetches the contents of a blob by its SHA, such as a symlink's
+// target.
+func (c *Client) GetBlob(ctx context.Context, repo Repository, sha string) ([]byte, error) {
+	var result struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+
+	endpoint := fmt.Sprintf("repos/%s/%s/git/blobs/%s", repo.Owner, repo.Name, sha)
+	err := c.rest.DoWithContext(ctx, "GET", endpoint, nil, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s for %s: %w", sha, repo.FullName, err)
+	}
+
+	return decodeContent(result.Encoding, result.Content, sha)
+}
+
+// GetBlobPrefix fetches at most the first n bytes of a blob by its SHA. Only
+// that range is requested, and the response is read no further, so a large
+// file isn't downloaded in full.
+func (c *Client) GetBlobPrefix(ctx context.Context, repo Repository, sha string, n int) ([]byte, error) {
+	endpoint := fmt.Sprintf("repos/%s/%s/git/blobs/%s", repo.Owner, repo.Name, sha)
+	resp, err := c.raw.RequestWithContext(withByteRange(ctx, n), "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s for %s: %w", sha, repo.FullName, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, int64(n)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s for %s: %w", sha, repo.FullName, err)
+	}
+	return data, nil
+}
+
+// decodeContent decodes file or blob content from the API, using name in
+// errors.
+func decodeContent(encoding, content, name string) ([]byte, error) {
+	if encoding != "base64" {
+		return nil, fmt.Errorf("unsupported content encoding %q for %s", encoding, name)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+
+	return data, nil
+}
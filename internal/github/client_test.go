@@ -2,6 +2,7 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"slices"
@@ -27,6 +28,7 @@ func testClient(t *testing.T) *Client {
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
 	}
+	client.retryDelay = time.Millisecond
 	return client
 }
 
@@ -368,8 +370,8 @@ func TestGetOwnerType_ContextCanceled(t *testing.T) {
 	}
 }
 
-// TestListRepos tests repository listing with pagination and filtering.
-func TestListRepos(t *testing.T) {
+// TestWalkRepos tests repository listing with pagination and filtering.
+func TestWalkRepos(t *testing.T) {
 	tests := []struct {
 		name          string
 		username      string
@@ -523,6 +525,20 @@ func TestListRepos(t *testing.T) {
 			wantRepoCount: 1,
 			wantRepoNames: []string{"normal-repo"},
 		},
+		{
+			name:          "include empty repositories",
+			username:      "filtertest",
+			repoTypes:     RepoTypes{Sources: true, Empty: true},
+			mockOwnerType: "User",
+			mockPages: []string{
+				reposJSON("filtertest",
+					repoFields{name: "normal-repo", branch: "main", size: 1024},
+					repoFields{name: "empty-repo", branch: "main", size: 0},
+				),
+			},
+			wantRepoCount: 2,
+			wantRepoNames: []string{"normal-repo", "empty-repo"},
+		},
 		{
 			name:          "filter repositories without default branch",
 			username:      "filtertest",
@@ -567,13 +583,17 @@ func TestListRepos(t *testing.T) {
 
 			client := testClient(t)
 
-			repos, err := client.ListRepos(context.Background(), tt.username, tt.repoTypes)
-			if !assertError(t, err, tt.wantErr, "ListRepos()") {
+			var repos []Repository
+			err := client.WalkRepos(context.Background(), tt.username, tt.repoTypes, "", func(page []Repository) error {
+				repos = append(repos, page...)
+				return nil
+			})
+			if !assertError(t, err, tt.wantErr, "WalkRepos()") {
 				return
 			}
 
 			if !tt.wantErr && len(repos) != tt.wantRepoCount {
-				t.Errorf("ListRepos() returned %d repos, want %d", len(repos), tt.wantRepoCount)
+				t.Errorf("WalkRepos() returned %d repos, want %d", len(repos), tt.wantRepoCount)
 			}
 
 			// If specific repo names are provided, verify them
@@ -586,7 +606,7 @@ func TestListRepos(t *testing.T) {
 				wantNames := slices.Clone(tt.wantRepoNames)
 				slices.Sort(wantNames)
 				if !slices.Equal(gotNames, wantNames) {
-					t.Errorf("ListRepos() repo names = %v, want %v", gotNames, wantNames)
+					t.Errorf("WalkRepos() repo names = %v, want %v", gotNames, wantNames)
 				}
 			}
 		})
@@ -602,6 +622,7 @@ func TestGetRepo(t *testing.T) {
 		mockStatus int
 		mockBody   string
 		wantErr    bool
+		wantEmpty  bool
 	}{
 		{
 			name:       "valid repository",
@@ -651,7 +672,8 @@ func TestGetRepo(t *testing.T) {
 				"archived": false,
 				"mirror_url": ""
 			}`,
-			wantErr: true,
+			wantErr:   true,
+			wantEmpty: true,
 		},
 		{
 			name:       "repository without default branch",
@@ -684,6 +706,12 @@ func TestGetRepo(t *testing.T) {
 			client := testClient(t)
 
 			repo, err := client.GetRepo(context.Background(), tt.owner, tt.repo)
+			if errors.Is(err, ErrEmptyRepository) != tt.wantEmpty {
+				t.Fatalf("GetRepo() error = %v, want ErrEmptyRepository = %v", err, tt.wantEmpty)
+			}
+			if tt.wantEmpty && repo.Name != tt.repo {
+				t.Errorf("GetRepo() repo.Name = %v, want %v", repo.Name, tt.repo)
+			}
 			if !assertError(t, err, tt.wantErr, "GetRepo()") {
 				return
 			}
@@ -810,3 +838,316 @@ func TestGetTree(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTreeSubtree(t *testing.T) {
+	assertMocksCalled(t)
+
+	gock.New("https://api.github.com").
+		Get("/repos/golang/go/git/trees/master:src/crypto").
+		MatchParam("recursive", "1").
+		Reply(200).
+		JSON(`{"tree": [
+			{"path": "sha256", "mode": "040000", "type": "tree", "sha": "abc123"},
+			{"path": "sha256/sha256.go", "mode": "100644", "type": "blob", "sha": "def456", "size": 100}
+		], "truncated": false}`)
+
+	client := testClient(t)
+
+	repo := Repository{Owner: "golang", Name: "go", FullName: "golang/go", Ref: "master", Subtree: "src/crypto"}
+	tree, err := client.GetTree(context.Background(), repo)
+	if err != nil {
+		t.Fatalf("GetTree() error = %v", err)
+	}
+
+	var paths []string
+	for _, entry := range tree.Tree {
+		paths = append(paths, entry.Path)
+	}
+	if want := []string{"src/crypto/sha256", "src/crypto/sha256/sha256.go"}; !slices.Equal(paths, want) {
+		t.Errorf("GetTree() paths = %v, want %v", paths, want)
+	}
+}
+
+func TestGetFileContent(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		mockPath   string
+		mockStatus int
+		mockBody   string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "base64 content with line breaks",
+			path:       ".gitattributes",
+			mockPath:   "/repos/octocat/Hello-World/contents/.gitattributes",
+			mockStatus: 200,
+			mockBody:   `{"encoding": "base64", "content": "Ki5taW4uanMg\nbGluZ3Vpc3QtZ2VuZXJhdGVkCg=="}`,
+			want:       "*.min.js linguist-generated\n",
+		},
+		{
+			name:       "nested path with spaces",
+			path:       "docs/read me.txt",
+			mockPath:   "/repos/octocat/Hello-World/contents/docs/read me.txt",
+			mockStatus: 200,
+			mockBody:   `{"encoding": "base64", "content": "aGk="}`,
+			want:       "hi",
+		},
+		{
+			name:       "unsupported encoding",
+			path:       "large.bin",
+			mockPath:   "/repos/octocat/Hello-World/contents/large.bin",
+			mockStatus: 200,
+			mockBody:   `{"encoding": "none", "content": ""}`,
+			wantErr:    true,
+		},
+		{
+			name:       "not found",
+			path:       "missing.txt",
+			mockPath:   "/repos/octocat/Hello-World/contents/missing.txt",
+			mockStatus: 404,
+			mockBody:   `{"message": "Not Found"}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			gock.New("https://api.github.com").
+				Get(tt.mockPath).
+				MatchParam("ref", "main").
+				Reply(tt.mockStatus).
+				JSON(tt.mockBody)
+
+			client := testClient(t)
+			repo := Repository{Owner: "octocat", Name: "Hello-World", FullName: "octocat/Hello-World", Ref: "main"}
+
+			got, err := client.GetFileContent(context.Background(), repo, tt.path)
+			if !assertError(t, err, tt.wantErr, "GetFileContent()") {
+				return
+			}
+
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("GetFileContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFileContentPinned(t *testing.T) {
+	assertMocksCalled(t)
+
+	// A repository pinned to a commit, such as by --as-of, reads files at
+	// that commit rather than at its branch's tip.
+	gock.New("https://api.github.com").
+		Get("/repos/octocat/Hello-World/contents/.gitattributes").
+		MatchParam("ref", "^abc1234$").
+		Reply(200).
+		JSON(`{"encoding": "base64", "content": "aGk="}`)
+
+	client := testClient(t)
+	repo := Repository{Owner: "octocat", Name: "Hello-World", FullName: "octocat/Hello-World", Ref: "main", CommitSHA: "abc1234"}
+
+	got, err := client.GetFileContent(context.Background(), repo, ".gitattributes")
+	if err != nil {
+		t.Fatalf("GetFileContent() error = %v", err)
+	}
+	if string(got) != "hi" {
+		t.Errorf("GetFileContent() = %q, want %q", got, "hi")
+	}
+}
+
+func TestGetBlob(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockStatus int
+		mockBody   string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "symlink target",
+			mockStatus: 200,
+			mockBody:   `{"sha": "3f2a", "encoding": "base64", "content": "L3Vzci9sb2Nh\nbC9iaW4vdG9vbA=="}`,
+			want:       "/usr/local/bin/tool",
+		},
+		{
+			name:       "not found",
+			mockStatus: 404,
+			mockBody:   `{"message": "Not Found"}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			gock.New("https://api.github.com").
+				Get("/repos/octocat/Hello-World/git/blobs/3f2a").
+				Reply(tt.mockStatus).
+				JSON(tt.mockBody)
+
+			client := testClient(t)
+			repo := Repository{Owner: "octocat", Name: "Hello-World", FullName: "octocat/Hello-World", Ref: "main"}
+
+			got, err := client.GetBlob(context.Background(), repo, "3f2a")
+			if !assertError(t, err, tt.wantErr, "GetBlob()") {
+				return
+			}
+
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("GetBlob() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBlobPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockStatus int
+		mockBody   string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "range honored",
+			mockStatus: 206,
+			mockBody:   "hello",
+			want:       "hello",
+		},
+		{
+			// A server that ignores the range still isn't read past n bytes.
+			name:       "range ignored",
+			mockStatus: 200,
+			mockBody:   "hello, world",
+			want:       "hello",
+		},
+		{
+			name:       "not found",
+			mockStatus: 404,
+			mockBody:   `{"message": "Not Found"}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			gock.New("https://api.github.com").
+				Get("/repos/octocat/Hello-World/git/blobs/3f2a").
+				MatchHeader("Accept", "^application/vnd.github.raw\\+json$").
+				MatchHeader("Range", "^bytes=0-4$").
+				Reply(tt.mockStatus).
+				BodyString(tt.mockBody)
+
+			client := testClient(t)
+			repo := Repository{Owner: "octocat", Name: "Hello-World", FullName: "octocat/Hello-World", Ref: "main"}
+
+			got, err := client.GetBlobPrefix(context.Background(), repo, "3f2a", 5)
+			if !assertError(t, err, tt.wantErr, "GetBlobPrefix()") {
+				return
+			}
+
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("GetBlobPrefix() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWalkTeamRepos(t *testing.T) {
+	assertMocksCalled(t)
+
+	gock.New("https://api.github.com").
+		Get("/orgs/cli/teams/maintainers/repos").
+		MatchParam("page", "1").
+		MatchParam("per_page", "100").
+		Reply(200).
+		JSON(reposJSON("cli", sourceRepo, forkRepo, repoFields{name: "empty", branch: "main"}))
+
+	client := testClient(t)
+	var names []string
+	err := client.WalkTeamRepos(context.Background(), "cli", "maintainers", RepoTypes{Sources: true}, func(repos []Repository) error {
+		for _, repo := range repos {
+			names = append(names, repo.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkTeamRepos() error = %v", err)
+	}
+	if want := []string{"source-repo"}; !slices.Equal(names, want) {
+		t.Errorf("WalkTeamRepos() = %v, want %v", names, want)
+	}
+}
+
+func TestWalkStarredRepos(t *testing.T) {
+	tests := []struct {
+		name string
+		user string
+		path string
+	}{
+		{name: "authenticated user", path: "/user/starred"},
+		{name: "named user", user: "octocat", path: "/users/octocat/starred"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			gock.New("https://api.github.com").
+				Get(tt.path).
+				MatchParam("page", "1").
+				Reply(200).
+				JSON(reposJSON("cli", sourceRepo, forkRepo))
+
+			client := testClient(t)
+			var names []string
+			err := client.WalkStarredRepos(context.Background(), tt.user, RepoTypes{Sources: true}, func(repos []Repository) error {
+				for _, repo := range repos {
+					names = append(names, repo.Name)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("WalkStarredRepos() error = %v", err)
+			}
+			if want := []string{"source-repo"}; !slices.Equal(names, want) {
+				t.Errorf("WalkStarredRepos() = %v, want %v", names, want)
+			}
+		})
+	}
+}
+
+func TestWalkUserRepos(t *testing.T) {
+	assertMocksCalled(t)
+
+	private := repoFields{name: "private-repo", branch: "main", size: 1024}
+	gock.New("https://api.github.com").
+		Get("/user/repos").
+		MatchParam("affiliation", "owner,collaborator").
+		MatchParam("page", "1").
+		Reply(200).
+		JSON(reposJSON("octocat", sourceRepo, private, forkRepo))
+
+	client := testClient(t)
+	var names []string
+	err := client.WalkUserRepos(context.Background(), []string{"owner", "collaborator"}, RepoTypes{Sources: true}, func(repos []Repository) error {
+		for _, repo := range repos {
+			names = append(names, repo.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WalkUserRepos() error = %v", err)
+	}
+	if want := []string{"source-repo", "private-repo"}; !slices.Equal(names, want) {
+		t.Errorf("WalkUserRepos() = %v, want %v", names, want)
+	}
+}
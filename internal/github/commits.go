@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// commitFilesPageSize is the number of changed files requested per page.
+const commitFilesPageSize = 100
+
+// ResolveRef returns the SHA of the commit that the repository's ref points
+// to.
+func (c *Client) ResolveRef(ctx context.Context, repo Repository) (string, error) {
+	return c.ResolveRefAt(ctx, repo, time.Time{})
+}
+
+// ResolveRefAt returns the SHA of the latest commit on the repository's ref
+// that was made before t, or of the ref's current commit if t is zero.
+func (c *Client) ResolveRefAt(ctx context.Context, repo Repository, t time.Time) (string, error) {
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+
+	params := url.Values{"sha": {repo.Ref}, "per_page": {"1"}}
+	if !t.IsZero() {
+		params.Set("until", t.UTC().Format(time.RFC3339))
+	}
+
+	endpoint := fmt.Sprintf("repos/%s/%s/commits?%s", repo.Owner, repo.Name, params.Encode())
+	err := c.rest.DoWithContext(ctx, "GET", endpoint, nil, &commits)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", repo.FullName, repo.Ref, err)
+	}
+	if len(commits) == 0 {
+		if !t.IsZero() {
+			return "", fmt.Errorf("no commits to %s before %s", repo.Ref, t.Format(time.DateOnly))
+		}
+		return "", fmt.Errorf("failed to resolve %s@%s: no commits", repo.FullName, repo.Ref)
+	}
+
+	return commits[0].SHA, nil
+}
+
+// GetCommit fetches a commit and the paths of the files it changed. The sha
+// may be abbreviated; the returned Commit always carries the full SHA.
+func (c *Client) GetCommit(ctx context.Context, repo Repository, sha string) (*Commit, error) {
+	var commit Commit
+
+	for page := 1; ; page++ {
+		var result struct {
+			SHA   string `json:"sha"`
+			Files []struct {
+				Filename string `json:"filename"`
+			} `json:"files"`
+		}
+
+		endpoint := fmt.Sprintf("repos/%s/%s/commits/%s?per_page=%d&page=%d",
+			repo.Owner, repo.Name, sha, commitFilesPageSize, page)
+		err := c.rest.DoWithContext(ctx, "GET", endpoint, nil, &result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit %s for %s: %w", sha, repo.FullName, err)
+		}
+
+		commit.SHA = result.SHA
+		for _, file := range result.Files {
+			commit.Files = append(commit.Files, file.Filename)
+		}
+
+		if len(result.Files) < commitFilesPageSize {
+			break
+		}
+	}
+
+	return &commit, nil
+}
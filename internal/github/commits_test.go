@@ -0,0 +1,223 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/h2non/gock.v1"
+)
+
+// commitJSON creates a JSON commit response listing the given changed files.
+func commitJSON(sha string, files ...string) string {
+	parts := make([]string, len(files))
+	for i, f := range files {
+		parts[i] = fmt.Sprintf(`{"filename": %q, "status": "modified"}`, f)
+	}
+	return fmt.Sprintf(`{"sha": %q, "files": [%s]}`, sha, strings.Join(parts, ","))
+}
+
+func TestGetCommit(t *testing.T) {
+	fullSHA := "abc1234def5678abc1234def5678abc1234def56"
+
+	tests := []struct {
+		name       string
+		sha        string
+		mockStatus int
+		mockBody   string
+		wantSHA    string
+		wantFiles  []string
+		wantErr    bool
+	}{
+		{
+			name:       "full sha",
+			sha:        fullSHA,
+			mockStatus: 200,
+			mockBody:   commitJSON(fullSHA, "main.go", "README.md"),
+			wantSHA:    fullSHA,
+			wantFiles:  []string{"main.go", "README.md"},
+		},
+		{
+			name:       "short sha resolved",
+			sha:        "abc1234",
+			mockStatus: 200,
+			mockBody:   commitJSON(fullSHA, "main.go"),
+			wantSHA:    fullSHA,
+			wantFiles:  []string{"main.go"},
+		},
+		{
+			name:       "unknown commit",
+			sha:        "deadbeef",
+			mockStatus: 422,
+			mockBody:   `{"message": "No commit found for SHA: deadbeef"}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			gock.New("https://api.github.com").
+				Get("/repos/cli/cli/commits/"+tt.sha).
+				MatchParam("page", "1").
+				Reply(tt.mockStatus).
+				JSON(tt.mockBody)
+
+			client := testClient(t)
+			repo := Repository{Owner: "cli", Name: "cli", FullName: "cli/cli"}
+
+			commit, err := client.GetCommit(context.Background(), repo, tt.sha)
+			if !assertError(t, err, tt.wantErr, "GetCommit()") {
+				return
+			}
+
+			if !tt.wantErr {
+				if commit.SHA != tt.wantSHA {
+					t.Errorf("GetCommit() SHA = %q, want %q", commit.SHA, tt.wantSHA)
+				}
+				if !slices.Equal(commit.Files, tt.wantFiles) {
+					t.Errorf("GetCommit() Files = %v, want %v", commit.Files, tt.wantFiles)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCommit_Pagination(t *testing.T) {
+	assertMocksCalled(t)
+
+	sha := "abc1234def5678abc1234def5678abc1234def56"
+	firstPage := make([]string, commitFilesPageSize)
+	for i := range firstPage {
+		firstPage[i] = fmt.Sprintf("file%d.go", i)
+	}
+
+	gock.New("https://api.github.com").
+		Get("/repos/cli/cli/commits/"+sha).
+		MatchParam("page", "1").
+		Reply(200).
+		JSON(commitJSON(sha, firstPage...))
+	gock.New("https://api.github.com").
+		Get("/repos/cli/cli/commits/"+sha).
+		MatchParam("page", "2").
+		Reply(200).
+		JSON(commitJSON(sha, "last.go"))
+
+	client := testClient(t)
+	repo := Repository{Owner: "cli", Name: "cli", FullName: "cli/cli"}
+
+	commit, err := client.GetCommit(context.Background(), repo, sha)
+	if err != nil {
+		t.Fatalf("GetCommit() error = %v", err)
+	}
+	if len(commit.Files) != commitFilesPageSize+1 {
+		t.Errorf("GetCommit() returned %d files, want %d", len(commit.Files), commitFilesPageSize+1)
+	}
+}
+
+func TestResolveRef(t *testing.T) {
+	fullSHA := "abc1234def5678abc1234def5678abc1234def56"
+
+	tests := []struct {
+		name       string
+		mockStatus int
+		mockBody   string
+		wantSHA    string
+		wantErr    bool
+	}{
+		{
+			name:       "branch",
+			mockStatus: 200,
+			mockBody:   fmt.Sprintf(`[{"sha": %q}]`, fullSHA),
+			wantSHA:    fullSHA,
+		},
+		{
+			name:       "no commits",
+			mockStatus: 200,
+			mockBody:   `[]`,
+			wantErr:    true,
+		},
+		{
+			name:       "unknown ref",
+			mockStatus: 404,
+			mockBody:   `{"message": "Not Found"}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			gock.New("https://api.github.com").
+				Get("/repos/cli/cli/commits").
+				MatchParam("sha", "feature/x").
+				MatchParam("per_page", "1").
+				Reply(tt.mockStatus).
+				JSON(tt.mockBody)
+
+			client := testClient(t)
+			repo := Repository{Owner: "cli", Name: "cli", FullName: "cli/cli", Ref: "feature/x"}
+
+			sha, err := client.ResolveRef(context.Background(), repo)
+			if !assertError(t, err, tt.wantErr, "ResolveRef()") {
+				return
+			}
+			if sha != tt.wantSHA {
+				t.Errorf("ResolveRef() = %q, want %q", sha, tt.wantSHA)
+			}
+		})
+	}
+}
+
+func TestResolveRefAt(t *testing.T) {
+	fullSHA := "abc1234def5678abc1234def5678abc1234def56"
+	asOf := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		mockBody string
+		wantSHA  string
+		wantErr  bool
+	}{
+		{
+			name:     "commit before date",
+			mockBody: fmt.Sprintf(`[{"sha": %q}]`, fullSHA),
+			wantSHA:  fullSHA,
+		},
+		{
+			name:     "no commits before date",
+			mockBody: `[]`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			gock.New("https://api.github.com").
+				Get("/repos/cli/cli/commits").
+				MatchParam("sha", "trunk").
+				MatchParam("until", "2023-01-01T00:00:00Z").
+				MatchParam("per_page", "1").
+				Reply(200).
+				JSON(tt.mockBody)
+
+			client := testClient(t)
+			repo := Repository{Owner: "cli", Name: "cli", FullName: "cli/cli", Ref: "trunk"}
+
+			sha, err := client.ResolveRefAt(context.Background(), repo, asOf)
+			if !assertError(t, err, tt.wantErr, "ResolveRefAt()") {
+				return
+			}
+			if sha != tt.wantSHA {
+				t.Errorf("ResolveRefAt() = %q, want %q", sha, tt.wantSHA)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -12,53 +13,122 @@ import (
 const (
 	// batchSize is the number of files to query per GraphQL request.
 	batchSize = 100
+	// batchAttempts is the number of times a failed batch is tried before giving up.
+	batchAttempts = 3
 )
 
-// GetFileCommitDates fetches the last commit date for multiple files.
-func (c *Client) GetFileCommitDates(ctx context.Context, repo Repository, paths []string) ([]FileCommitInfo, error) {
+// GetFileCommitDates fetches the last commit date for multiple files. If
+// created is set, it also fetches the date of each file's first commit, and
+// if count is set, the number of commits in each file's history. Counting
+// walks a file's whole history, which makes it one of the most expensive
+// fields to query, so callers should only ask for it when they need it.
+//
+// Files are queried in batches, and each failed batch is retried with
+// backoff. If a batch still fails, the results from all other batches are
+// returned along with the error.
+func (c *Client) GetFileCommitDates(ctx context.Context, repo Repository, paths []string, created, count bool) ([]FileCommitInfo, error) {
 	if len(paths) == 0 {
 		return nil, nil
 	}
 
 	results := make([]FileCommitInfo, 0, len(paths))
+	var failed []error
 
 	// Process files in batches to stay within GraphQL API limits.
 	for i := 0; i < len(paths); i += batchSize {
 		end := min(i+batchSize, len(paths))
 		batch := paths[i:end]
 
-		query := buildFileHistoryQuery(repo.Owner, repo.Name, repo.Ref, batch)
-
-		var response struct {
-			Repository struct {
-				Ref struct {
-					Target map[string]struct {
-						Nodes []struct {
-							CommittedDate time.Time `json:"committedDate"`
-						} `json:"nodes"`
-					} `json:"target"`
-				} `json:"ref"`
-			} `json:"repository"`
+		infos, err := c.fetchCommitDatesWithRetry(ctx, repo, batch, created, count)
+		if err != nil {
+			if ctx.Err() != nil {
+				return results, err
+			}
+			failed = append(failed, fmt.Errorf("files %d-%d: %w", i+1, end, err))
+			continue
 		}
 
-		err := c.graphql.DoWithContext(ctx, query, nil, &response)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch file commit dates: %w", err)
+		results = append(results, infos...)
+	}
+
+	if len(failed) > 0 {
+		return results, fmt.Errorf("failed to fetch file commit dates: %w", errors.Join(failed...))
+	}
+
+	return results, nil
+}
+
+// fetchCommitDatesWithRetry fetches a single batch, retrying with
+// exponential backoff.
+func (c *Client) fetchCommitDatesWithRetry(ctx context.Context, repo Repository, batch []string, created, count bool) ([]FileCommitInfo, error) {
+	delay := c.retryDelay
+	for attempt := 1; ; attempt++ {
+		infos, err := c.fetchCommitDates(ctx, repo, batch, created, count)
+		if err == nil || attempt == batchAttempts || ctx.Err() != nil {
+			return infos, err
 		}
 
-		// Extract commit dates from the response
-		for j, path := range batch {
-			alias := "file" + strconv.Itoa(j)
-			history, ok := response.Repository.Ref.Target[alias]
-			if !ok || len(history.Nodes) == 0 {
-				continue // File doesn't exist or no commit history
-			}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// fetchCommitDates fetches the last commit date for a single batch of files.
+func (c *Client) fetchCommitDates(ctx context.Context, repo Repository, batch []string, created, count bool) ([]FileCommitInfo, error) {
+	query := buildFileHistoryQuery(repo.Owner, repo.Name, repo.Revision(), batch, created, count)
+
+	var response struct {
+		Repository struct {
+			Object map[string]struct {
+				TotalCount int `json:"totalCount"`
+				Nodes      []struct {
+					CommittedDate time.Time `json:"committedDate"`
+					OID           string    `json:"oid"`
+					Author        struct {
+						Name string `json:"name"`
+						User *struct {
+							Login string `json:"login"`
+						} `json:"user"`
+					} `json:"author"`
+				} `json:"nodes"`
+			} `json:"object"`
+		} `json:"repository"`
+	}
+
+	err := c.graphql.DoWithContext(ctx, query, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract commit dates from the response
+	results := make([]FileCommitInfo, 0, len(batch))
+	for j, path := range batch {
+		alias := "file" + strconv.Itoa(j)
+		history, ok := response.Repository.Object[alias]
+		if !ok || len(history.Nodes) == 0 {
+			continue // File doesn't exist or no commit history
+		}
 
-			results = append(results, FileCommitInfo{
-				Path:          path,
-				CommittedDate: history.Nodes[0].CommittedDate,
-			})
+		node := history.Nodes[0]
+		author := node.Author.Name
+		if node.Author.User != nil && node.Author.User.Login != "" {
+			author = node.Author.User.Login
 		}
+		info := FileCommitInfo{
+			Path:          path,
+			CommittedDate: node.CommittedDate,
+			OID:           node.OID,
+			Author:        author,
+			Commits:       history.TotalCount,
+		}
+		if first, ok := response.Repository.Object["created"+strconv.Itoa(j)]; ok && len(first.Nodes) > 0 {
+			info.CreatedDate = first.Nodes[0].CommittedDate
+		}
+		results = append(results, info)
 	}
 
 	return results, nil
@@ -69,32 +139,43 @@ func (c *Client) GetFileCommitDates(ctx context.Context, repo Repository, paths
 //
 //	{
 //	  repository(owner: "owner", name: "repo") {
-//	    ref(qualifiedName: "ref") {
-//	      target {
-//	        ... on Commit {
-//	          file0: history(first: 1, path: "path0") {
-//	            nodes { committedDate }
-//	          }
-//	          file1: history(first: 1, path: "path1") {
-//	            nodes { committedDate }
-//	          }
+//	    object(expression: "ref") {
+//	      ... on Commit {
+//	        file0: history(first: 1, path: "path0") {
+//	          totalCount  # if count is set
+//	          nodes { committedDate oid author { name user { login } } }
+//	        }
+//	        file1: history(first: 1, path: "path1") {
+//	          totalCount  # if count is set
+//	          nodes { committedDate oid author { name user { login } } }
 //	        }
 //	      }
 //	    }
 //	  }
 //	}
-func buildFileHistoryQuery(owner, repo, ref string, paths []string) string {
+//
+// totalCount is only requested if count is set. If created is set, each file
+// also gets a createdN alias for the oldest commit in its history:
+// history(last: 1, path: "pathN") { nodes { committedDate } }.
+func buildFileHistoryQuery(owner, repo, ref string, paths []string, created, count bool) string {
 	var buf strings.Builder
 	buf.Grow(200 + len(paths)*80) // estimate: 200 bytes base overhead + ~80 bytes per path
 
-	fmt.Fprintf(&buf, "{repository(owner:%q,name:%q){ref(qualifiedName:%q){target{...on Commit{", owner, repo, ref)
+	fmt.Fprintf(&buf, "{repository(owner:%q,name:%q){object(expression:%q){...on Commit{", owner, repo, ref)
 
+	totalCount := ""
+	if count {
+		totalCount = "totalCount "
+	}
 	for i, path := range paths {
 		escapedPath, _ := json.Marshal(path)
-		fmt.Fprintf(&buf, "%s:history(first:1,path:%s){nodes{committedDate}}", "file"+strconv.Itoa(i), escapedPath)
+		fmt.Fprintf(&buf, "%s:history(first:1,path:%s){%snodes{committedDate oid author{name user{login}}}}", "file"+strconv.Itoa(i), escapedPath, totalCount)
+		if created {
+			fmt.Fprintf(&buf, "%s:history(last:1,path:%s){nodes{committedDate}}", "created"+strconv.Itoa(i), escapedPath)
+		}
 	}
 
-	fmt.Fprintf(&buf, "}}}}}")
+	fmt.Fprintf(&buf, "}}}}")
 
 	return buf.String()
 }
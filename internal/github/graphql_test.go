@@ -17,7 +17,10 @@ func TestBuildFileHistoryQuery(t *testing.T) {
 		repo     string
 		ref      string
 		paths    []string
+		created  bool
+		count    bool
 		contains []string
+		excludes []string
 	}{
 		{
 			name:  "files",
@@ -26,11 +29,23 @@ func TestBuildFileHistoryQuery(t *testing.T) {
 			ref:   "trunk",
 			paths: []string{"README.md", "LICENSE", "go.mod"},
 			contains: []string{
-				"ref(qualifiedName:\"trunk\")",
-				"file0:history(first:1,path:\"README.md\")",
+				"object(expression:\"trunk\")",
+				"file0:history(first:1,path:\"README.md\"){nodes{committedDate oid author{name user{login}}}}",
 				"file1:history(first:1,path:\"LICENSE\")",
 				"file2:history(first:1,path:\"go.mod\")",
 			},
+			excludes: []string{"totalCount"},
+		},
+		{
+			name:  "commit counts",
+			owner: "cli",
+			repo:  "cli",
+			ref:   "trunk",
+			paths: []string{"README.md"},
+			count: true,
+			contains: []string{
+				"file0:history(first:1,path:\"README.md\"){totalCount nodes{committedDate oid author{name user{login}}}}",
+			},
 		},
 		{
 			name:  "file with quotes",
@@ -42,17 +57,34 @@ func TestBuildFileHistoryQuery(t *testing.T) {
 				"history(first:1,path:\"path/to/\\\"file\\\".txt\")",
 			},
 		},
+		{
+			name:    "created dates",
+			owner:   "cli",
+			repo:    "cli",
+			ref:     "trunk",
+			paths:   []string{"README.md"},
+			created: true,
+			contains: []string{
+				"file0:history(first:1,path:\"README.md\")",
+				"created0:history(last:1,path:\"README.md\"){nodes{committedDate}}",
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			query := buildFileHistoryQuery(tt.owner, tt.repo, tt.ref, tt.paths)
+			query := buildFileHistoryQuery(tt.owner, tt.repo, tt.ref, tt.paths, tt.created, tt.count)
 
 			for _, substr := range tt.contains {
 				if !strings.Contains(query, substr) {
 					t.Errorf("query missing expected substring %q:\n%s", substr, query)
 				}
 			}
+			for _, substr := range tt.excludes {
+				if strings.Contains(query, substr) {
+					t.Errorf("query contains unexpected substring %q:\n%s", substr, query)
+				}
+			}
 		})
 	}
 }
@@ -61,12 +93,16 @@ func TestGetFileCommitDates(t *testing.T) {
 	testDate := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
 
 	tests := []struct {
-		name       string
-		paths      []string
-		mockStatus int
-		mockBody   string
-		wantCount  int
-		wantErr    bool
+		name        string
+		paths       []string
+		count       bool
+		mockStatus  int
+		mockBody    string
+		wantCount   int
+		wantOID     string
+		wantAuthor  string
+		wantCommits int
+		wantErr     bool
 	}{
 		{
 			name:      "empty paths",
@@ -77,21 +113,50 @@ func TestGetFileCommitDates(t *testing.T) {
 			name:       "single file",
 			paths:      []string{"README.md"},
 			mockStatus: 200,
-			mockBody:   `{"data":{"repository":{"ref":{"target":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]}}}}}}`,
+			mockBody:   `{"data":{"repository":{"object":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z","oid":"8b4c2a1f"}]}}}}}`,
+			wantCount:  1,
+			wantOID:    "8b4c2a1f",
+		},
+		{
+			name:       "author with account",
+			paths:      []string{"README.md"},
+			mockStatus: 200,
+			mockBody:   `{"data":{"repository":{"object":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z","oid":"8b4c2a1f","author":{"name":"Jon Parise","user":{"login":"jparise"}}}]}}}}}`,
 			wantCount:  1,
+			wantOID:    "8b4c2a1f",
+			wantAuthor: "jparise",
+		},
+		{
+			name:       "author without account",
+			paths:      []string{"README.md"},
+			mockStatus: 200,
+			mockBody:   `{"data":{"repository":{"object":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z","oid":"8b4c2a1f","author":{"name":"Jon Parise","user":null}}]}}}}}`,
+			wantCount:  1,
+			wantOID:    "8b4c2a1f",
+			wantAuthor: "Jon Parise",
+		},
+		{
+			name:        "commit count",
+			paths:       []string{"README.md"},
+			count:       true,
+			mockStatus:  200,
+			mockBody:    `{"data":{"repository":{"object":{"file0":{"totalCount":42,"nodes":[{"committedDate":"2024-01-15T10:30:00Z","oid":"8b4c2a1f"}]}}}}}`,
+			wantCount:   1,
+			wantOID:     "8b4c2a1f",
+			wantCommits: 42,
 		},
 		{
 			name:       "multiple files",
 			paths:      []string{"README.md", "LICENSE", "go.mod"},
 			mockStatus: 200,
-			mockBody:   `{"data":{"repository":{"ref":{"target":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]},"file1":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]},"file2":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]}}}}}}`,
+			mockBody:   `{"data":{"repository":{"object":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]},"file1":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]},"file2":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]}}}}}`,
 			wantCount:  3,
 		},
 		{
 			name:       "files with empty history excluded",
 			paths:      []string{"README.md", "missing.txt"},
 			mockStatus: 200,
-			mockBody:   `{"data":{"repository":{"ref":{"target":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]},"file1":{"nodes":[]}}}}}}`,
+			mockBody:   `{"data":{"repository":{"object":{"file0":{"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]},"file1":{"nodes":[]}}}}}`,
 			wantCount:  1,
 		},
 		{
@@ -108,7 +173,7 @@ func TestGetFileCommitDates(t *testing.T) {
 			assertMocksCalled(t)
 
 			if tt.mockStatus != 0 {
-				query := buildFileHistoryQuery("cli", "cli", "main", tt.paths)
+				query := buildFileHistoryQuery("cli", "cli", "main", tt.paths, false, tt.count)
 				gock.New("https://api.github.com").
 					Post("/graphql").
 					BodyString(fmt.Sprintf(`{"query":%q,"variables":null}`, query)).
@@ -118,7 +183,7 @@ func TestGetFileCommitDates(t *testing.T) {
 
 			client := testClient(t)
 			repo := Repository{Owner: "cli", Name: "cli", Ref: "main"}
-			got, err := client.GetFileCommitDates(context.Background(), repo, tt.paths)
+			got, err := client.GetFileCommitDates(context.Background(), repo, tt.paths, false, tt.count)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetFileCommitDates() error = %v, wantErr %v", err, tt.wantErr)
@@ -147,11 +212,56 @@ func TestGetFileCommitDates(t *testing.T) {
 				if !got[0].CommittedDate.Equal(testDate) {
 					t.Errorf("first result CommittedDate = %v, want %v", got[0].CommittedDate, testDate)
 				}
+				if got[0].OID != tt.wantOID {
+					t.Errorf("first result OID = %q, want %q", got[0].OID, tt.wantOID)
+				}
+				if got[0].Author != tt.wantAuthor {
+					t.Errorf("first result Author = %q, want %q", got[0].Author, tt.wantAuthor)
+				}
+				if got[0].Commits != tt.wantCommits {
+					t.Errorf("first result Commits = %d, want %d", got[0].Commits, tt.wantCommits)
+				}
 			}
 		})
 	}
 }
 
+func TestGetFileCommitDates_Created(t *testing.T) {
+	assertMocksCalled(t)
+
+	paths := []string{"README.md", "LICENSE"}
+	query := buildFileHistoryQuery("cli", "cli", "main", paths, true, false)
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		BodyString(fmt.Sprintf(`{"query":%q,"variables":null}`, query)).
+		Reply(200).
+		JSON(`{"data":{"repository":{"object":{` +
+			`"file0":{"totalCount":3,"nodes":[{"committedDate":"2024-01-15T10:30:00Z"}]},` +
+			`"created0":{"nodes":[{"committedDate":"2020-06-01T08:00:00Z"}]},` +
+			`"file1":{"totalCount":1,"nodes":[{"committedDate":"2023-03-01T00:00:00Z"}]},` +
+			`"created1":{"nodes":[{"committedDate":"2023-03-01T00:00:00Z"}]}}}}}`)
+
+	client := testClient(t)
+	repo := Repository{Owner: "cli", Name: "cli", Ref: "main"}
+	got, err := client.GetFileCommitDates(context.Background(), repo, paths, true, false)
+	if err != nil {
+		t.Fatalf("GetFileCommitDates() error = %v", err)
+	}
+
+	want := []time.Time{
+		time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC),
+		time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d results, want %d", len(got), len(want))
+	}
+	for i, info := range got {
+		if !info.CreatedDate.Equal(want[i]) {
+			t.Errorf("result[%d].CreatedDate = %v, want %v", i, info.CreatedDate, want[i])
+		}
+	}
+}
+
 func TestGetFileCommitDates_MultipleBatches(t *testing.T) {
 	assertMocksCalled(t)
 
@@ -166,7 +276,7 @@ func TestGetFileCommitDates_MultipleBatches(t *testing.T) {
 		start := batchNum * 100
 		end := min(start+100, len(paths))
 		batch := paths[start:end]
-		query := buildFileHistoryQuery("cli", "cli", "main", batch)
+		query := buildFileHistoryQuery("cli", "cli", "main", batch, false, false)
 		response := buildBatchResponse(len(batch), "2024-01-15T10:00:00Z")
 
 		gock.New("https://api.github.com").
@@ -179,7 +289,7 @@ func TestGetFileCommitDates_MultipleBatches(t *testing.T) {
 	client := testClient(t)
 	repo := Repository{Owner: "cli", Name: "cli", Ref: "main"}
 
-	got, err := client.GetFileCommitDates(context.Background(), repo, paths)
+	got, err := client.GetFileCommitDates(context.Background(), repo, paths, false, false)
 	if err != nil {
 		t.Fatalf("GetFileCommitDates() error = %v", err)
 	}
@@ -189,6 +299,78 @@ func TestGetFileCommitDates_MultipleBatches(t *testing.T) {
 	}
 }
 
+func TestGetFileCommitDates_RetryBatch(t *testing.T) {
+	assertMocksCalled(t)
+
+	paths := make([]string, 150)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("file%d.go", i)
+	}
+
+	first := buildFileHistoryQuery("cli", "cli", "main", paths[:100], false, false)
+	second := buildFileHistoryQuery("cli", "cli", "main", paths[100:], false, false)
+
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		BodyString(fmt.Sprintf(`{"query":%q,"variables":null}`, first)).
+		Reply(200).
+		JSON(buildBatchResponse(100, "2024-01-15T10:00:00Z"))
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		BodyString(fmt.Sprintf(`{"query":%q,"variables":null}`, second)).
+		Reply(502)
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		BodyString(fmt.Sprintf(`{"query":%q,"variables":null}`, second)).
+		Reply(200).
+		JSON(buildBatchResponse(50, "2024-01-15T10:00:00Z"))
+
+	client := testClient(t)
+	repo := Repository{Owner: "cli", Name: "cli", Ref: "main"}
+
+	got, err := client.GetFileCommitDates(context.Background(), repo, paths, false, false)
+	if err != nil {
+		t.Fatalf("GetFileCommitDates() error = %v", err)
+	}
+	if len(got) != 150 {
+		t.Errorf("got %d results, want 150", len(got))
+	}
+}
+
+func TestGetFileCommitDates_PartialResults(t *testing.T) {
+	assertMocksCalled(t)
+
+	paths := make([]string, 150)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("file%d.go", i)
+	}
+
+	first := buildFileHistoryQuery("cli", "cli", "main", paths[:100], false, false)
+	second := buildFileHistoryQuery("cli", "cli", "main", paths[100:], false, false)
+
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		BodyString(fmt.Sprintf(`{"query":%q,"variables":null}`, first)).
+		Reply(200).
+		JSON(buildBatchResponse(100, "2024-01-15T10:00:00Z"))
+	gock.New("https://api.github.com").
+		Post("/graphql").
+		BodyString(fmt.Sprintf(`{"query":%q,"variables":null}`, second)).
+		Times(batchAttempts).
+		Reply(502)
+
+	client := testClient(t)
+	repo := Repository{Owner: "cli", Name: "cli", Ref: "main"}
+
+	got, err := client.GetFileCommitDates(context.Background(), repo, paths, false, false)
+	if err == nil {
+		t.Fatal("expected error when a batch fails permanently")
+	}
+	if len(got) != 100 {
+		t.Errorf("got %d results, want 100 from the successful batch", len(got))
+	}
+}
+
 func TestGetFileCommitDates_ContextCanceled(t *testing.T) {
 	client := testClient(t)
 	repo := Repository{Owner: "cli", Name: "cli", Ref: "main"}
@@ -196,7 +378,7 @@ func TestGetFileCommitDates_ContextCanceled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	_, err := client.GetFileCommitDates(ctx, repo, []string{"README.md"})
+	_, err := client.GetFileCommitDates(ctx, repo, []string{"README.md"}, false, false)
 	if err == nil {
 		t.Error("expected error with canceled context")
 	}
@@ -205,7 +387,7 @@ func TestGetFileCommitDates_ContextCanceled(t *testing.T) {
 // buildBatchResponse creates a GraphQL response with N files, all with the same commit date.
 func buildBatchResponse(count int, commitDate string) string {
 	var sb strings.Builder
-	sb.WriteString(`{"data":{"repository":{"ref":{"target":{`)
+	sb.WriteString(`{"data":{"repository":{"object":{`)
 
 	for i := range count {
 		if i > 0 {
@@ -214,6 +396,6 @@ func buildBatchResponse(count int, commitDate string) string {
 		fmt.Fprintf(&sb, `"file%d":{"nodes":[{"committedDate":%q}]}`, i, commitDate)
 	}
 
-	sb.WriteString(`}}}}}`)
+	sb.WriteString(`}}}}`)
 	return sb.String()
 }
@@ -0,0 +1,36 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Ref namespaces that ListRefs can list.
+const (
+	RefBranches = "heads"
+	RefTags     = "tags"
+)
+
+// ListRefs returns the names of the repository's refs in namespace (RefBranches
+// or RefTags) that start with prefix, without the "refs/<namespace>/" part.
+func (c *Client) ListRefs(ctx context.Context, repo Repository, namespace, prefix string) ([]string, error) {
+	var refs []struct {
+		Ref string `json:"ref"`
+	}
+
+	endpoint := fmt.Sprintf("repos/%s/%s/git/matching-refs/%s/%s",
+		repo.Owner, repo.Name, namespace, prefix)
+	err := c.rest.DoWithContext(ctx, "GET", endpoint, nil, &refs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs for %s: %w", repo.FullName, err)
+	}
+
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if name, ok := strings.CutPrefix(ref.Ref, "refs/"+namespace+"/"); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestListRefs(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespace  string
+		prefix     string
+		path       string
+		mockStatus int
+		mockBody   string
+		want       []string
+		wantErr    bool
+	}{
+		{
+			name:       "branches",
+			namespace:  RefBranches,
+			path:       "/repos/cli/cli/git/matching-refs/heads/",
+			mockStatus: 200,
+			mockBody:   `[{"ref": "refs/heads/main"}, {"ref": "refs/heads/feature/x"}]`,
+			want:       []string{"main", "feature/x"},
+		},
+		{
+			name:       "tags with prefix",
+			namespace:  RefTags,
+			prefix:     "v2.",
+			path:       "/repos/cli/cli/git/matching-refs/tags/v2.",
+			mockStatus: 200,
+			mockBody:   `[{"ref": "refs/tags/v2.0.0"}, {"ref": "refs/tags/v2.1.0"}]`,
+			want:       []string{"v2.0.0", "v2.1.0"},
+		},
+		{
+			name:       "no refs",
+			namespace:  RefTags,
+			path:       "/repos/cli/cli/git/matching-refs/tags/",
+			mockStatus: 200,
+			mockBody:   `[]`,
+			want:       []string{},
+		},
+		{
+			name:       "not found",
+			namespace:  RefBranches,
+			path:       "/repos/cli/cli/git/matching-refs/heads/",
+			mockStatus: 404,
+			mockBody:   `{"message": "Not Found"}`,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			gock.New("https://api.github.com").
+				Get(tt.path).
+				Reply(tt.mockStatus).
+				JSON(tt.mockBody)
+
+			client := testClient(t)
+			repo := Repository{Owner: "cli", Name: "cli", FullName: "cli/cli"}
+			got, err := client.ListRefs(context.Background(), repo, tt.namespace, tt.prefix)
+			if !assertError(t, err, tt.wantErr, "ListRefs()") {
+				return
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ListRefs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// SearchResultLimit is the most results the search API returns for a query.
+const SearchResultLimit = 1000
+
+// SearchRepos calls fn with each page of repositories matching a repository
+// search query (e.g., "org:cli topic:terraform") as it is fetched. The query
+// selects forks and archives itself, so of types only Empty applies: empty
+// repositories are skipped unless it's set. It returns the total number of
+// repositories the query matched, which may be more than SearchResultLimit.
+func (c *Client) SearchRepos(ctx context.Context, query string, types RepoTypes, fn func([]Repository) error) (int, error) {
+	var total int
+	for page := 1; ; page++ {
+		var result struct {
+			TotalCount int          `json:"total_count"`
+			Items      []Repository `json:"items"`
+		}
+
+		endpoint := fmt.Sprintf("search/repositories?q=%s&per_page=%d&page=%d",
+			url.QueryEscape(query), pageSize, page)
+		err := c.rest.DoWithContext(ctx, "GET", endpoint, nil, &result)
+		if err != nil {
+			return 0, fmt.Errorf("failed to search repos for %q: %w", query, err)
+		}
+		total = result.TotalCount
+
+		repos := make([]Repository, 0, len(result.Items))
+		for _, repo := range result.Items {
+			if (!repo.Empty() || types.Empty) && repo.Ref != "" {
+				repos = append(repos, repo)
+			}
+		}
+		if len(repos) > 0 {
+			if err := fn(repos); err != nil {
+				return 0, err
+			}
+		}
+
+		if len(result.Items) < pageSize || page*pageSize >= min(total, SearchResultLimit) {
+			break
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"testing"
+
+	"gopkg.in/h2non/gock.v1"
+)
+
+func TestSearchRepos(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		types     RepoTypes
+		pages     []string
+		wantRepos int
+		wantTotal int
+		wantErr   bool
+	}{
+		{
+			name: "single page",
+			pages: []string{
+				fmt.Sprintf(`{"total_count": 3, "items": %s}`, reposJSON("cli",
+					repoFields{name: "cli", branch: "trunk", size: 100},
+					repoFields{name: "go-gh", branch: "trunk", size: 100},
+					repoFields{name: "empty", branch: "main", size: 0},
+				)),
+			},
+			wantRepos: 2,
+			wantTotal: 3,
+		},
+		{
+			name:  "include empty",
+			types: RepoTypes{Empty: true},
+			pages: []string{
+				fmt.Sprintf(`{"total_count": 3, "items": %s}`, reposJSON("cli",
+					repoFields{name: "cli", branch: "trunk", size: 100},
+					repoFields{name: "go-gh", branch: "trunk", size: 100},
+					repoFields{name: "empty", branch: "main", size: 0},
+				)),
+			},
+			wantRepos: 3,
+			wantTotal: 3,
+		},
+		{
+			name: "multiple pages",
+			pages: []string{
+				fmt.Sprintf(`{"total_count": 150, "items": %s}`, generateRepoPage("cli", 1, 100)),
+				fmt.Sprintf(`{"total_count": 150, "items": %s}`, generateRepoPage("cli", 101, 50)),
+			},
+			wantRepos: 150,
+			wantTotal: 150,
+		},
+		{
+			name: "stops at the result limit",
+			pages: func() []string {
+				pages := make([]string, SearchResultLimit/pageSize)
+				for i := range pages {
+					pages[i] = fmt.Sprintf(`{"total_count": 5000, "items": %s}`, generateRepoPage("cli", i*pageSize+1, pageSize))
+				}
+				return pages
+			}(),
+			wantRepos: SearchResultLimit,
+			wantTotal: 5000,
+		},
+		{
+			name:    "invalid query",
+			status:  422,
+			pages:   []string{`{"message": "Validation Failed"}`},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertMocksCalled(t)
+
+			status := cmp.Or(tt.status, 200)
+			for i, page := range tt.pages {
+				gock.New("https://api.github.com").
+					Get("/search/repositories").
+					MatchParam("q", "org:cli topic:go").
+					MatchParam("page", fmt.Sprint(i+1)).
+					Reply(status).
+					JSON(page)
+			}
+
+			client := testClient(t)
+			var repos int
+			total, err := client.SearchRepos(context.Background(), "org:cli topic:go", tt.types, func(page []Repository) error {
+				repos += len(page)
+				return nil
+			})
+			if !assertError(t, err, tt.wantErr, "SearchRepos()") {
+				return
+			}
+			if repos != tt.wantRepos {
+				t.Errorf("SearchRepos() returned %d repos, want %d", repos, tt.wantRepos)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("SearchRepos() total = %d, want %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
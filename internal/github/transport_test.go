@@ -0,0 +1,111 @@
+package github
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeServerCA writes the TLS test server's certificate as a PEM bundle.
+func writeServerCA(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write CA bundle: %v", err)
+	}
+	return path
+}
+
+func TestNewTLSTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		caFile   string
+		insecure bool
+		wantErr  bool
+	}{
+		{name: "system roots reject test server", wantErr: true},
+		{name: "custom CA bundle trusted", caFile: writeServerCA(t, srv)},
+		{name: "insecure skips verification", insecure: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newTLSTransport(tt.caFile, tt.insecure)
+			if err != nil {
+				t.Fatalf("newTLSTransport() unexpected error: %v", err)
+			}
+
+			client := &http.Client{Transport: transport}
+			resp, err := client.Get(srv.URL)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GET error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewTLSTransport_InvalidBundle(t *testing.T) {
+	dir := t.TempDir()
+
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{empty, filepath.Join(dir, "missing.pem")} {
+		if _, err := newTLSTransport(path, false); err == nil {
+			t.Errorf("newTLSTransport(%q) expected error, got nil", path)
+		}
+	}
+}
+
+// recordingTransport records whether it handled a request.
+type recordingTransport struct {
+	used bool
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.used = true
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestHostTransport(t *testing.T) {
+	tests := []struct {
+		url        string
+		wantCustom bool
+	}{
+		{url: "https://ghe.example.com/api/v3/repos/o/r", wantCustom: true},
+		{url: "https://api.ghe.example.com/repos/o/r", wantCustom: true},
+		{url: "https://ghe.example.com:8443/api/v3/repos/o/r", wantCustom: true},
+		{url: "https://api.github.com/repos/o/r", wantCustom: false},
+		{url: "https://evil-ghe.example.com/", wantCustom: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			custom := &recordingTransport{}
+			fallback := &recordingTransport{}
+			transport := &hostTransport{host: "ghe.example.com", custom: custom, fallback: fallback}
+
+			u, _ := url.Parse(tt.url)
+			if _, err := transport.RoundTrip(&http.Request{URL: u}); err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+
+			if custom.used != tt.wantCustom || fallback.used == tt.wantCustom {
+				t.Errorf("custom used = %v, fallback used = %v; want custom = %v", custom.used, fallback.used, tt.wantCustom)
+			}
+		})
+	}
+}
@@ -8,16 +8,41 @@ import (
 
 // Repository represents a GitHub repository.
 type Repository struct {
-	Owner       string `json:"-"`
-	Name        string `json:"name"`
-	FullName    string `json:"full_name"`
-	Ref         string `json:"default_branch"`
-	ExplicitRef bool   `json:"-"`
-	URL         string `json:"html_url"`
-	Size        int    `json:"size"`
-	Fork        bool   `json:"fork"`
-	Archived    bool   `json:"archived"`
-	MirrorURL   string `json:"mirror_url"`
+	Owner       string     `json:"-"`
+	Name        string     `json:"name"`
+	FullName    string     `json:"full_name"`
+	Ref         string     `json:"default_branch"`
+	ExplicitRef bool       `json:"-"`
+	CommitSHA   string     `json:"-"` // Commit the ref was resolved to, if any
+	Subtree     string     `json:"-"` // Directory to search instead of the whole tree, if any
+	URL         string     `json:"html_url"`
+	Size        int        `json:"size"` // Kilobytes
+	Private     bool       `json:"private"`
+	Visibility  Visibility `json:"visibility"`
+	Fork        bool       `json:"fork"`
+	Archived    bool       `json:"archived"`
+	IsTemplate  bool       `json:"is_template"`
+	MirrorURL   string     `json:"mirror_url"`
+	Topics      []string   `json:"topics"`
+	Language    string     `json:"language"` // Primary language, if any
+	Stars       int        `json:"stargazers_count"`
+	PushedAt    time.Time  `json:"pushed_at"`
+}
+
+// Empty reports whether the repository has no commits yet, and so has no
+// tree to search.
+func (r Repository) Empty() bool {
+	return r.Size == 0
+}
+
+// Revision returns the resolved commit SHA if there is one, or else the ref.
+// Trees, commit histories, and web links use it so they don't change as the
+// ref moves.
+func (r Repository) Revision() string {
+	if r.CommitSHA != "" {
+		return r.CommitSHA
+	}
+	return r.Ref
 }
 
 // UnmarshalJSON implements custom JSON unmarshaling for Repository.
@@ -37,14 +62,44 @@ func (r *Repository) UnmarshalJSON(data []byte) error {
 	}
 
 	r.Owner = aux.Owner.Login
+
+	// Older GitHub Enterprise Server versions only report whether a
+	// repository is private.
+	if r.Visibility == "" {
+		r.Visibility = VisibilityPublic
+		if r.Private {
+			r.Visibility = VisibilityPrivate
+		}
+	}
 	return nil
 }
 
+// Visibility is who can see a repository.
+type Visibility string
+
+const (
+	// VisibilityPublic represents repositories anyone can see.
+	VisibilityPublic Visibility = "public"
+	// VisibilityPrivate represents repositories only their collaborators can see.
+	VisibilityPrivate Visibility = "private"
+	// VisibilityInternal represents repositories any member of their
+	// enterprise can see.
+	VisibilityInternal Visibility = "internal"
+)
+
+// ValidVisibilities is the list of valid repository visibility values.
+var ValidVisibilities = []string{
+	string(VisibilityPublic),
+	string(VisibilityPrivate),
+	string(VisibilityInternal),
+}
+
 // TreeEntry represents a file or directory in a Git tree.
 type TreeEntry struct {
 	Path string `json:"path"`
 	Mode string `json:"mode"`
 	Size int64  `json:"size"`
+	SHA  string `json:"sha"` // Blob, tree, or submodule commit SHA
 }
 
 // TreeResponse represents the GitHub API tree response.
@@ -53,10 +108,14 @@ type TreeResponse struct {
 	Truncated bool        `json:"truncated"`
 }
 
-// FileCommitInfo holds the last commit timestamp for a file.
+// FileCommitInfo holds the last commit to change a file.
 type FileCommitInfo struct {
 	Path          string
 	CommittedDate time.Time
+	OID           string    // Commit SHA
+	Author        string    // Author's login, or their name if they have no GitHub account
+	Commits       int       // Number of commits in the file's history
+	CreatedDate   time.Time // Date of the file's first commit, if fetched
 }
 
 // RepoType represents a GitHub repository classification.
@@ -87,6 +146,7 @@ type RepoTypes struct {
 	Forks    bool
 	Archives bool
 	Mirrors  bool
+	Empty    bool // Include repositories without any commits
 }
 
 // All returns a RepoTypes with all types enabled.
@@ -157,3 +217,9 @@ func ParseFileType(mode string) FileType {
 		return FileTypeFile
 	}
 }
+
+// Commit holds a commit's full SHA and the paths of the files it changed.
+type Commit struct {
+	SHA   string
+	Files []string
+}
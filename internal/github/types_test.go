@@ -1,6 +1,9 @@
 package github
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+)
 
 func TestParseFileType(t *testing.T) {
 	tests := []struct {
@@ -59,3 +62,27 @@ func TestParseFileType(t *testing.T) {
 		})
 	}
 }
+
+func TestRepositoryVisibility(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want Visibility
+	}{
+		{name: "reported", json: `{"private": true, "visibility": "internal"}`, want: VisibilityInternal},
+		{name: "private", json: `{"private": true}`, want: VisibilityPrivate},
+		{name: "public", json: `{"private": false}`, want: VisibilityPublic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var repo Repository
+			if err := json.Unmarshal([]byte(tt.json), &repo); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if repo.Visibility != tt.want {
+				t.Errorf("Visibility = %q, want %q", repo.Visibility, tt.want)
+			}
+		})
+	}
+}
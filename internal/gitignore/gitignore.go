@@ -0,0 +1,125 @@
+// Package gitignore parses .gitignore files to decide whether repository
+// paths are ignored.
+//
+// Supported syntax:
+//   - Blank lines and lines starting with "#" are ignored; a leading "\"
+//     escapes a literal "#" or "!"
+//   - Trailing spaces are ignored unless escaped with "\"
+//   - A leading "!" negates the pattern, re-including paths that an earlier
+//     pattern ignored
+//   - A trailing "/" makes the pattern match only directories
+//   - Patterns without a slash (other than a trailing one) match the
+//     basename at any depth; other patterns match the full path relative to
+//     the repository root, with any leading "/" removed
+//   - Glob syntax, including "**", follows doublestar
+//
+// As in git, a path inside an ignored directory is ignored, and no pattern
+// can re-include it.
+package gitignore
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+type rule struct {
+	pattern  string
+	negate   bool // re-include matching paths
+	dirOnly  bool // match only directories
+	basename bool // match only the basename
+}
+
+// Matcher holds the parsed rules of a .gitignore file.
+type Matcher struct {
+	rules []rule
+}
+
+// Parse parses the contents of a .gitignore file. Malformed patterns never
+// match rather than being reported, matching git's lenient behavior.
+func Parse(data []byte) *Matcher {
+	m := &Matcher{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := trimTrailingSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var r rule
+		if pattern, ok := strings.CutPrefix(line, "!"); ok {
+			r.negate = true
+			line = pattern
+		} else if strings.HasPrefix(line, `\#`) || strings.HasPrefix(line, `\!`) {
+			line = line[1:]
+		}
+		if pattern, ok := strings.CutSuffix(line, "/"); ok {
+			r.dirOnly = true
+			line = pattern
+		}
+		if line == "" {
+			continue
+		}
+
+		r.basename = !strings.Contains(line, "/")
+		r.pattern = strings.TrimPrefix(line, "/")
+		m.rules = append(m.rules, r)
+	}
+
+	return m
+}
+
+// trimTrailingSpace removes trailing spaces from line, keeping one that is
+// escaped with a backslash.
+func trimTrailingSpace(line string) string {
+	trimmed := strings.TrimRight(line, " ")
+	if trimmed != line && strings.HasSuffix(trimmed, `\`) {
+		trimmed += " "
+	}
+	return trimmed
+}
+
+// Match reports whether the path p, relative to the repository root, is
+// ignored. dir reports whether p is a directory.
+func (m *Matcher) Match(p string, dir bool) bool {
+	// A path inside an ignored directory is ignored regardless of later
+	// rules, so check each parent directory from the top down.
+	for i := 0; i < len(p); i++ {
+		if p[i] == '/' && m.matchRules(p[:i], true) {
+			return true
+		}
+	}
+	return m.matchRules(p, dir)
+}
+
+// matchRules reports whether the last rule matching p ignores it.
+func (m *Matcher) matchRules(p string, dir bool) bool {
+	for i := len(m.rules) - 1; i >= 0; i-- {
+		if r := m.rules[i]; r.matches(p, dir) {
+			return !r.negate
+		}
+	}
+	return false
+}
+
+func (r rule) matches(p string, dir bool) bool {
+	if r.dirOnly && !dir {
+		return false
+	}
+	if r.basename {
+		p = path.Base(p)
+	}
+	// doublestar's trailing "/**" also matches the directory itself, but
+	// git's only matches what's inside it.
+	if prefix, ok := strings.CutSuffix(r.pattern, "/**"); ok {
+		if matched, _ := doublestar.Match(prefix, p); matched {
+			return false
+		}
+	}
+	matched, err := doublestar.Match(r.pattern, p)
+	return err == nil && matched
+}
@@ -0,0 +1,62 @@
+package gitignore
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	data := []byte(`# Build output
+*.log
+!important.log
+/dist
+build/
+docs/**/*.tmp
+vendor/**
+\#notes
+\!bang
+trailing\ 
+node_modules/
+!node_modules/keep.js
+`)
+
+	m := Parse(data)
+
+	tests := []struct {
+		name string
+		path string
+		dir  bool
+		want bool
+	}{
+		{name: "basename pattern at root", path: "debug.log", want: true},
+		{name: "basename pattern nested", path: "var/log/debug.log", want: true},
+		{name: "negation re-includes", path: "var/important.log", want: false},
+		{name: "anchored pattern", path: "dist", dir: true, want: true},
+		{name: "inside anchored directory", path: "dist/app.js", want: true},
+		{name: "anchored pattern not nested", path: "web/dist/app.js", want: false},
+		{name: "directory pattern matches directory", path: "web/build", dir: true, want: true},
+		{name: "directory pattern skips file", path: "web/build", want: false},
+		{name: "inside directory", path: "web/build/out.js", want: true},
+		{name: "doublestar", path: "docs/api/v1/x.tmp", want: true},
+		{name: "doublestar zero directories", path: "docs/x.tmp", want: true},
+		{name: "trailing doublestar contents", path: "vendor/pkg/a.go", want: true},
+		{name: "trailing doublestar directory itself", path: "vendor", dir: true, want: false},
+		{name: "escaped hash", path: "#notes", want: true},
+		{name: "escaped bang", path: "!bang", want: true},
+		{name: "escaped trailing space", path: "trailing ", want: true},
+		{name: "can't re-include inside ignored directory", path: "node_modules/keep.js", want: true},
+		{name: "unmatched", path: "main.go", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.Match(tt.path, tt.dir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	m := Parse(nil)
+	if m.Match("main.go", false) {
+		t.Error("empty gitignore ignored a path")
+	}
+}